@@ -4,13 +4,26 @@ import (
 	"bike/configs"
 	_ "bike/docs"
 	"bike/internal/addresses"
+	"bike/internal/apikeys"
 	"bike/internal/auth"
+	"bike/internal/categories"
+	"bike/internal/impersonation"
+	"bike/internal/privacy"
 	"bike/internal/products"
 	"bike/internal/users"
 	"bike/pkg/db"
+	"bike/pkg/jwt"
+	"bike/pkg/mailer"
 	"bike/pkg/middleware"
+	"bike/pkg/oidc"
+	"bike/pkg/paginate"
+	"bike/pkg/sms"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	httpSwagger "github.com/swaggo/http-swagger"
+	"log"
 	"net/http"
 )
 
@@ -26,45 +39,170 @@ func main() {
 	database := db.NewDb(conf)
 	router := http.NewServeMux()
 
+	// Ключи подписи JWT
+	keyring, err := jwt.LoadKeyring(conf.Auth)
+	if err != nil {
+		log.Fatalf("Failed to load JWT keys: %v", err)
+	}
+	tokens := jwt.NewJWTWithKeyring(keyring)
+
+	// Подпись курсоров пагинации
+	cursorSecret := conf.App.CursorSecret
+	if cursorSecret == "" {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			log.Fatalf("Failed to generate cursor secret: %v", err)
+		}
+		cursorSecret = hex.EncodeToString(buf)
+		log.Println("CURSOR_SECRET is not set: pagination cursors will not survive a restart")
+	}
+	cursors := paginate.NewCodec(cursorSecret)
+
 	// Repositories
 	productRepository := products.NewProductRepository(database)
+	categoryRepository := categories.NewCategoryRepository(database)
+	modifierRepository := products.NewModifierRepository(database)
 	userRepository := users.NewUserRepository(database)
 	addressRepository := addresses.NewAddressRepository(database)
+	refreshTokenRepository := auth.NewRefreshTokenRepository(database)
+	revocationRepository := auth.NewRevocationRepository(database)
+	userTokenRepository := auth.NewUserTokenRepository(database)
+	twoFactorRepository := auth.NewTwoFactorRepository(database)
+	identityRepository := auth.NewIdentityRepository(database)
+	sessionRepository := auth.NewSessionRepository(database)
+	phoneCodeRepository := auth.NewPhoneCodeRepository(database)
+	apiKeyRepository := apikeys.NewAPIKeyRepository(database)
+	erasureRepository := privacy.NewErasureRepository(database)
+	impersonationRepository := impersonation.NewImpersonationRepository(database)
+
+	// Отозванные токены: кэш в памяти + периодическая чистка
+	revocationStore := auth.NewRevocationStore(revocationRepository)
+	if err := revocationStore.Load(context.Background()); err != nil {
+		log.Printf("Failed to load revoked tokens: %v", err)
+	}
+	go revocationStore.Run(context.Background(), conf.Auth.RevocationSweepInterval)
+	authDeps := &middleware.AuthDeps{
+		Config:      conf,
+		JWT:         tokens,
+		Revocations: revocationStore,
+		Sessions:    auth.NewSessionTracker(sessionRepository),
+	}
+
+	// Защита входа от перебора
+	var attemptStore auth.AttemptStore = auth.NewMemoryAttemptStore()
+	if conf.Lockout.Store == "postgres" {
+		attemptStore = auth.NewPostgresAttemptStore(database)
+	}
+	loginLimiter := auth.NewLoginLimiter(attemptStore, conf.Lockout)
 
 	// Services
-	productService := products.NewProductService(productRepository)
-	authService := auth.NewAuthService(userRepository)
-	addressService := addresses.NewAddressService(addressRepository, userRepository)
+	categoryService := categories.NewCategoryService(categoryRepository)
+	productService := products.NewProductService(productRepository, categoryRepository)
+	modifierService := products.NewModifierService(products.ModifierServiceDeps{
+		ModifierRepository: modifierRepository,
+		ProductRepository:  productRepository,
+		CategoryRepository: categoryRepository,
+	})
+	authService := auth.NewAuthService(auth.AuthServiceDeps{
+		UserRepository:         userRepository,
+		RefreshTokenRepository: refreshTokenRepository,
+		UserTokenRepository:    userTokenRepository,
+		TwoFactorRepository:    twoFactorRepository,
+		IdentityRepository:     identityRepository,
+		SessionRepository:      sessionRepository,
+		PhoneCodeRepository:    phoneCodeRepository,
+		RevocationStore:        revocationStore,
+		LoginLimiter:           loginLimiter,
+		Mailer:                 mailer.New(conf.Mail),
+		SMSSender:              sms.New(conf.SMS),
+		OIDCProviders:          oidc.NewRegistry(conf.OIDC),
+		JWT:                    tokens,
+		Config:                 conf,
+	})
+	userCache := users.NewUserCache(userRepository, conf.Auth.UserCacheTTL)
+	addressService := addresses.NewAddressService(addressRepository, userCache, conf)
+	userService := users.NewUserService(userRepository, userCache, authService)
+	apiKeyService := apikeys.NewAPIKeyService(apiKeyRepository, userRepository)
+	authDeps.APIKeys = apiKeyService
+	authDeps.Accounts = userCache
+	impersonationService := impersonation.NewImpersonationService(impersonation.ImpersonationServiceDeps{
+		ImpersonationRepository: impersonationRepository,
+		UserRepository:          userRepository,
+		JWT:                     tokens,
+		Config:                  conf,
+	})
+	authDeps.Impersonations = impersonationService
+	privacyService := privacy.NewPrivacyService(privacy.PrivacyServiceDeps{
+		Database:          database,
+		UserRepository:    userRepository,
+		AddressRepository: addressRepository,
+		ErasureRepository: erasureRepository,
+		AuthService:       authService,
+	})
 
 	// Handlers
 	auth.NewAuthHandler(router, auth.AuthHandlerDeps{
 		Config:      conf,
 		AuthService: authService,
+		Auth:        authDeps,
 	})
 	products.NewProductHandler(router, products.ProductHandlerDeps{
 		Config:            conf,
 		ProductRepository: productRepository,
 		ProductService:    productService,
+		CategoryService:   categoryService,
+		Auth:              authDeps,
+		Cursors:           cursors,
+	})
+	products.NewModifierHandler(router, products.ModifierHandlerDeps{
+		ModifierService: modifierService,
+		Auth:            authDeps,
+	})
+	categories.NewCategoryHandler(router, categories.CategoryHandlerDeps{
+		CategoryService: categoryService,
+		Auth:            authDeps,
 	})
 	addresses.NewAddressHandler(router, addresses.AddressHandlerDeps{
 		Config:            conf,
 		AddressRepository: addressRepository,
 		AddressService:    addressService,
 		UserRepository:    userRepository,
+		Auth:              authDeps,
+		Cursors:           cursors,
 	})
 	users.NewUsersHandler(router, users.UserHandlerDeps{
 		Config:         conf,
 		UserRepository: userRepository,
+		UserService:    userService,
+		Auth:           authDeps,
+		Cursors:        cursors,
+	})
+
+	apikeys.NewAPIKeyHandler(router, apikeys.APIKeyHandlerDeps{
+		APIKeyService: apiKeyService,
+		Auth:          authDeps,
+	})
+	impersonation.NewImpersonationHandler(router, impersonation.ImpersonationHandlerDeps{
+		ImpersonationService: impersonationService,
+		Auth:                 authDeps,
+	})
+	privacy.NewPrivacyHandler(router, privacy.PrivacyHandlerDeps{
+		PrivacyService: privacyService,
+		Auth:           authDeps,
 	})
 
 	// Swagger UI
 	router.Handle("/swagger/", httpSwagger.WrapHandler)
 
 	// Middlewares
-	stack := middleware.Chain(
+	middlewares := []middleware.Middleware{
 		middleware.CORS,
 		middleware.Logging,
-	)
+	}
+	if conf.App.TrustProxy {
+		middlewares = append([]middleware.Middleware{middleware.RealIP}, middlewares...)
+	}
+	stack := middleware.Chain(middlewares...)
 
 	server := http.Server{
 		Addr:    ":8081",
@@ -4,11 +4,29 @@ import (
 	"github.com/joho/godotenv"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
-	Db   Dbconfig
-	Auth AuthConfig
+	App     AppConfig
+	Db      Dbconfig
+	Auth    AuthConfig
+	Mail    MailConfig
+	Lockout LockoutConfig
+	OIDC    OIDCConfig
+	SMS     SMSConfig
+	OTP     OTPConfig
+}
+
+type AppConfig struct {
+	// Публичный адрес API, из него строятся ссылки в письмах
+	BaseURL string
+	// Брать IP клиента из X-Forwarded-For (только за доверенным прокси)
+	TrustProxy bool
+	// Ключ подписи курсоров пагинации
+	CursorSecret string
 }
 
 type Dbconfig struct {
@@ -16,7 +34,114 @@ type Dbconfig struct {
 }
 
 type AuthConfig struct {
-	Secret string
+	Secret     string
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+	// Как часто чистить истёкшие записи об отзыве токенов и обновлять их кэш
+	RevocationSweepInterval time.Duration
+
+	// Ключи подписи JWT (PEM-файлы RSA / Ed25519), выбираются по kid
+	Keys         []JWTKeyConfig
+	SigningKeyID string
+	// Сколько ещё принимать токены, подписанные выведенным из оборота ключом
+	KeyGracePeriod time.Duration
+	// Момент вывода из оборота HMAC-ключа SECRET (нулевое значение — активен)
+	SecretRetiredAt time.Time
+
+	PasswordResetTTL time.Duration
+	// Страница фронтенда для сброса пароля, к ней добавляется ?token=
+	PasswordResetURL string
+
+	EmailVerificationTTL time.Duration
+	// Запрещать неподтверждённым аккаунтам чувствительные действия (например, добавление адресов)
+	RequireVerifiedEmail bool
+
+	// Название сервиса в приложении-аутентификаторе
+	TwoFactorIssuer string
+	// Сколько действует токен второго шага входа
+	TwoFactorChallengeTTL time.Duration
+
+	// Сколько хранить пользователя в кэше по ID (users.UserCache)
+	UserCacheTTL time.Duration
+
+	// Срок действия токена входа администратора от имени пользователя
+	ImpersonationTTL time.Duration
+}
+
+type MailConfig struct {
+	// "smtp" или "log"
+	Driver       string
+	From         string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUser     string
+	SMTPPassword string
+	// Файл, куда LogMailer пишет письма; пусто — в лог
+	LogPath string
+}
+
+type SMSConfig struct {
+	// "http" или "log"
+	Driver string
+	// Адрес HTTP-шлюза, запросы идут на {BaseURL}/messages
+	BaseURL string
+	APIKey  string
+	From    string
+	// Файл, куда LogSender пишет сообщения; пусто — в лог
+	LogPath string
+}
+
+// OTPConfig — вход по одноразовому коду из SMS.
+type OTPConfig struct {
+	TTL time.Duration
+	// Сколько ждать перед повторной отправкой кода на тот же номер
+	ResendCooldown time.Duration
+	// Сколько неверных попыток ввода разрешено для одного кода
+	MaxAttempts int
+}
+
+// LockoutConfig — защита /auth/login от перебора паролей.
+type LockoutConfig struct {
+	// "memory" (один инстанс) или "postgres" (несколько инстансов)
+	Store string
+	// Сколько неудачных попыток подряд разрешено без задержки
+	FreeAttempts int
+	// После стольких неудач аккаунт (email) блокируется на LockDuration
+	MaxFailures int
+	// То же для IP — порог выше, за одним IP может быть много пользователей
+	IPMaxFailures int
+	// Задержка после неудачи растёт экспоненциально: BaseDelay * 2^n, но не больше MaxDelay
+	BaseDelay    time.Duration
+	MaxDelay     time.Duration
+	LockDuration time.Duration
+}
+
+// OIDCConfig — вход через внешних провайдеров OpenID Connect.
+type OIDCConfig struct {
+	Providers []OIDCProviderConfig
+	// Сколько ждать возврата пользователя от провайдера
+	StateTTL time.Duration
+}
+
+type OIDCProviderConfig struct {
+	// Имя провайдера в URL: /auth/oidc/{name}/...
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// Адрес, на который провайдер вернёт пользователя (deep link приложения или страница фронтенда)
+	RedirectURL string
+	Scopes      []string
+	// Необязательно: по умолчанию берутся из {Issuer}/.well-known/openid-configuration
+	AuthURL  string
+	TokenURL string
+	JWKSURL  string
+}
+
+type JWTKeyConfig struct {
+	ID        string
+	Path      string
+	RetiredAt time.Time
 }
 
 func LoadConfig() *Config {
@@ -25,11 +150,196 @@ func LoadConfig() *Config {
 		log.Println("Error loading .env file, using defaults.")
 	}
 	return &Config{
+		App: AppConfig{
+			BaseURL:      strings.TrimRight(getString("APP_URL", "http://localhost:8081"), "/"),
+			TrustProxy:   getBool("TRUST_PROXY", false),
+			CursorSecret: getString("CURSOR_SECRET", os.Getenv("SECRET")),
+		},
 		Db: Dbconfig{
 			Dsn: os.Getenv("DSN"),
 		},
 		Auth: AuthConfig{
-			Secret: os.Getenv("SECRET"),
+			Secret:     os.Getenv("SECRET"),
+			AccessTTL:  getDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+			RefreshTTL: getDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+
+			RevocationSweepInterval: getDuration("REVOCATION_SWEEP_INTERVAL", time.Minute),
+
+			Keys:            parseJWTKeys(os.Getenv("JWT_KEYS")),
+			SigningKeyID:    os.Getenv("JWT_SIGNING_KEY"),
+			KeyGracePeriod:  getDuration("JWT_KEY_GRACE", 24*time.Hour),
+			SecretRetiredAt: getTime("JWT_SECRET_RETIRED_AT"),
+
+			PasswordResetTTL: getDuration("PASSWORD_RESET_TTL", time.Hour),
+			PasswordResetURL: os.Getenv("PASSWORD_RESET_URL"),
+
+			EmailVerificationTTL: getDuration("EMAIL_VERIFICATION_TTL", 24*time.Hour),
+			RequireVerifiedEmail: getBool("REQUIRE_VERIFIED_EMAIL", true),
+
+			TwoFactorIssuer:       getString("TOTP_ISSUER", "API-Bike"),
+			TwoFactorChallengeTTL: getDuration("TWO_FACTOR_CHALLENGE_TTL", 5*time.Minute),
+
+			UserCacheTTL: getDuration("USER_CACHE_TTL", 30*time.Second),
+
+			ImpersonationTTL: getDuration("IMPERSONATION_TTL", 10*time.Minute),
+		},
+		Mail: MailConfig{
+			Driver:       getString("MAIL_DRIVER", "log"),
+			From:         getString("MAIL_FROM", "no-reply@bike.local"),
+			SMTPHost:     os.Getenv("SMTP_HOST"),
+			SMTPPort:     getString("SMTP_PORT", "587"),
+			SMTPUser:     os.Getenv("SMTP_USER"),
+			SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+			LogPath:      os.Getenv("MAIL_LOG_PATH"),
 		},
+		Lockout: LockoutConfig{
+			Store:         getString("LOGIN_LOCKOUT_STORE", "memory"),
+			FreeAttempts:  getInt("LOGIN_FREE_ATTEMPTS", 3),
+			MaxFailures:   getInt("LOGIN_MAX_FAILURES", 10),
+			IPMaxFailures: getInt("LOGIN_IP_MAX_FAILURES", 50),
+			BaseDelay:     getDuration("LOGIN_BASE_DELAY", time.Second),
+			MaxDelay:      getDuration("LOGIN_MAX_DELAY", time.Minute),
+			LockDuration:  getDuration("LOGIN_LOCK_DURATION", 15*time.Minute),
+		},
+		OIDC: OIDCConfig{
+			Providers: loadOIDCProviders(os.Getenv("OIDC_PROVIDERS")),
+			StateTTL:  getDuration("OIDC_STATE_TTL", 10*time.Minute),
+		},
+		SMS: SMSConfig{
+			Driver:  getString("SMS_DRIVER", "log"),
+			BaseURL: os.Getenv("SMS_BASE_URL"),
+			APIKey:  os.Getenv("SMS_API_KEY"),
+			From:    os.Getenv("SMS_FROM"),
+			LogPath: os.Getenv("SMS_LOG_PATH"),
+		},
+		OTP: OTPConfig{
+			TTL:            getDuration("OTP_TTL", 5*time.Minute),
+			ResendCooldown: getDuration("OTP_RESEND_COOLDOWN", time.Minute),
+			MaxAttempts:    getInt("OTP_MAX_ATTEMPTS", 5),
+		},
+	}
+}
+
+// getString читает строку, пустое значение заменяется значением по умолчанию.
+func getString(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// getInt читает положительное целое, при ошибке возвращает значение по умолчанию.
+func getInt(key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		log.Printf("Invalid %s=%q, using default %d", key, v, def)
+		return def
+	}
+	return n
+}
+
+// getBool читает булево значение ("true", "1", "false", "0"...).
+func getBool(key string, def bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		log.Printf("Invalid %s=%q, using default %t", key, v, def)
+		return def
+	}
+	return b
+}
+
+// getDuration читает длительность вида "15m" / "720h", при ошибке возвращает значение по умолчанию.
+func getDuration(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		log.Printf("Invalid %s=%q, using default %s", key, v, def)
+		return def
+	}
+	return d
+}
+
+// getTime читает момент времени в формате RFC 3339, пустое или неверное значение — нулевое время.
+func getTime(key string) time.Time {
+	v := os.Getenv(key)
+	if v == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		log.Printf("Invalid %s=%q, ignoring", key, v)
+		return time.Time{}
+	}
+	return t
+}
+
+// parseJWTKeys разбирает список ключей вида
+// "kid=/path/key.pem,old=/path/old.pem@2025-10-01T00:00:00Z",
+// где после @ указан момент вывода ключа из оборота.
+func parseJWTKeys(v string) []JWTKeyConfig {
+	var keys []JWTKeyConfig
+	for _, item := range strings.Split(v, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		id, rest, ok := strings.Cut(item, "=")
+		if !ok || id == "" || rest == "" {
+			log.Printf("Invalid JWT_KEYS entry %q, skipping", item)
+			continue
+		}
+		key := JWTKeyConfig{ID: id, Path: rest}
+		if path, retired, ok := strings.Cut(rest, "@"); ok {
+			t, err := time.Parse(time.RFC3339, retired)
+			if err != nil {
+				log.Printf("Invalid retirement time in JWT_KEYS entry %q, skipping", item)
+				continue
+			}
+			key.Path = path
+			key.RetiredAt = t
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// loadOIDCProviders читает провайдеров из списка имён "google,yandex".
+// Параметры провайдера берутся из OIDC_<NAME>_*, например OIDC_GOOGLE_CLIENT_ID.
+func loadOIDCProviders(v string) []OIDCProviderConfig {
+	var providers []OIDCProviderConfig
+	for _, name := range strings.Split(v, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		p := OIDCProviderConfig{
+			Name:         name,
+			Issuer:       strings.TrimRight(os.Getenv(prefix+"ISSUER"), "/"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+			Scopes:       strings.Fields(getString(prefix+"SCOPES", "openid email profile")),
+			AuthURL:      os.Getenv(prefix + "AUTH_URL"),
+			TokenURL:     os.Getenv(prefix + "TOKEN_URL"),
+			JWKSURL:      os.Getenv(prefix + "JWKS_URL"),
+		}
+		if p.Issuer == "" || p.ClientID == "" || p.RedirectURL == "" {
+			log.Printf("OIDC provider %q needs %sISSUER, %sCLIENT_ID and %sREDIRECT_URL, skipping", name, prefix, prefix, prefix)
+			continue
+		}
+		providers = append(providers, p)
 	}
+	return providers
 }
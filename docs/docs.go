@@ -18,37 +18,46 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/auth/login": {
-            "post": {
-                "description": "Авторизация пользователя по email и паролю",
-                "consumes": [
-                    "application/json"
-                ],
+        "/.well-known/jwks.json": {
+            "get": {
+                "description": "JWKS (RFC 7517) с публичными ключами, которыми можно проверить наши токены без общего секрета",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "auth",
-                    "open",
-                    "user"
+                    "open"
                 ],
-                "summary": "Авторизация пользователя",
-                "parameters": [
-                    {
-                        "description": "Данные для авторизации",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
+                "summary": "Публичные ключи подписи JWT",
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/auth.LoginRequest"
+                            "$ref": "#/definitions/jwt.JWKS"
                         }
                     }
+                }
+            }
+        },
+        "/apikeys": {
+            "get": {
+                "description": "Возвращает все ключи без секретной части, с датой последнего использования",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "apikeys",
+                    "admin"
                 ],
+                "summary": "Список API-ключей (админ)",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/auth.LoginResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/apikeys.APIKeyResponse"
+                            }
                         }
                     },
                     "401": {
@@ -60,6 +69,15 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -70,11 +88,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/auth/register": {
+            },
             "post": {
-                "description": "Создаёт нового пользователя и возвращает JWT",
+                "description": "Создаёт ключ для межсервисного доступа. Ключ возвращается только в этом ответе, передаётся в заголовке X-API-Key. Доступные права: products:write, users:read, addresses:read",
                 "consumes": [
                     "application/json"
                 ],
@@ -82,27 +98,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth",
-                    "open",
-                    "user"
+                    "apikeys",
+                    "admin"
                 ],
-                "summary": "Регистрация пользователя",
+                "summary": "Выпустить API-ключ (админ)",
                 "parameters": [
                     {
-                        "description": "Данные регистрации",
+                        "description": "Название, права и срок действия",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/auth.RegisterRequest"
+                            "$ref": "#/definitions/apikeys.CreateAPIKeyRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/auth.RegisterResponse"
+                            "$ref": "#/definitions/apikeys.CreateAPIKeyResponse"
                         }
                     },
                     "400": {
@@ -114,6 +129,24 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -126,39 +159,31 @@ const docTemplate = `{
                 }
             }
         },
-        "/products": {
-            "get": {
-                "description": "Возвращает список продуктов (пагинация через limit/offset)",
+        "/apikeys/{id}": {
+            "delete": {
+                "description": "Ключ перестаёт приниматься сразу после отзыва",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products",
-                    "open"
+                    "apikeys",
+                    "admin"
                 ],
-                "summary": "Список продуктов",
+                "summary": "Отозвать API-ключ (админ)",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "limit",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "offset",
-                        "name": "offset",
-                        "in": "query"
+                        "description": "ID ключа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/products.Product"
-                            }
+                            "$ref": "#/definitions/apikeys.APIKeyResponse"
                         }
                     },
                     "400": {
@@ -170,6 +195,33 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -180,9 +232,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/auth/2fa/confirm": {
             "post": {
-                "description": "Создаёт новый продукт",
+                "description": "Подтверждает подключение кодом из приложения и возвращает одноразовые коды восстановления (показываются один раз)",
                 "consumes": [
                     "application/json"
                 ],
@@ -190,26 +244,27 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "products",
-                    "admin"
+                    "auth",
+                    "jwt",
+                    "user"
                 ],
-                "summary": "Создать продукт (админ)",
+                "summary": "Включить 2FA",
                 "parameters": [
                     {
-                        "description": "Product data",
+                        "description": "Код из приложения",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/products.ProductCreateRequest"
+                            "$ref": "#/definitions/auth.TwoFactorConfirmRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/products.Product"
+                            "$ref": "#/definitions/auth.TwoFactorConfirmResponse"
                         }
                     },
                     "400": {
@@ -221,6 +276,24 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -233,34 +306,27 @@ const docTemplate = `{
                 }
             }
         },
-        "/products/{slug}": {
-            "get": {
+        "/auth/2fa/setup": {
+            "post": {
+                "description": "Выпускает TOTP-секрет и otpauth-ссылку для приложения-аутентификатора. 2FA включится после POST /auth/2fa/confirm",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products",
-                    "open"
-                ],
-                "summary": "Получить блюдо по slug, переход на конкретное блюдо",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "slug",
-                        "name": "slug",
-                        "in": "path",
-                        "required": true
-                    }
+                    "auth",
+                    "jwt",
+                    "user"
                 ],
+                "summary": "Начать подключение 2FA",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/products.Product"
+                            "$ref": "#/definitions/auth.TwoFactorSetupResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -268,38 +334,17 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
-                    }
-                }
-            },
-            "delete": {
-                "tags": [
-                    "products",
-                    "admin"
-                ],
-                "summary": "Удалить продукт (админ)",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "slug",
-                        "name": "slug",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "204": {
-                        "description": "No Content"
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -308,8 +353,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "patch": {
+            }
+        },
+        "/auth/2fa/verify": {
+            "post": {
+                "description": "Обменивает токен второго шага из /auth/login и код из приложения (или код восстановления) на пару токенов",
                 "consumes": [
                     "application/json"
                 ],
@@ -317,25 +365,19 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "products",
-                    "admin"
+                    "auth",
+                    "open",
+                    "user"
                 ],
-                "summary": "Обновить продукт (админ)",
+                "summary": "Второй шаг входа",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "slug",
-                        "name": "slug",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Fields to update",
+                        "description": "Токен второго шага и код",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/products.ProductUpdateRequest"
+                            "$ref": "#/definitions/auth.TwoFactorVerifyRequest"
                         }
                     }
                 ],
@@ -343,7 +385,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/products.Product"
+                            "$ref": "#/definitions/auth.TwoFactorVerifyResponse"
                         }
                     },
                     "400": {
@@ -355,8 +397,26 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -367,8 +427,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/products/{slug}/change": {
+        "/auth/login": {
             "post": {
+                "description": "Авторизация пользователя по email и паролю. Возвращает короткоживущий access-токен и refresh-токен.\nПосле нескольких неудачных попыток вход временно блокируется (429 с заголовком Retry-After)\nЕсли у пользователя включена 2FA, возвращается 202 с токеном второго шага; токены выдаёт POST /auth/2fa/verify",
                 "consumes": [
                     "application/json"
                 ],
@@ -376,25 +437,19 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "products",
-                    "admin"
+                    "auth",
+                    "open",
+                    "user"
                 ],
-                "summary": "Сменить slug продукта (админ)",
+                "summary": "Авторизация пользователя",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "current slug",
-                        "name": "slug",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "new slug",
+                        "description": "Данные для авторизации",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/products.ProductSlugUpdateRequest"
+                            "$ref": "#/definitions/auth.LoginRequest"
                         }
                     }
                 ],
@@ -402,54 +457,26 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/products.Product"
+                            "$ref": "#/definitions/auth.LoginResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/auth.TwoFactorChallengeResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
-                    }
-                }
-            }
-        },
-        "/user/address": {
-            "get": {
-                "description": "Возвращает адреса текущего авторизованного пользователя",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "addresses",
-                    "jwt",
-                    "user"
-                ],
-                "summary": "Список адресов пользователя",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/addresses.AddressResponse"
-                            }
-                        }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "429": {
+                        "description": "Too Many Requests",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -467,38 +494,33 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/auth/logout": {
             "post": {
-                "description": "Добавляет новый адрес для текущего авторизованного пользователя",
+                "description": "Отзывает текущий access-токен. Если передан refresh-токен — отзывает и его",
                 "consumes": [
                     "application/json"
                 ],
-                "produces": [
-                    "application/json"
-                ],
                 "tags": [
-                    "addresses",
+                    "auth",
                     "jwt",
                     "user"
                 ],
-                "summary": "Создать адрес",
+                "summary": "Выход из текущей сессии",
                 "parameters": [
                     {
-                        "description": "Данные адреса",
+                        "description": "Refresh-токен текущей сессии",
                         "name": "request",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/addresses.AddressCreateRequest"
+                            "$ref": "#/definitions/auth.LogoutRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/addresses.AddressResponse"
-                        }
+                    "204": {
+                        "description": "No Content"
                     },
                     "400": {
                         "description": "Bad Request",
@@ -530,37 +552,19 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/address/{id}": {
-            "delete": {
-                "description": "Удаляет адрес (только владелец)",
+        "/auth/logout-all": {
+            "post": {
+                "description": "Отзывает все access- и refresh-токены текущего пользователя",
                 "tags": [
-                    "addresses",
+                    "auth",
                     "jwt",
                     "user"
                 ],
-                "summary": "Удалить адрес",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "ID адреса",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
+                "summary": "Выход со всех устройств",
                 "responses": {
                     "204": {
                         "description": "No Content"
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
@@ -570,28 +574,43 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
-                    },
-                    "404": {
-                        "description": "Not Found",
+                    }
+                }
+            }
+        },
+        "/auth/oidc/providers": {
+            "get": {
+                "description": "Список настроенных провайдеров OpenID Connect",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth",
+                    "open",
+                    "user"
+                ],
+                "summary": "Провайдеры входа",
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/auth.OIDCProvidersResponse"
                         }
                     }
                 }
-            },
-            "patch": {
-                "description": "Частичное обновление адреса (PATCH) — только владелец",
+            }
+        },
+        "/auth/oidc/{provider}/callback": {
+            "post": {
+                "description": "Обменивает code от провайдера на пару токенов (или токен второго шага, если включена 2FA).\nАккаунт провайдера привязывается к пользователю с тем же email, если провайдер подтвердил email; иначе создаётся новый пользователь",
                 "consumes": [
                     "application/json"
                 ],
@@ -599,26 +618,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "addresses",
-                    "jwt",
+                    "auth",
+                    "open",
                     "user"
                 ],
-                "summary": "Обновить адрес",
+                "summary": "Завершить вход через провайдера",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID адреса",
-                        "name": "id",
+                        "type": "string",
+                        "description": "Имя провайдера, например google",
+                        "name": "provider",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Поля для обновления",
+                        "description": "code и state из redirect URL",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/addresses.AddressUpdateRequest"
+                            "$ref": "#/definitions/auth.OIDCCallbackRequest"
                         }
                     }
                 ],
@@ -626,7 +645,13 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/addresses.AddressResponse"
+                            "$ref": "#/definitions/auth.LoginResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/auth.TwoFactorChallengeResponse"
                         }
                     },
                     "400": {
@@ -647,8 +672,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -656,8 +681,17 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -668,72 +702,45 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/adminaddress": {
-            "get": {
-                "description": "Возвращает список адресов c фильтрами (для админов)",
+        "/auth/oidc/{provider}/start": {
+            "post": {
+                "description": "Возвращает ссылку на страницу входа провайдера (authorization code flow с PKCE).\nПосле входа провайдер вернёт пользователя на настроенный redirect URL с параметрами code и state — их нужно передать в /auth/oidc/{provider}/callback",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "addresses",
-                    "admin"
+                    "auth",
+                    "open",
+                    "user"
                 ],
-                "summary": "Список адресов (админ)",
+                "summary": "Начать вход через провайдера",
                 "parameters": [
-                    {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Page number",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "Page size",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Filter by user id",
-                        "name": "user_id",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by city",
-                        "name": "city",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by street",
-                        "name": "street",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by phone",
-                        "name": "phone",
-                        "in": "query"
-                    },
                     {
                         "type": "string",
-                        "description": "Filter by label",
-                        "name": "label",
-                        "in": "query"
+                        "description": "Имя провайдера, например google",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/addresses.AdminAddressesResponse"
+                            "$ref": "#/definitions/auth.OIDCStartResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -744,50 +751,118 @@ const docTemplate = `{
                 }
             }
         },
-        "/users": {
-            "get": {
-                "description": "Возвращает пользователей с пагинацией и фильтрацией",
-                "produces": [
+        "/auth/otp/request": {
+            "post": {
+                "description": "Отправляет 6-значный код на подтверждённый телефон, привязанный к аккаунту. Ответ не зависит от того, зарегистрирован ли номер.\nПовторный запрос возможен после паузы (429 с заголовком Retry-After)",
+                "consumes": [
                     "application/json"
                 ],
                 "tags": [
-                    "users",
-                    "admin"
+                    "auth",
+                    "open",
+                    "user"
                 ],
-                "summary": "Получить всех пользователей (админ)",
+                "summary": "Запросить код входа по SMS",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "page",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "limit",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "Телефон",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.OTPRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted"
                     },
-                    {
-                        "type": "string",
-                        "description": "filter by name",
-                        "name": "name",
-                        "in": "query"
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/otp/verify": {
+            "post": {
+                "description": "Проверяет код и возвращает пару токенов (или токен второго шага, если включена 2FA). Число попыток ввода одного кода ограничено.\nВход возможен только по телефону, подтверждённому через POST /users/me/phone/confirm",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth",
+                    "open",
+                    "user"
+                ],
+                "summary": "Войти по коду из SMS",
+                "parameters": [
                     {
-                        "type": "string",
-                        "description": "filter by email",
-                        "name": "email",
-                        "in": "query"
+                        "description": "Телефон и код",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.OTPVerifyRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/users.UserListResponse"
+                            "$ref": "#/definitions/auth.LoginResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/auth.TwoFactorChallengeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "500": {
@@ -802,34 +877,80 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/jwt/{id}": {
-            "get": {
-                "produces": [
+        "/auth/password/forgot": {
+            "post": {
+                "description": "Отправляет на email одноразовую ссылку для сброса пароля. Ответ одинаковый независимо от того, зарегистрирован ли email",
+                "consumes": [
                     "application/json"
                 ],
                 "tags": [
-                    "users",
-                    "admin"
+                    "auth",
+                    "open",
+                    "user"
                 ],
-                "summary": "Возвращает jwt-token для пользователя по ID (админ)",
+                "summary": "Запрос сброса пароля",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID пользователя",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Email",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.ForgotPasswordRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Accepted"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
+                    }
+                }
+            }
+        },
+        "/auth/password/reset": {
+            "post": {
+                "description": "Устанавливает новый пароль по токену из письма. Токен одноразовый; после сброса все сессии пользователя завершаются",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth",
+                    "open",
+                    "user"
+                ],
+                "summary": "Сброс пароля",
+                "parameters": [
+                    {
+                        "description": "Токен и новый пароль",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.ResetPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
                     },
                     "400": {
                         "description": "Bad Request",
@@ -840,8 +961,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -852,33 +973,37 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/search": {
-            "get": {
+        "/auth/refresh": {
+            "post": {
+                "description": "Обменивает refresh-токен на новую пару токенов. Старый refresh-токен становится недействительным; его повторное использование отзывает все токены этой сессии",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users",
-                    "admin"
+                    "auth",
+                    "open",
+                    "user"
                 ],
-                "summary": "Поиск пользователей по email (админ)",
+                "summary": "Обновление токенов",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "email",
-                        "name": "email",
-                        "in": "query",
-                        "required": true
+                        "description": "Refresh-токен",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.RefreshRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/users.UserResponse"
-                            }
+                            "$ref": "#/definitions/auth.RefreshResponse"
                         }
                     },
                     "400": {
@@ -890,6 +1015,15 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -902,30 +1036,37 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{id}": {
-            "get": {
+        "/auth/register": {
+            "post": {
+                "description": "Создаёт нового пользователя, отправляет письмо для подтверждения email и возвращает пару токенов",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users",
-                    "admin"
+                    "auth",
+                    "open",
+                    "user"
                 ],
-                "summary": "Получить пользователя по ID (админ)",
+                "summary": "Регистрация пользователя",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID пользователя",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Данные регистрации",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.RegisterRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/users.UserResponse"
+                            "$ref": "#/definitions/auth.RegisterResponse"
                         }
                     },
                     "400": {
@@ -937,8 +1078,17 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -948,210 +1098,5497 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "addresses.AddressCreateRequest": {
-            "type": "object",
-            "required": [
-                "city",
-                "label",
-                "phone",
-                "street"
-            ],
-            "properties": {
-                "apartment": {
-                    "type": "string",
-                    "example": "277"
-                },
-                "city": {
-                    "type": "string",
-                    "example": "Moscow"
-                },
-                "comment": {
-                    "type": "string",
-                    "example": "Легче позвонить, чем у кого то занимать"
-                },
-                "entrance": {
-                    "type": "string",
-                    "example": "1"
-                },
-                "floor": {
-                    "type": "string",
-                    "example": "3"
-                },
-                "label": {
-                    "type": "string",
-                    "example": "home"
-                },
-                "phone": {
-                    "type": "string",
-                    "example": "+7 800 555 35 55"
-                },
-                "street": {
-                    "type": "string",
-                    "example": "Lenina 10"
-                }
+        },
+        "/auth/sessions": {
+            "get": {
+                "description": "Возвращает устройства, на которых выполнен вход. Текущая сессия отмечена полем current",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth",
+                    "jwt",
+                    "user"
+                ],
+                "summary": "Активные сессии",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/auth.SessionResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/sessions/{id}": {
+            "delete": {
+                "description": "Выходит из аккаунта на выбранном устройстве: его access- и refresh-токены перестают действовать",
+                "tags": [
+                    "auth",
+                    "jwt",
+                    "user"
+                ],
+                "summary": "Завершить сессию",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID сессии",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/unlock": {
+            "post": {
+                "description": "Сбрасывает счётчик неудачных попыток входа для email и/или IP",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth",
+                    "admin"
+                ],
+                "summary": "Снять блокировку входа (админ)",
+                "parameters": [
+                    {
+                        "description": "Email и/или IP",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.UnlockRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/verify": {
+            "get": {
+                "description": "Подтверждает email (или смену email) по одноразовому токену из письма",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth",
+                    "open",
+                    "user"
+                ],
+                "summary": "Подтверждение email",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен из письма",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth.VerifyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/verify/resend": {
+            "post": {
+                "description": "Отправляет новое письмо для подтверждения email текущего пользователя; предыдущие ссылки перестают работать",
+                "tags": [
+                    "auth",
+                    "jwt",
+                    "user"
+                ],
+                "summary": "Повторная отправка письма подтверждения",
+                "responses": {
+                    "202": {
+                        "description": "Accepted"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/categories": {
+            "get": {
+                "description": "Видимые категории с подкатегориями, упорядоченные по sort_order и имени",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "categories",
+                    "open"
+                ],
+                "summary": "Дерево категорий",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/categories.CategoryTreeResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Slug строится из имени, если не задан. Slug «all» зарезервирован",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "categories",
+                    "admin"
+                ],
+                "summary": "Создать категорию (админ)",
+                "parameters": [
+                    {
+                        "description": "Данные категории",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/categories.CategoryCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/categories.CategoryNode"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/categories/all": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "categories",
+                    "admin"
+                ],
+                "summary": "Дерево категорий со скрытыми (админ)",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/categories.CategoryTreeResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/categories/{slug}": {
+            "get": {
+                "description": "Видимая категория с подкатегориями",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "categories",
+                    "open"
+                ],
+                "summary": "Категория по slug",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/categories.CategoryNode"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет категорию без подкатегорий и отвязывает от неё продукты",
+                "tags": [
+                    "categories",
+                    "admin"
+                ],
+                "summary": "Удалить категорию (админ)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Частичное обновление. parent_id = 0 переносит категорию в корень; перенос в собственное поддерево запрещён",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "categories",
+                    "admin"
+                ],
+                "summary": "Обновить категорию (админ)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Поля для обновления",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/categories.CategoryUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/categories.CategoryNode"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/categories/{slug}/modifier-groups": {
+            "put": {
+                "description": "Группы категории действуют на все продукты категории и её подкатегорий",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "modifiers",
+                    "categories",
+                    "admin"
+                ],
+                "summary": "Привязать группы модификаторов к категории (админ)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "slug категории",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "ID групп",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/products.ModifierGroupsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/products.ModifierGroupsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/categories/{slug}/products": {
+            "get": {
+                "description": "Продукты видимой категории и всех её видимых подкатегорий. Фильтры, сортировка и пагинация — как у GET /products",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products",
+                    "categories",
+                    "open"
+                ],
+                "summary": "Продукты категории",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "slug категории",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limit (в режиме курсора по умолчанию 20, до 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "offset",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "курсор страницы",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "тип блюда",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "теги через запятую",
+                        "name": "tags",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "any",
+                        "description": "any — хотя бы один тег, all — все теги",
+                        "name": "tags_match",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "минимальная цена (from_price — самый дешёвый вариант)",
+                        "name": "price_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "максимальная цена (from_price)",
+                        "name": "price_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "минимальный рейтинг (0–5)",
+                        "name": "rating_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "обязательные ингредиенты через запятую",
+                        "name": "ingredients",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "исключаемые ингредиенты через запятую",
+                        "name": "exclude_ingredients",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "newest",
+                        "description": "newest, price, price_desc, rating, rating_desc, name, name_desc",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/products.ProductListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/impersonations": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Журнал имперсонаций (админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID администратора",
+                        "name": "actor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/impersonation.ImpersonationListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/impersonations/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Имперсонация и выполненные запросы (админ)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID имперсонации (jti токена)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/impersonation.ImpersonationDetailsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/modifier-groups": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "modifiers",
+                    "admin"
+                ],
+                "summary": "Группы модификаторов (админ)",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/products.ModifierGroupsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Группа с правилами выбора (min_select..max_select, 0 — без ограничения) и модификаторами. Убираемые ингредиенты (removable) в правилах не учитываются",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "modifiers",
+                    "admin"
+                ],
+                "summary": "Создать группу модификаторов (админ)",
+                "parameters": [
+                    {
+                        "description": "Группа",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/products.ModifierGroupCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/products.ModifierGroup"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/modifier-groups/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "modifiers",
+                    "admin"
+                ],
+                "summary": "Группа модификаторов (админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID группы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/products.ModifierGroup"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет группу с модификаторами и отвязывает её от продуктов и категорий",
+                "tags": [
+                    "modifiers",
+                    "admin"
+                ],
+                "summary": "Удалить группу модификаторов (админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID группы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Частичное обновление. modifiers заменяет набор целиком: модификатор с id обновляется, без id создаётся, отсутствующие удаляются",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "modifiers",
+                    "admin"
+                ],
+                "summary": "Обновить группу модификаторов (админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID группы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Поля для обновления",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/products.ModifierGroupUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/products.ModifierGroup"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/privacy/erasures": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "privacy",
+                    "admin"
+                ],
+                "summary": "Журнал удалений (админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/privacy.ErasureListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/privacy/users/{id}/erase": {
+            "post": {
+                "description": "Для обращений, полученных по почте. Причина (например, номер обращения) сохраняется в журнале удалений",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "privacy",
+                    "admin"
+                ],
+                "summary": "Удалить данные пользователя (админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Причина удаления",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/privacy.AdminEraseRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/privacy.ErasureResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/privacy/users/{id}/export": {
+            "get": {
+                "description": "Для обращений, полученных по почте. Формат тот же, что у /users/me/export",
+                "produces": [
+                    "application/json",
+                    "application/zip"
+                ],
+                "tags": [
+                    "privacy",
+                    "admin"
+                ],
+                "summary": "Выгрузить данные пользователя (админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "json",
+                        "description": "json или zip",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/privacy.ExportBundle"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/products": {
+            "get": {
+                "description": "Возвращает список продуктов с фильтрами и сортировкой.\nПагинация через limit/offset или, если передан cursor (для первой страницы — пустой), по курсору: next_cursor/prev_cursor в ответе и заголовок Link.\nСписки передаются через запятую. При неверном значении ответ 400 с именем параметра в поле param",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products",
+                    "open"
+                ],
+                "summary": "Список продуктов",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "limit (в режиме курсора по умолчанию 20, до 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "offset",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "курсор страницы",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "тип блюда",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "теги через запятую",
+                        "name": "tags",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "any",
+                        "description": "any — хотя бы один тег, all — все теги",
+                        "name": "tags_match",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "минимальная цена (from_price — самый дешёвый вариант)",
+                        "name": "price_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "максимальная цена (from_price)",
+                        "name": "price_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "минимальный рейтинг (0–5)",
+                        "name": "rating_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "обязательные ингредиенты через запятую",
+                        "name": "ingredients",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "исключаемые ингредиенты через запятую",
+                        "name": "exclude_ingredients",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "newest",
+                        "description": "newest, price, price_desc, rating, rating_desc, name, name_desc",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/products.ProductListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Создаёт новый продукт, можно сразу с вариантами (размер, объём). С вариантами цена продукта берётся у варианта по умолчанию, а from_price — минимальная цена варианта",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products",
+                    "admin"
+                ],
+                "summary": "Создать продукт (админ)",
+                "parameters": [
+                    {
+                        "description": "Product data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/products.ProductCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/products.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/products/search": {
+            "get": {
+                "description": "Полнотекстовый поиск по названию, типу, тегам и ингредиентам с учётом русской морфологии.\nКаждое слово ищется по префиксу («марг» найдёт «Маргарита»). Результаты отсортированы по релевантности, совпадения подсвечены тегом \u003cmark\u003e",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products",
+                    "open"
+                ],
+                "summary": "Поиск продуктов",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "поисковый запрос",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "offset",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/products.ProductSearchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/products/suggest": {
+            "get": {
+                "description": "Подсказки для строки поиска с учётом опечаток. Латиница находит блюда с кириллическими названиями и наоборот («pepperony» → «Пепперони»).\nДля запросов короче двух символов возвращается пустой список",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products",
+                    "open"
+                ],
+                "summary": "Подсказки по названию",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "начало или часть названия",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "limit (до 20)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/products.SuggestResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{slug}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products",
+                    "open"
+                ],
+                "summary": "Получить блюдо по slug, переход на конкретное блюдо",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/products.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "tags": [
+                    "products",
+                    "admin"
+                ],
+                "summary": "Удалить продукт (админ)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Частичное обновление. variants заменяет набор вариантов целиком: вариант с id обновляется, без id создаётся, отсутствующие удаляются",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products",
+                    "admin"
+                ],
+                "summary": "Обновить продукт (админ)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to update",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/products.ProductUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/products.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{slug}/change": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products",
+                    "admin"
+                ],
+                "summary": "Сменить slug продукта (админ)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "current slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "new slug",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/products.ProductSlugUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/products.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{slug}/modifier-groups": {
+            "put": {
+                "description": "Заменяет собственные группы продукта; группы категорий не затрагиваются",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "modifiers",
+                    "admin"
+                ],
+                "summary": "Привязать группы модификаторов к продукту (админ)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "slug продукта",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "ID групп",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/products.ModifierGroupsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/products.ModifierGroupsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{slug}/modifiers": {
+            "get": {
+                "description": "Группы модификаторов продукта: собственные и унаследованные от категорий",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products",
+                    "modifiers",
+                    "open"
+                ],
+                "summary": "Модификаторы продукта",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "slug продукта",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/products.ModifierGroupsResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{slug}/price": {
+            "post": {
+                "description": "Проверяет выбранные вариант и модификаторы по правилам групп и возвращает итоговую цену позиции",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products",
+                    "modifiers",
+                    "open"
+                ],
+                "summary": "Рассчитать цену позиции",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "slug продукта",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Выбор покупателя",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/products.PriceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/products.PriceQuote"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/user/address": {
+            "get": {
+                "description": "Возвращает адреса текущего авторизованного пользователя",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "addresses",
+                    "jwt",
+                    "user"
+                ],
+                "summary": "Список адресов пользователя",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/addresses.AddressResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Добавляет новый адрес для текущего авторизованного пользователя",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "addresses",
+                    "jwt",
+                    "user"
+                ],
+                "summary": "Создать адрес",
+                "parameters": [
+                    {
+                        "description": "Данные адреса",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/addresses.AddressCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/addresses.AddressResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/user/address/{id}": {
+            "delete": {
+                "description": "Удаляет адрес (только владелец)",
+                "tags": [
+                    "addresses",
+                    "jwt",
+                    "user"
+                ],
+                "summary": "Удалить адрес",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID адреса",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Частичное обновление адреса (PATCH) — только владелец",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "addresses",
+                    "jwt",
+                    "user"
+                ],
+                "summary": "Обновить адрес",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID адреса",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Поля для обновления",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/addresses.AddressUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/addresses.AddressResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/user/adminaddress": {
+            "get": {
+                "description": "Возвращает список адресов c фильтрами (для админов).\nЕсли передан cursor (для первой страницы — пустой), пагинация по курсору: next_cursor/prev_cursor в ответе и заголовок Link",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "addresses",
+                    "admin"
+                ],
+                "summary": "Список адресов (админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Page cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by user id",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by city",
+                        "name": "city",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by street",
+                        "name": "street",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by phone",
+                        "name": "phone",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by label",
+                        "name": "label",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/addresses.AdminAddressesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "description": "Возвращает пользователей с пагинацией и фильтрацией.\nЕсли передан cursor (для первой страницы — пустой), пагинация по курсору: next_cursor/prev_cursor в ответе и заголовок Link, page игнорируется",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users",
+                    "admin"
+                ],
+                "summary": "Получить всех пользователей (админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "курсор страницы",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "name, name_desc, email, email_desc, created_at, created_at_desc",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "filter by name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "filter by email",
+                        "name": "email",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "active, suspended или deleted",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "включать удалённых",
+                        "name": "include_deleted",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/users.UserListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me": {
+            "get": {
+                "description": "Возвращает профиль текущего пользователя",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users",
+                    "jwt",
+                    "user"
+                ],
+                "summary": "Мой профиль",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/users.UserResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Обезличивает профиль, безвозвратно удаляет адреса, отзывает все токены и сессии. Если у аккаунта есть пароль, его нужно передать",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users",
+                    "privacy",
+                    "jwt",
+                    "user"
+                ],
+                "summary": "Удалить мой аккаунт",
+                "parameters": [
+                    {
+                        "description": "Подтверждение паролем",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/privacy.EraseMeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Меняет имя, телефон, язык (ru, en) и согласие на рекламные рассылки. Передаются только изменяемые поля; пустой phone удаляет телефон.\nEmail и пароль меняются через /users/me/email и /users/me/password",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users",
+                    "jwt",
+                    "user"
+                ],
+                "summary": "Изменить мой профиль",
+                "parameters": [
+                    {
+                        "description": "Изменяемые поля",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/users.UpdateProfileRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/users.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/email": {
+            "post": {
+                "description": "Отправляет письмо с подтверждением на новый адрес. Email аккаунта меняется только после перехода по ссылке из письма (GET /auth/verify)",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users",
+                    "jwt",
+                    "user"
+                ],
+                "summary": "Смена email",
+                "parameters": [
+                    {
+                        "description": "Новый email и текущий пароль",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.ChangeEmailRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/export": {
+            "get": {
+                "description": "Возвращает все данные, которые сервис хранит о пользователе: профиль, адреса, сессии, привязанные аккаунты. format=zip отдаёт zip-архив с JSON-файлами",
+                "produces": [
+                    "application/json",
+                    "application/zip"
+                ],
+                "tags": [
+                    "users",
+                    "privacy",
+                    "jwt",
+                    "user"
+                ],
+                "summary": "Выгрузить мои данные",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "json",
+                        "description": "json или zip",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/privacy.ExportBundle"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/password": {
+            "post": {
+                "description": "Меняет пароль текущего пользователя. Требует текущий пароль; все остальные сессии завершаются, для текущей выдаётся новая пара токенов",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users",
+                    "jwt",
+                    "user"
+                ],
+                "summary": "Смена пароля",
+                "parameters": [
+                    {
+                        "description": "Текущий и новый пароль",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.ChangePasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth.ChangePasswordResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/phone/confirm": {
+            "post": {
+                "description": "Проверяет код из SMS и отмечает телефон подтверждённым",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth",
+                    "jwt",
+                    "user"
+                ],
+                "summary": "Подтвердить телефон",
+                "parameters": [
+                    {
+                        "description": "Код",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.PhoneConfirmRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/users.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/phone/verify": {
+            "post": {
+                "description": "Отправляет 6-значный код на телефон из профиля. Пока телефон не подтверждён, войти по SMS нельзя",
+                "tags": [
+                    "auth",
+                    "jwt",
+                    "user"
+                ],
+                "summary": "Запросить код подтверждения телефона",
+                "responses": {
+                    "202": {
+                        "description": "Accepted"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/search": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users",
+                    "admin"
+                ],
+                "summary": "Поиск пользователей по email (админ)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "email",
+                        "name": "email",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/users.UserResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users",
+                    "admin"
+                ],
+                "summary": "Получить пользователя по ID (админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/users.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Мягкое удаление: данные сохраняются, аккаунт можно восстановить. Токены пользователя отзываются",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users",
+                    "admin"
+                ],
+                "summary": "Удалить пользователя (админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Причина",
+                        "name": "reason",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Меняет имя, email и роль. При смене email или роли все токены пользователя отзываются; новый email нужно подтвердить",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users",
+                    "admin"
+                ],
+                "summary": "Изменить пользователя (админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Изменяемые поля",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/users.AdminUpdateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/users.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/impersonate": {
+            "post": {
+                "description": "Выдаёт короткоживущий access-токен пользователя с claim act (ID администратора). Refresh-токен не выдаётся.\nС таким токеном нельзя менять пароль, email, 2FA и сессии, удалять аккаунт. Начало и каждый запрос записываются в журнал. Администраторов имперсонировать нельзя",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users",
+                    "admin"
+                ],
+                "summary": "Войти от имени пользователя (админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Причина",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/impersonation.StartRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/impersonation.StartResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/restore": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users",
+                    "admin"
+                ],
+                "summary": "Восстановить удалённого пользователя (админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Причина",
+                        "name": "reason",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/users.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/suspend": {
+            "post": {
+                "description": "Заблокированный пользователь не может войти, его токены отзываются",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users",
+                    "admin"
+                ],
+                "summary": "Заблокировать пользователя (админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Причина",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/users.StatusChangeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/users.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/unsuspend": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users",
+                    "admin"
+                ],
+                "summary": "Разблокировать пользователя (админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Причина",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/users.StatusChangeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/users.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "addresses.AddressCreateRequest": {
+            "type": "object",
+            "required": [
+                "city",
+                "label",
+                "phone",
+                "street"
+            ],
+            "properties": {
+                "apartment": {
+                    "type": "string",
+                    "example": "277"
+                },
+                "city": {
+                    "type": "string",
+                    "example": "Moscow"
+                },
+                "comment": {
+                    "type": "string",
+                    "example": "Легче позвонить, чем у кого то занимать"
+                },
+                "entrance": {
+                    "type": "string",
+                    "example": "1"
+                },
+                "floor": {
+                    "type": "string",
+                    "example": "3"
+                },
+                "label": {
+                    "type": "string",
+                    "example": "home"
+                },
+                "phone": {
+                    "type": "string",
+                    "example": "+7 800 555 35 55"
+                },
+                "street": {
+                    "type": "string",
+                    "example": "Lenina 10"
+                }
+            }
+        },
+        "addresses.AddressResponse": {
+            "type": "object",
+            "properties": {
+                "apartment": {
+                    "type": "string"
+                },
+                "city": {
+                    "type": "string"
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "entrance": {
+                    "type": "string"
+                },
+                "floor": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "street": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "addresses.AddressUpdateRequest": {
+            "type": "object",
+            "properties": {
+                "apartment": {
+                    "type": "string",
+                    "example": "33"
+                },
+                "city": {
+                    "type": "string",
+                    "example": "Saint-Petersburg"
+                },
+                "comment": {
+                    "type": "string",
+                    "example": "Не работает домофон"
+                },
+                "entrance": {
+                    "type": "string",
+                    "example": "A"
+                },
+                "floor": {
+                    "type": "string",
+                    "example": "27"
+                },
+                "label": {
+                    "type": "string",
+                    "example": "work"
+                },
+                "phone": {
+                    "type": "string",
+                    "example": "+7 952 812 52 52"
+                },
+                "street": {
+                    "type": "string",
+                    "example": "Pushkina 5"
+                }
+            }
+        },
+        "addresses.AdminAddressesResponse": {
+            "type": "object",
+            "properties": {
+                "addresses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/addresses.AddressResponse"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "prev_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "total_pages": {
+                    "type": "integer"
+                }
+            }
+        },
+        "apikeys.APIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string",
+                    "example": "bk_live_AbCd"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "apikeys.CreateAPIKeyRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "expires_at": {
+                    "type": "string",
+                    "example": "2026-01-01T00:00:00Z"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 128,
+                    "example": "catalog-sync"
+                },
+                "scopes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "products:write"
+                    ]
+                }
+            }
+        },
+        "apikeys.CreateAPIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "key": {
+                    "type": "string",
+                    "example": "bk_live_AbCdEf..."
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string",
+                    "example": "bk_live_AbCd"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "auth.ChangeEmailRequest": {
+            "type": "object",
+            "required": [
+                "current_password",
+                "new_email"
+            ],
+            "properties": {
+                "current_password": {
+                    "type": "string",
+                    "example": "secret"
+                },
+                "new_email": {
+                    "type": "string",
+                    "example": "new@example.com"
+                }
+            }
+        },
+        "auth.ChangePasswordRequest": {
+            "type": "object",
+            "required": [
+                "current_password",
+                "new_password"
+            ],
+            "properties": {
+                "current_password": {
+                    "type": "string",
+                    "example": "secret"
+                },
+                "new_password": {
+                    "type": "string",
+                    "minLength": 8,
+                    "example": "new-secret"
+                }
+            }
+        },
+        "auth.ChangePasswordResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string",
+                    "example": "2025-10-07T12:15:00Z"
+                },
+                "refresh_expires_at": {
+                    "type": "string",
+                    "example": "2025-11-06T12:00:00Z"
+                },
+                "refresh_token": {
+                    "type": "string",
+                    "example": "q7v1Jm0xk3..."
+                },
+                "token": {
+                    "type": "string",
+                    "example": "eyJhbGciOi..."
+                }
+            }
+        },
+        "auth.ForgotPasswordRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "email@example.com"
+                }
+            }
+        },
+        "auth.LoginRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "device_name": {
+                    "description": "Название устройства для списка сессий (иначе берётся из заголовка X-Device-Name)",
+                    "type": "string",
+                    "maxLength": 128,
+                    "example": "iPhone Ивана"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "email@example.com"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "secret"
+                }
+            }
+        },
+        "auth.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string",
+                    "example": "2025-10-07T12:15:00Z"
+                },
+                "refresh_expires_at": {
+                    "type": "string",
+                    "example": "2025-11-06T12:00:00Z"
+                },
+                "refresh_token": {
+                    "type": "string",
+                    "example": "q7v1Jm0xk3..."
+                },
+                "token": {
+                    "type": "string",
+                    "example": "eyJhbGciOi..."
+                }
+            }
+        },
+        "auth.LogoutRequest": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string",
+                    "example": "q7v1Jm0xk3..."
+                }
+            }
+        },
+        "auth.OIDCCallbackRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "state"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "4/0AX4XfWh..."
+                },
+                "device_name": {
+                    "type": "string",
+                    "maxLength": 128,
+                    "example": "iPhone Ивана"
+                },
+                "state": {
+                    "type": "string",
+                    "example": "3JmQ0oV..."
+                }
+            }
+        },
+        "auth.OIDCProvidersResponse": {
+            "type": "object",
+            "properties": {
+                "providers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "google"
+                    ]
+                }
+            }
+        },
+        "auth.OIDCStartResponse": {
+            "type": "object",
+            "properties": {
+                "authorization_url": {
+                    "type": "string",
+                    "example": "https://accounts.google.com/o/oauth2/v2/auth?client_id=..."
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2025-10-07T12:10:00Z"
+                },
+                "state": {
+                    "type": "string",
+                    "example": "3JmQ0oV..."
+                }
+            }
+        },
+        "auth.OTPRequest": {
+            "type": "object",
+            "required": [
+                "phone"
+            ],
+            "properties": {
+                "phone": {
+                    "type": "string",
+                    "example": "+7 900 123-45-67"
+                }
+            }
+        },
+        "auth.OTPVerifyRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "phone"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                },
+                "device_name": {
+                    "type": "string",
+                    "maxLength": 128,
+                    "example": "iPhone Ивана"
+                },
+                "phone": {
+                    "type": "string",
+                    "example": "+7 900 123-45-67"
+                }
+            }
+        },
+        "auth.PhoneConfirmRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                }
+            }
+        },
+        "auth.RefreshRequest": {
+            "type": "object",
+            "required": [
+                "refresh_token"
+            ],
+            "properties": {
+                "refresh_token": {
+                    "type": "string",
+                    "example": "q7v1Jm0xk3..."
+                }
+            }
+        },
+        "auth.RefreshResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string",
+                    "example": "2025-10-07T12:15:00Z"
+                },
+                "refresh_expires_at": {
+                    "type": "string",
+                    "example": "2025-11-06T12:00:00Z"
+                },
+                "refresh_token": {
+                    "type": "string",
+                    "example": "q7v1Jm0xk3..."
+                },
+                "token": {
+                    "type": "string",
+                    "example": "eyJhbGciOi..."
+                }
+            }
+        },
+        "auth.RegisterRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "name",
+                "password"
+            ],
+            "properties": {
+                "device_name": {
+                    "type": "string",
+                    "maxLength": 128,
+                    "example": "iPhone Ивана"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "email@example.com"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Ivan"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "secret"
+                },
+                "phone": {
+                    "type": "string",
+                    "example": "+7 900 123-45-67"
+                }
+            }
+        },
+        "auth.RegisterResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string",
+                    "example": "2025-10-07T12:15:00Z"
+                },
+                "refresh_expires_at": {
+                    "type": "string",
+                    "example": "2025-11-06T12:00:00Z"
+                },
+                "refresh_token": {
+                    "type": "string",
+                    "example": "q7v1Jm0xk3..."
+                },
+                "token": {
+                    "type": "string",
+                    "example": "eyJhbGciOi..."
+                }
+            }
+        },
+        "auth.ResetPasswordRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "token"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "minLength": 8,
+                    "example": "new-secret"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "q7v1Jm0xk3..."
+                }
+            }
+        },
+        "auth.SessionResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-10-07T12:00:00Z"
+                },
+                "current": {
+                    "description": "Сессия, в которой выдан токен текущего запроса",
+                    "type": "boolean"
+                },
+                "device_name": {
+                    "type": "string",
+                    "example": "iPhone Ивана"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "3f1c2a9e-5b7d-4c1e-9a0b-2d4e6f8a0c1e"
+                },
+                "ip": {
+                    "type": "string",
+                    "example": "203.0.113.7"
+                },
+                "last_seen_at": {
+                    "type": "string",
+                    "example": "2025-10-07T12:30:00Z"
+                },
+                "user_agent": {
+                    "type": "string",
+                    "example": "BikeApp/2.3 (iOS 18.1)"
+                }
+            }
+        },
+        "auth.TwoFactorChallengeResponse": {
+            "type": "object",
+            "properties": {
+                "challenge_token": {
+                    "type": "string",
+                    "example": "Zk3r9QpL..."
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2025-10-07T12:05:00Z"
+                },
+                "two_factor_required": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "auth.TwoFactorConfirmRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                }
+            }
+        },
+        "auth.TwoFactorConfirmResponse": {
+            "type": "object",
+            "properties": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "k2f7q-x9m3a"
+                    ]
+                }
+            }
+        },
+        "auth.TwoFactorSetupResponse": {
+            "type": "object",
+            "properties": {
+                "otpauth_uri": {
+                    "type": "string",
+                    "example": "otpauth://totp/API-Bike:email@example.com?secret=JBSWY3DPEHPK3PXP\u0026issuer=API-Bike"
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "JBSWY3DPEHPK3PXP"
+                }
+            }
+        },
+        "auth.TwoFactorVerifyRequest": {
+            "type": "object",
+            "required": [
+                "challenge_token",
+                "code"
+            ],
+            "properties": {
+                "challenge_token": {
+                    "type": "string",
+                    "example": "Zk3r9QpL..."
+                },
+                "code": {
+                    "description": "6-значный код из приложения или код восстановления",
+                    "type": "string",
+                    "example": "123456"
+                },
+                "device_name": {
+                    "type": "string",
+                    "maxLength": 128,
+                    "example": "iPhone Ивана"
+                }
+            }
+        },
+        "auth.TwoFactorVerifyResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string",
+                    "example": "2025-10-07T12:15:00Z"
+                },
+                "refresh_expires_at": {
+                    "type": "string",
+                    "example": "2025-11-06T12:00:00Z"
+                },
+                "refresh_token": {
+                    "type": "string",
+                    "example": "q7v1Jm0xk3..."
+                },
+                "token": {
+                    "type": "string",
+                    "example": "eyJhbGciOi..."
+                }
+            }
+        },
+        "auth.UnlockRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "email@example.com"
+                },
+                "ip": {
+                    "type": "string",
+                    "example": "203.0.113.7"
+                }
+            }
+        },
+        "auth.VerifyResponse": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "email@example.com"
+                },
+                "verified_at": {
+                    "type": "string",
+                    "example": "2025-10-07T12:00:00Z"
+                }
+            }
+        },
+        "categories.Category": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "description": "Родительская категория; nil — корень",
+                    "type": "integer"
+                },
+                "slug": {
+                    "type": "string"
+                },
+                "sort_order": {
+                    "type": "integer"
+                },
+                "visible": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "categories.CategoryCreateRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 128,
+                    "example": "Кофе"
+                },
+                "parent_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "slug": {
+                    "description": "Если не задан — строится из имени",
+                    "type": "string",
+                    "maxLength": 128,
+                    "example": "coffee"
+                },
+                "sort_order": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "visible": {
+                    "description": "По умолчанию true",
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "categories.CategoryNode": {
+            "type": "object",
+            "properties": {
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/categories.CategoryNode"
+                    }
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Кофе"
+                },
+                "parent_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "coffee"
+                },
+                "sort_order": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "visible": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "categories.CategoryTreeResponse": {
+            "type": "object",
+            "properties": {
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/categories.CategoryNode"
+                    }
+                }
+            }
+        },
+        "categories.CategoryUpdateRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 128,
+                    "minLength": 1
+                },
+                "parent_id": {
+                    "description": "0 — перенести в корень",
+                    "type": "integer"
+                },
+                "slug": {
+                    "type": "string",
+                    "maxLength": 128,
+                    "minLength": 1
+                },
+                "sort_order": {
+                    "type": "integer"
+                },
+                "visible": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "impersonation.ActionResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                }
+            }
+        },
+        "impersonation.ImpersonationDetailsResponse": {
+            "type": "object",
+            "properties": {
+                "actions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/impersonation.ActionResponse"
+                    }
+                },
+                "actor_email": {
+                    "type": "string"
+                },
+                "actor_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "impersonation.ImpersonationListResponse": {
+            "type": "object",
+            "properties": {
+                "impersonations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/impersonation.ImpersonationResponse"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "total_pages": {
+                    "type": "integer"
+                }
+            }
+        },
+        "impersonation.ImpersonationResponse": {
+            "type": "object",
+            "properties": {
+                "actor_email": {
+                    "type": "string"
+                },
+                "actor_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "impersonation.StartRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500,
+                    "example": "Обращение №1542: не оформляется заказ"
+                }
+            }
+        },
+        "impersonation.StartResponse": {
+            "type": "object",
+            "properties": {
+                "actor_email": {
+                    "type": "string"
+                },
+                "actor_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "token": {
+                    "description": "Access-токен пользователя с claim act; refresh-токен не выдаётся",
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "jwt.JWK": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string"
+                },
+                "crv": {
+                    "description": "Ed25519 (OKP)",
+                    "type": "string"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string"
+                },
+                "n": {
+                    "description": "RSA",
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string"
+                },
+                "x": {
+                    "type": "string"
+                }
+            }
+        },
+        "jwt.JWKS": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/jwt.JWK"
+                    }
+                }
+            }
+        },
+        "privacy.AdminEraseRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500,
+                    "example": "Письмо в поддержку от 12.03, обращение №1542"
+                }
+            }
+        },
+        "privacy.EraseMeRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "example": "secret123"
+                }
+            }
+        },
+        "privacy.ErasureListResponse": {
+            "type": "object",
+            "properties": {
+                "erasures": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/privacy.ErasureResponse"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "total_pages": {
+                    "type": "integer"
+                }
             }
         },
-        "addresses.AddressResponse": {
+        "privacy.ErasureResponse": {
             "type": "object",
             "properties": {
-                "apartment": {
+                "addresses_deleted": {
+                    "type": "integer"
+                },
+                "channel": {
                     "type": "string"
                 },
-                "city": {
+                "created_at": {
                     "type": "string"
                 },
-                "comment": {
+                "email_hash": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "requested_by_id": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "privacy.ExportBundle": {
+            "type": "object",
+            "properties": {
+                "addresses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/addresses.AddressResponse"
+                    }
+                },
+                "exported_at": {
                     "type": "string"
                 },
+                "linked_accounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/privacy.ExportLinkedAccount"
+                    }
+                },
+                "sessions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/privacy.ExportSession"
+                    }
+                },
+                "two_factor_enabled": {
+                    "type": "boolean"
+                },
+                "user": {
+                    "$ref": "#/definitions/privacy.ExportUser"
+                }
+            }
+        },
+        "privacy.ExportLinkedAccount": {
+            "type": "object",
+            "properties": {
                 "created_at": {
                     "type": "string"
                 },
-                "entrance": {
+                "email": {
                     "type": "string"
                 },
-                "floor": {
+                "provider": {
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string"
+                }
+            }
+        },
+        "privacy.ExportSession": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "device_name": {
                     "type": "string"
                 },
                 "id": {
-                    "type": "integer"
+                    "type": "string"
                 },
-                "label": {
+                "ip": {
                     "type": "string"
                 },
-                "phone": {
+                "last_seen_at": {
                     "type": "string"
                 },
-                "street": {
+                "revoked_at": {
                     "type": "string"
                 },
-                "user_id": {
-                    "type": "integer"
+                "user_agent": {
+                    "type": "string"
                 }
             }
         },
-        "addresses.AddressUpdateRequest": {
+        "privacy.ExportUser": {
             "type": "object",
             "properties": {
-                "apartment": {
+                "created_at": {
                     "type": "string",
-                    "example": "33"
+                    "example": "2025-10-07T12:00:00Z"
                 },
-                "city": {
+                "deleted_at": {
                     "type": "string",
-                    "example": "Saint-Petersburg"
+                    "example": "2025-11-02T10:00:00Z"
                 },
-                "comment": {
+                "email": {
                     "type": "string",
-                    "example": "Не работает домофон"
+                    "example": "john.doe@example.com"
                 },
-                "entrance": {
+                "email_verified": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "marketing_consent": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "marketing_consent_at": {
+                    "type": "string"
+                },
+                "name": {
                     "type": "string",
-                    "example": "A"
+                    "example": "John Doe"
                 },
-                "floor": {
+                "phone": {
                     "type": "string",
-                    "example": "27"
+                    "example": "+79001234567"
                 },
-                "label": {
+                "phone_verified": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "phone_verified_at": {
+                    "type": "string"
+                },
+                "preferred_language": {
                     "type": "string",
-                    "example": "work"
+                    "example": "ru"
                 },
-                "phone": {
+                "role": {
                     "type": "string",
-                    "example": "+7 952 812 52 52"
+                    "example": "customer"
                 },
-                "street": {
+                "status": {
                     "type": "string",
-                    "example": "Pushkina 5"
+                    "example": "active"
+                },
+                "suspend_reason": {
+                    "type": "string",
+                    "example": "Спам в отзывах"
+                },
+                "suspended_at": {
+                    "type": "string",
+                    "example": "2025-11-01T09:30:00Z"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "verified_at": {
+                    "type": "string"
                 }
             }
         },
-        "addresses.AdminAddressesResponse": {
+        "products.Modifier": {
             "type": "object",
             "properties": {
-                "addresses": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/addresses.AddressResponse"
-                    }
-                },
-                "limit": {
+                "group_id": {
                     "type": "integer"
                 },
-                "page": {
-                    "type": "integer"
+                "name": {
+                    "type": "string",
+                    "example": "Двойной сыр"
                 },
-                "total": {
+                "price_delta": {
+                    "type": "integer",
+                    "example": 90
+                },
+                "removable": {
+                    "type": "boolean"
+                },
+                "sort_order": {
                     "type": "integer"
+                }
+            }
+        },
+        "products.ModifierGroup": {
+            "type": "object",
+            "properties": {
+                "max_select": {
+                    "type": "integer",
+                    "example": 3
                 },
-                "total_pages": {
+                "min_select": {
+                    "description": "Сколько модификаторов группы нужно выбрать: от MinSelect до MaxSelect\n(0 — без ограничения). Убираемые ингредиенты не считаются",
+                    "type": "integer",
+                    "example": 0
+                },
+                "modifiers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/products.Modifier"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Добавки"
+                },
+                "sort_order": {
                     "type": "integer"
                 }
             }
         },
-        "auth.LoginRequest": {
+        "products.ModifierGroupCreateRequest": {
             "type": "object",
             "required": [
-                "email",
-                "password"
+                "name"
             ],
             "properties": {
-                "email": {
-                    "type": "string",
-                    "example": "email@example.com"
+                "max_select": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 3
                 },
-                "password": {
+                "min_select": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 0
+                },
+                "modifiers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/products.ModifierRequest"
+                    }
+                },
+                "name": {
                     "type": "string",
-                    "example": "secret"
+                    "maxLength": 64,
+                    "example": "Добавки"
+                },
+                "sort_order": {
+                    "type": "integer",
+                    "example": 10
                 }
             }
         },
-        "auth.LoginResponse": {
+        "products.ModifierGroupUpdateRequest": {
             "type": "object",
             "properties": {
-                "token": {
+                "max_select": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "min_select": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "modifiers": {
+                    "description": "Заменяет набор модификаторов: отсутствующие в списке удаляются",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/products.ModifierRequest"
+                    }
+                },
+                "name": {
                     "type": "string",
-                    "example": "eyJhbGciOi..."
+                    "maxLength": 64,
+                    "minLength": 1
+                },
+                "sort_order": {
+                    "type": "integer"
                 }
             }
         },
-        "auth.RegisterRequest": {
+        "products.ModifierGroupsRequest": {
+            "type": "object",
+            "properties": {
+                "group_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2
+                    ]
+                }
+            }
+        },
+        "products.ModifierGroupsResponse": {
+            "type": "object",
+            "properties": {
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/products.ModifierGroup"
+                    }
+                }
+            }
+        },
+        "products.ModifierRequest": {
             "type": "object",
             "required": [
-                "email",
-                "name",
-                "password"
+                "name"
             ],
             "properties": {
-                "email": {
-                    "type": "string",
-                    "example": "email@example.com"
+                "id": {
+                    "type": "integer",
+                    "example": 7
                 },
                 "name": {
                     "type": "string",
-                    "example": "Ivan"
+                    "maxLength": 64,
+                    "example": "Двойной сыр"
                 },
-                "password": {
-                    "type": "string",
-                    "example": "secret"
+                "price_delta": {
+                    "type": "integer",
+                    "example": 90
+                },
+                "removable": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "sort_order": {
+                    "type": "integer",
+                    "example": 10
                 }
             }
         },
-        "auth.RegisterResponse": {
+        "products.PriceQuote": {
             "type": "object",
             "properties": {
-                "token": {
+                "base_price": {
+                    "description": "Цена продукта или варианта без модификаторов",
+                    "type": "integer",
+                    "example": 599
+                },
+                "modifiers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/products.QuoteModifier"
+                    }
+                },
+                "product": {
                     "type": "string",
-                    "example": "eyJhbGciOi..."
+                    "example": "margarita"
+                },
+                "quantity": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 1378
+                },
+                "unit_price": {
+                    "type": "integer",
+                    "example": 689
+                },
+                "variant_id": {
+                    "type": "integer",
+                    "example": 12
+                }
+            }
+        },
+        "products.PriceRequest": {
+            "type": "object",
+            "properties": {
+                "modifier_ids": {
+                    "description": "Выбранные модификаторы, в том числе убираемые ингредиенты",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        7,
+                        9
+                    ]
+                },
+                "quantity": {
+                    "description": "По умолчанию 1",
+                    "type": "integer",
+                    "maximum": 99,
+                    "minimum": 0,
+                    "example": 2
+                },
+                "variant_id": {
+                    "description": "Вариант продукта; по умолчанию — вариант по умолчанию",
+                    "type": "integer",
+                    "example": 12
                 }
             }
         },
         "products.Product": {
             "type": "object",
             "properties": {
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/categories.Category"
+                    }
+                },
+                "from_price": {
+                    "description": "Минимальная цена среди вариантов (или Price без вариантов) — «от 499 ₽».\nВычисляется при сохранении, по ней фильтрует и сортирует каталог",
+                    "type": "integer"
+                },
                 "image": {
                     "type": "string"
                 },
@@ -1159,6 +6596,7 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "price": {
+                    "description": "Цена продукта; при наличии вариантов — цена варианта по умолчанию",
                     "type": "integer"
                 },
                 "rating": {
@@ -1168,7 +6606,14 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "type": {
+                    "description": "Свободный тип блюда; для навигации используются Categories",
                     "type": "string"
+                },
+                "variants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/products.ProductVariant"
+                    }
                 }
             }
         },
@@ -1176,10 +6621,19 @@ const docTemplate = `{
             "type": "object",
             "required": [
                 "name",
-                "price",
                 "tags"
             ],
             "properties": {
+                "category_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        4
+                    ]
+                },
                 "image": {
                     "type": "string",
                     "example": "https://example.com/image.jpg"
@@ -1202,6 +6656,7 @@ const docTemplate = `{
                     "example": "Маргарита"
                 },
                 "price": {
+                    "description": "Обязательна без вариантов; с вариантами берётся у варианта по умолчанию",
                     "type": "integer",
                     "example": 499
                 },
@@ -1225,6 +6680,58 @@ const docTemplate = `{
                     "type": "string",
                     "maxLength": 64,
                     "example": "pizza"
+                },
+                "variants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/products.ProductVariantRequest"
+                    }
+                }
+            }
+        },
+        "products.ProductListResponse": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "prev_cursor": {
+                    "type": "string"
+                },
+                "products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/products.Product"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "products.ProductSearchResponse": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/products.SearchResult"
+                    }
+                },
+                "total": {
+                    "type": "integer"
                 }
             }
         },
@@ -1247,6 +6754,13 @@ const docTemplate = `{
                 "tags"
             ],
             "properties": {
+                "category_ids": {
+                    "description": "Заменяет список категорий продукта; пустой список отвязывает от всех",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
                 "image": {
                     "type": "string"
                 },
@@ -1277,6 +6791,264 @@ const docTemplate = `{
                 "type": {
                     "type": "string",
                     "maxLength": 64
+                },
+                "variants": {
+                    "description": "Заменяет набор вариантов: отсутствующие в списке удаляются",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/products.ProductVariantRequest"
+                    }
+                }
+            }
+        },
+        "products.ProductVariant": {
+            "type": "object",
+            "properties": {
+                "is_default": {
+                    "description": "Вариант по умолчанию — ровно один у продукта с вариантами (см. индекс на ProductID)",
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "30 см"
+                },
+                "price": {
+                    "type": "integer",
+                    "example": 599
+                },
+                "sku": {
+                    "description": "Артикул; пустой допускается, непустой уникален",
+                    "type": "string",
+                    "example": "PZ-MARG-30"
+                },
+                "sort_order": {
+                    "type": "integer"
+                },
+                "volume_ml": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "weight_g": {
+                    "type": "integer",
+                    "example": 650
+                }
+            }
+        },
+        "products.ProductVariantRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "price"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "is_default": {
+                    "description": "Если ни один вариант не отмечен, по умолчанию первый по sort_order",
+                    "type": "boolean",
+                    "example": true
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "30 см"
+                },
+                "price": {
+                    "type": "integer",
+                    "example": 599
+                },
+                "sku": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "PZ-MARG-30"
+                },
+                "sort_order": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "volume_ml": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 0
+                },
+                "weight_g": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 650
+                }
+            }
+        },
+        "products.QuoteModifier": {
+            "type": "object",
+            "properties": {
+                "group_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Двойной сыр"
+                },
+                "price_delta": {
+                    "type": "integer",
+                    "example": 90
+                },
+                "removed": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "products.SearchResult": {
+            "type": "object",
+            "properties": {
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/categories.Category"
+                    }
+                },
+                "from_price": {
+                    "description": "Минимальная цена среди вариантов (или Price без вариантов) — «от 499 ₽».\nВычисляется при сохранении, по ней фильтрует и сортирует каталог",
+                    "type": "integer"
+                },
+                "image": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "name_highlight": {
+                    "description": "Имя с подсвеченными совпадениями (\u003cmark\u003e)",
+                    "type": "string"
+                },
+                "price": {
+                    "description": "Цена продукта; при наличии вариантов — цена варианта по умолчанию",
+                    "type": "integer"
+                },
+                "rank": {
+                    "description": "Релевантность (ts_rank_cd), больше — выше",
+                    "type": "number"
+                },
+                "rating": {
+                    "type": "number"
+                },
+                "slug": {
+                    "type": "string"
+                },
+                "snippet": {
+                    "description": "Фрагмент типа, тегов и ингредиентов с подсвеченными совпадениями",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "Свободный тип блюда; для навигации используются Categories",
+                    "type": "string"
+                },
+                "variants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/products.ProductVariant"
+                    }
+                }
+            }
+        },
+        "products.SuggestResponse": {
+            "type": "object",
+            "properties": {
+                "suggestions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/products.Suggestion"
+                    }
+                }
+            }
+        },
+        "products.Suggestion": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Маргарита"
+                },
+                "score": {
+                    "description": "Похожесть на запрос (word_similarity) от 0 до 1",
+                    "type": "number",
+                    "example": 0.67
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "margarita"
+                }
+            }
+        },
+        "users.AdminUpdateUserRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "ivan@example.com"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 1,
+                    "example": "Ivan"
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "customer",
+                        "manager",
+                        "admin"
+                    ],
+                    "example": "manager"
+                }
+            }
+        },
+        "users.StatusChangeRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500,
+                    "example": "Спам в отзывах"
+                }
+            }
+        },
+        "users.UpdateProfileRequest": {
+            "type": "object",
+            "properties": {
+                "marketing_consent": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 1,
+                    "example": "Ivan"
+                },
+                "phone": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "example": "+7 900 123-45-67"
+                },
+                "preferred_language": {
+                    "type": "string",
+                    "enum": [
+                        "ru",
+                        "en"
+                    ],
+                    "example": "en"
                 }
             }
         },
@@ -1286,9 +7058,15 @@ const docTemplate = `{
                 "limit": {
                     "type": "integer"
                 },
+                "next_cursor": {
+                    "type": "string"
+                },
                 "page": {
                     "type": "integer"
                 },
+                "prev_cursor": {
+                    "type": "string"
+                },
                 "total": {
                     "type": "integer"
                 },
@@ -1310,17 +7088,57 @@ const docTemplate = `{
                     "type": "string",
                     "example": "2025-10-07T12:00:00Z"
                 },
+                "deleted_at": {
+                    "type": "string",
+                    "example": "2025-11-02T10:00:00Z"
+                },
                 "email": {
                     "type": "string",
                     "example": "john.doe@example.com"
                 },
+                "email_verified": {
+                    "type": "boolean",
+                    "example": true
+                },
                 "id": {
                     "type": "integer",
                     "example": 1
                 },
+                "marketing_consent": {
+                    "type": "boolean",
+                    "example": false
+                },
                 "name": {
                     "type": "string",
                     "example": "John Doe"
+                },
+                "phone": {
+                    "type": "string",
+                    "example": "+79001234567"
+                },
+                "phone_verified": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "preferred_language": {
+                    "type": "string",
+                    "example": "ru"
+                },
+                "role": {
+                    "type": "string",
+                    "example": "customer"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "active"
+                },
+                "suspend_reason": {
+                    "type": "string",
+                    "example": "Спам в отзывах"
+                },
+                "suspended_at": {
+                    "type": "string",
+                    "example": "2025-11-01T09:30:00Z"
                 }
             }
         }
@@ -4,9 +4,12 @@ import (
 	"bike/configs"
 	"bike/internal/users"
 	"bike/pkg/middleware"
+	"bike/pkg/paginate"
+	"bike/pkg/rbac"
 	"bike/pkg/req"
 	"bike/pkg/res"
 	"errors"
+	"math"
 	"net/http"
 	"strconv"
 )
@@ -16,26 +19,31 @@ type AddressHandlerDeps struct {
 	AddressService    *AddressService
 	UserRepository    *users.UserRepository
 	Config            *configs.Config
+	Auth              *middleware.AuthDeps
+	Cursors           *paginate.Codec
 }
 
 type AddressHandler struct {
 	AddressRepository *AddressRepository
 	service           *AddressService
+	cursors           *paginate.Codec
 }
 
 func NewAddressHandler(router *http.ServeMux, deps AddressHandlerDeps) {
 	handler := &AddressHandler{
 		AddressRepository: deps.AddressRepository,
 		service:           deps.AddressService,
+		cursors:           deps.Cursors,
 	}
 
 	// Защищённые маршруты — пользователь должен быть авторизован
-	router.Handle("POST /user/address", middleware.IsAuthenticated(handler.Create(), deps.Config))
-	router.Handle("GET /user/address", middleware.IsAuthenticated(handler.GetAllForUser(), deps.Config))
-	router.Handle("PATCH /user/address/{id}", middleware.IsAuthenticated(handler.Patch(), deps.Config))
-	router.Handle("DELETE /user/address/{id}", middleware.IsAuthenticated(handler.Delete(), deps.Config))
+	router.Handle("POST /user/address", middleware.IsAuthenticated(handler.Create(), deps.Auth))
+	router.Handle("GET /user/address", middleware.IsAuthenticated(handler.GetAllForUser(), deps.Auth))
+	router.Handle("PATCH /user/address/{id}", middleware.IsAuthenticated(handler.Patch(), deps.Auth))
+	router.Handle("DELETE /user/address/{id}", middleware.IsAuthenticated(handler.Delete(), deps.Auth))
 
-	router.HandleFunc("GET /user/adminaddress", handler.AdminListAll())
+	// Админский маршрут — нужно право addresses:read (у роли или API-ключа)
+	router.Handle("GET /user/adminaddress", middleware.IsAuthenticatedOrAPIKey(middleware.RequirePermission(handler.AdminListAll(), rbac.PermAddressesRead), deps.Auth))
 }
 
 // Create godoc
@@ -48,6 +56,7 @@ func NewAddressHandler(router *http.ServeMux, deps AddressHandlerDeps) {
 // @Success 201 {object} addresses.AddressResponse
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /user/address [post]
 func (handler *AddressHandler) Create() http.HandlerFunc {
@@ -57,9 +66,17 @@ func (handler *AddressHandler) Create() http.HandlerFunc {
 			return
 		}
 
-		email, _ := r.Context().Value(middleware.ContextEmailKey).(string)
-		created, err := handler.service.CreateAddress(r.Context(), email, *body)
+		principal, ok := middleware.FromContext(r.Context())
+		if !ok {
+			res.Json(w, map[string]string{"error": "unauthorized"}, http.StatusUnauthorized)
+			return
+		}
+		created, err := handler.service.CreateAddress(r.Context(), principal, *body)
 		if err != nil {
+			if errors.Is(err, ErrEmailNotVerified) {
+				res.Json(w, map[string]string{"error": "email not verified"}, http.StatusForbidden)
+				return
+			}
 			res.Json(w, map[string]string{"error": "failed to create Address"}, http.StatusInternalServerError)
 			return
 		}
@@ -79,8 +96,12 @@ func (handler *AddressHandler) Create() http.HandlerFunc {
 // @Router /user/address [get]
 func (handler *AddressHandler) GetAllForUser() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		email, _ := r.Context().Value(middleware.ContextEmailKey).(string)
-		list, err := handler.service.ListAddress(r.Context(), email)
+		principal, ok := middleware.FromContext(r.Context())
+		if !ok {
+			res.Json(w, map[string]string{"error": "unauthorized"}, http.StatusUnauthorized)
+			return
+		}
+		list, err := handler.service.ListAddress(r.Context(), principal)
 		if err != nil {
 			res.Json(w, map[string]string{"error": "failed to list Address"}, http.StatusInternalServerError)
 			return
@@ -127,8 +148,12 @@ func (handler *AddressHandler) Patch() http.HandlerFunc {
 			return
 		}
 
-		email, _ := r.Context().Value(middleware.ContextEmailKey).(string)
-		updated, err := handler.service.UpdateAddress(r.Context(), email, id, *body)
+		principal, ok := middleware.FromContext(r.Context())
+		if !ok {
+			res.Json(w, map[string]string{"error": "unauthorized"}, http.StatusUnauthorized)
+			return
+		}
+		updated, err := handler.service.UpdateAddress(r.Context(), principal, id, *body)
 		if err != nil {
 			if errors.Is(err, ErrAddressNotFound) {
 				res.Json(w, map[string]string{"error": "Address not found"}, http.StatusNotFound)
@@ -170,8 +195,12 @@ func (handler *AddressHandler) Delete() http.HandlerFunc {
 		}
 		id := uint(id64)
 
-		email, _ := r.Context().Value(middleware.ContextEmailKey).(string)
-		err = handler.service.DeleteAddress(r.Context(), email, id)
+		principal, ok := middleware.FromContext(r.Context())
+		if !ok {
+			res.Json(w, map[string]string{"error": "unauthorized"}, http.StatusUnauthorized)
+			return
+		}
+		err = handler.service.DeleteAddress(r.Context(), principal, id)
 		if err != nil {
 			if errors.Is(err, ErrAddressNotFound) {
 				res.Json(w, map[string]string{"error": "Address not found"}, http.StatusNotFound)
@@ -191,17 +220,22 @@ func (handler *AddressHandler) Delete() http.HandlerFunc {
 
 // AdminListAll godoc
 // @Summary Список адресов (админ)
-// @Description Возвращает список адресов c фильтрами (для админов)
+// @Description Возвращает список адресов c фильтрами (для админов).
+// @Description Если передан cursor (для первой страницы — пустой), пагинация по курсору: next_cursor/prev_cursor в ответе и заголовок Link
 // @Tags addresses,admin
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Page size" default(10)
+// @Param cursor query string false "Page cursor"
 // @Param user_id query int false "Filter by user id"
 // @Param city query string false "Filter by city"
 // @Param street query string false "Filter by street"
 // @Param phone query string false "Filter by phone"
 // @Param label query string false "Filter by label"
 // @Success 200 {object} addresses.AdminAddressesResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /user/adminaddress [get]
 func (handler *AddressHandler) AdminListAll() http.HandlerFunc {
@@ -234,6 +268,11 @@ func (handler *AddressHandler) AdminListAll() http.HandlerFunc {
 		phone := q.Get("phone")
 		label := q.Get("label")
 
+		if q.Has("cursor") {
+			handler.adminPage(w, r, userID, city, street, phone, label, limit)
+			return
+		}
+
 		items, total, totalPages, err := handler.service.ListAllAdmin(r.Context(), userID, city, street, phone, label, page, limit)
 		if err != nil {
 			res.Json(w, map[string]string{"error": "failed to list addresses"}, http.StatusInternalServerError)
@@ -255,3 +294,41 @@ func (handler *AddressHandler) AdminListAll() http.HandlerFunc {
 		res.Json(w, resp, http.StatusOK)
 	}
 }
+
+// adminPage отвечает страницей админского списка в режиме курсора.
+func (handler *AddressHandler) adminPage(w http.ResponseWriter, r *http.Request, userID uint, city, street, phone, label string, limit int) {
+	cursor, err := handler.cursors.Decode(r.URL.Query().Get("cursor"))
+	if err != nil {
+		res.Json(w, map[string]string{"error": err.Error()}, http.StatusBadRequest)
+		return
+	}
+	items, total, more, err := handler.service.ListPageAdmin(r.Context(), userID, city, street, phone, label, cursor, limit)
+	if err != nil {
+		if errors.Is(err, paginate.ErrInvalidCursor) {
+			res.Json(w, map[string]string{"error": err.Error()}, http.StatusBadRequest)
+			return
+		}
+		res.Json(w, map[string]string{"error": "failed to list addresses"}, http.StatusInternalServerError)
+		return
+	}
+
+	out := make([]AddressResponse, 0, len(items))
+	for _, a := range items {
+		out = append(out, ToResponse(&a))
+	}
+	var first, last *paginate.Cursor
+	if len(items) > 0 {
+		first, last = CursorFor(&items[0]), CursorFor(&items[len(items)-1])
+	}
+	next, prev := handler.cursors.Neighbours(cursor, more, first, last)
+	paginate.SetLinkHeader(w, r, next, prev)
+
+	res.Json(w, AdminAddressesResponse{
+		Addresses:  out,
+		Total:      total,
+		Limit:      limit,
+		TotalPages: int(math.Ceil(float64(total) / float64(limit))),
+		NextCursor: next,
+		PrevCursor: prev,
+	}, http.StatusOK)
+}
@@ -36,11 +36,14 @@ type AddressResponse struct {
 	CreatedAt string `json:"created_at"`
 }
 
-// AdminAddressesResponse — формат ответа для админа
+// AdminAddressesResponse — формат ответа для админа.
+// Курсоры заполняются только в режиме ?cursor=
 type AdminAddressesResponse struct {
 	Addresses  []AddressResponse `json:"addresses"`
 	Total      int64             `json:"total"`
 	Page       int               `json:"page"`
 	Limit      int               `json:"limit"`
 	TotalPages int               `json:"total_pages"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+	PrevCursor string            `json:"prev_cursor,omitempty"`
 }
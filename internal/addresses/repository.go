@@ -2,6 +2,7 @@ package addresses
 
 import (
 	"bike/pkg/db"
+	"bike/pkg/paginate"
 	"gorm.io/gorm"
 	"time"
 )
@@ -56,7 +57,27 @@ func (r *AddressRepository) DeleteByID(id uint) error {
 	return result.Error
 }
 
-func (r *AddressRepository) ListAll(userID uint, city, street, phone, label string, limit, offset int) (items []Address, total int64, err error) {
+// ListAllByUserID возвращает адреса пользователя, включая удалённые (soft delete).
+func (r *AddressRepository) ListAllByUserID(userID uint) ([]Address, error) {
+	var list []Address
+	result := r.database.DB.Unscoped().
+		Where("user_id = ?", userID).
+		Order("created_at desc").
+		Find(&list)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return list, nil
+}
+
+// HardDeleteByUserID безвозвратно удаляет все адреса пользователя.
+func (r *AddressRepository) HardDeleteByUserID(userID uint) (int64, error) {
+	result := r.database.DB.Unscoped().Where("user_id = ?", userID).Delete(&Address{})
+	return result.RowsAffected, result.Error
+}
+
+// adminFiltered применяет фильтры админского списка к запросу.
+func (r *AddressRepository) adminFiltered(userID uint, city, street, phone, label string) *gorm.DB {
 	dbq := r.database.DB.Model(&Address{})
 
 	if userID != 0 {
@@ -74,6 +95,11 @@ func (r *AddressRepository) ListAll(userID uint, city, street, phone, label stri
 	if label != "" {
 		dbq = dbq.Where("label = ?", label)
 	}
+	return dbq
+}
+
+func (r *AddressRepository) ListAll(userID uint, city, street, phone, label string, limit, offset int) (items []Address, total int64, err error) {
+	dbq := r.adminFiltered(userID, city, street, phone, label)
 
 	// count total
 	if err = dbq.Count(&total).Error; err != nil {
@@ -81,7 +107,7 @@ func (r *AddressRepository) ListAll(userID uint, city, street, phone, label stri
 	}
 
 	// fetch with pagination
-	result := dbq.Order("created_at desc").Limit(limit).Offset(offset).Find(&items)
+	result := dbq.Order(paginate.Order("created_at", true, false)).Limit(limit).Offset(offset).Find(&items)
 	if result.Error != nil {
 		// если нет записей — возвращаем пустой слайс
 		if result.Error == gorm.ErrRecordNotFound {
@@ -92,6 +118,34 @@ func (r *AddressRepository) ListAll(userID uint, city, street, phone, label stri
 	return items, total, nil
 }
 
+// ListAllAfter возвращает до limit адресов после курсора (nil — с начала), новые первыми.
+// more — есть ли ещё адреса в направлении движения.
+func (r *AddressRepository) ListAllAfter(userID uint, city, street, phone, label string, cur *paginate.Cursor, limit int) (items []Address, total int64, more bool, err error) {
+	dbq := r.adminFiltered(userID, city, street, phone, label)
+	if err = dbq.Count(&total).Error; err != nil {
+		return nil, 0, false, err
+	}
+
+	backward := cur != nil && cur.Backward
+	if cur != nil {
+		createdAt, err := time.Parse(time.RFC3339Nano, cur.Key)
+		if err != nil {
+			return nil, 0, false, paginate.ErrInvalidCursor
+		}
+		dbq = dbq.Where(paginate.Seek("created_at", true, backward), createdAt, cur.ID)
+	}
+	if err = dbq.Order(paginate.Order("created_at", true, backward)).Limit(limit + 1).Find(&items).Error; err != nil {
+		return nil, 0, false, err
+	}
+	items, more = paginate.Trim(items, limit, backward)
+	return items, total, more, nil
+}
+
+// CursorFor возвращает позицию адреса в админском списке.
+func CursorFor(a *Address) *paginate.Cursor {
+	return &paginate.Cursor{Key: a.CreatedAt.Format(time.RFC3339Nano), ID: a.ID}
+}
+
 // ToResponse helpers func
 func ToResponse(a *Address) AddressResponse {
 	created := ""
@@ -5,34 +5,46 @@ import (
 	"errors"
 	"math"
 
+	"bike/configs"
 	"bike/internal/users"
+	"bike/pkg/middleware"
+	"bike/pkg/paginate"
 )
 
 var (
-	ErrAddressNotFound = errors.New("address not found")
-	ErrForbidden       = errors.New("forbidden")
+	ErrAddressNotFound  = errors.New("address not found")
+	ErrForbidden        = errors.New("forbidden")
+	ErrEmailNotVerified = errors.New("email not verified")
 )
 
 type AddressService struct {
-	repo     *AddressRepository
-	userRepo *users.UserRepository
+	repo   *AddressRepository
+	users  *users.UserCache
+	config *configs.Config
 }
 
-func NewAddressService(repo *AddressRepository, userRepo *users.UserRepository) *AddressService {
+func NewAddressService(repo *AddressRepository, users *users.UserCache, config *configs.Config) *AddressService {
 	return &AddressService{
-		repo:     repo,
-		userRepo: userRepo,
+		repo:   repo,
+		users:  users,
+		config: config,
 	}
 }
 
-// CreateAddress создаёт адрес для пользователя, найденного по email.
-func (s *AddressService) CreateAddress(ctx context.Context, userEmail string, in AddressCreateRequest) (*Address, error) {
-	user, err := s.userRepo.FindByEmail(userEmail)
-	if err != nil {
-		return nil, err
+// CreateAddress создаёт адрес для текущего пользователя.
+// Если включено REQUIRE_VERIFIED_EMAIL, email пользователя должен быть подтверждён.
+func (s *AddressService) CreateAddress(ctx context.Context, principal *middleware.Principal, in AddressCreateRequest) (*Address, error) {
+	if s.config.Auth.RequireVerifiedEmail {
+		user, err := s.users.Get(principal.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if !user.IsVerified() {
+			return nil, ErrEmailNotVerified
+		}
 	}
 	a := &Address{
-		UserID:    user.ID,
+		UserID:    principal.UserID,
 		Label:     in.Label,
 		Apartment: in.Apartment,
 		Floor:     in.Floor,
@@ -45,26 +57,18 @@ func (s *AddressService) CreateAddress(ctx context.Context, userEmail string, in
 	return s.repo.Create(a)
 }
 
-// ListAddress возвращает все адреса конкретного пользователя.
-func (s *AddressService) ListAddress(ctx context.Context, userEmail string) ([]Address, error) {
-	user, err := s.userRepo.FindByEmail(userEmail)
-	if err != nil {
-		return nil, err
-	}
-	return s.repo.ListByUserID(user.ID)
+// ListAddress возвращает все адреса текущего пользователя.
+func (s *AddressService) ListAddress(ctx context.Context, principal *middleware.Principal) ([]Address, error) {
+	return s.repo.ListByUserID(principal.UserID)
 }
 
 // UpdateAddress обновляет поля адреса, только если он принадлежит пользователю.
-func (s *AddressService) UpdateAddress(ctx context.Context, userEmail string, id uint, in AddressUpdateRequest) (*Address, error) {
-	user, err := s.userRepo.FindByEmail(userEmail)
-	if err != nil {
-		return nil, err
-	}
+func (s *AddressService) UpdateAddress(ctx context.Context, principal *middleware.Principal, id uint, in AddressUpdateRequest) (*Address, error) {
 	addr, err := s.repo.FindByID(id)
 	if err != nil {
 		return nil, ErrAddressNotFound
 	}
-	if addr.UserID != user.ID {
+	if addr.UserID != principal.UserID {
 		return nil, ErrForbidden
 	}
 
@@ -98,16 +102,12 @@ func (s *AddressService) UpdateAddress(ctx context.Context, userEmail string, id
 }
 
 // DeleteAddress удаляет адрес только если он принадлежит пользователю.
-func (s *AddressService) DeleteAddress(ctx context.Context, userEmail string, id uint) error {
-	user, err := s.userRepo.FindByEmail(userEmail)
-	if err != nil {
-		return err
-	}
+func (s *AddressService) DeleteAddress(ctx context.Context, principal *middleware.Principal, id uint) error {
 	addr, err := s.repo.FindByID(id)
 	if err != nil {
 		return ErrAddressNotFound
 	}
-	if addr.UserID != user.ID {
+	if addr.UserID != principal.UserID {
 		return ErrForbidden
 	}
 	return s.repo.DeleteByID(id)
@@ -133,3 +133,15 @@ func (s *AddressService) ListAllAdmin(ctx context.Context, userID uint, city, st
 	}
 	return items, total, totalPages, nil
 }
+
+// ListPageAdmin — страница админского списка по курсору. Курсор выдаётся
+// без режима сортировки: список всегда упорядочен по дате создания.
+func (s *AddressService) ListPageAdmin(ctx context.Context, userID uint, city, street, phone, label string, cursor *paginate.Cursor, limit int) (items []Address, total int64, more bool, err error) {
+	if cursor != nil && cursor.Sort != "" {
+		return nil, 0, false, paginate.ErrInvalidCursor
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+	return s.repo.ListAllAfter(userID, city, street, phone, label, cursor, limit)
+}
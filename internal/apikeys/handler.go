@@ -0,0 +1,124 @@
+package apikeys
+
+import (
+	"bike/pkg/middleware"
+	"bike/pkg/rbac"
+	"bike/pkg/req"
+	"bike/pkg/res"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+type APIKeyHandlerDeps struct {
+	APIKeyService *APIKeyService
+	Auth          *middleware.AuthDeps
+}
+
+type APIKeyHandler struct {
+	service *APIKeyService
+}
+
+func NewAPIKeyHandler(router *http.ServeMux, deps APIKeyHandlerDeps) {
+	handler := &APIKeyHandler{service: deps.APIKeyService}
+
+	// Управлять ключами может только администратор, и только по JWT
+	router.Handle("POST /apikeys", middleware.IsAuthenticated(middleware.RequirePermission(handler.Create(), rbac.PermAPIKeysManage), deps.Auth))
+	router.Handle("GET /apikeys", middleware.IsAuthenticated(middleware.RequirePermission(handler.List(), rbac.PermAPIKeysManage), deps.Auth))
+	router.Handle("DELETE /apikeys/{id}", middleware.IsAuthenticated(middleware.RequirePermission(handler.Revoke(), rbac.PermAPIKeysManage), deps.Auth))
+}
+
+// Create godoc
+// @Summary Выпустить API-ключ (админ)
+// @Description Создаёт ключ для межсервисного доступа. Ключ возвращается только в этом ответе, передаётся в заголовке X-API-Key. Доступные права: products:write, users:read, addresses:read
+// @Tags apikeys,admin
+// @Accept json
+// @Produce json
+// @Param request body apikeys.CreateAPIKeyRequest true "Название, права и срок действия"
+// @Success 201 {object} apikeys.CreateAPIKeyResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /apikeys [post]
+func (handler *APIKeyHandler) Create() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := req.HandleBody[CreateAPIKeyRequest](&w, r)
+		if err != nil {
+			return
+		}
+		principal, ok := middleware.FromContext(r.Context())
+		if !ok {
+			res.Json(w, map[string]string{"error": "unauthorized"}, http.StatusUnauthorized)
+			return
+		}
+		key, raw, err := handler.service.Create(r.Context(), principal.UserID, *body)
+		if err != nil {
+			if errors.Is(err, ErrUnknownScope) || errors.Is(err, ErrExpiresInPast) {
+				res.Json(w, map[string]string{"error": err.Error()}, http.StatusBadRequest)
+				return
+			}
+			res.Json(w, map[string]string{"error": "failed to create api key"}, http.StatusInternalServerError)
+			return
+		}
+		res.Json(w, CreateAPIKeyResponse{APIKeyResponse: ToResponse(key), Key: raw}, http.StatusCreated)
+	}
+}
+
+// List godoc
+// @Summary Список API-ключей (админ)
+// @Description Возвращает все ключи без секретной части, с датой последнего использования
+// @Tags apikeys,admin
+// @Produce json
+// @Success 200 {array} apikeys.APIKeyResponse
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /apikeys [get]
+func (handler *APIKeyHandler) List() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		list, err := handler.service.List(r.Context())
+		if err != nil {
+			res.Json(w, map[string]string{"error": "failed to list api keys"}, http.StatusInternalServerError)
+			return
+		}
+		out := make([]APIKeyResponse, 0, len(list))
+		for i := range list {
+			out = append(out, ToResponse(&list[i]))
+		}
+		res.Json(w, out, http.StatusOK)
+	}
+}
+
+// Revoke godoc
+// @Summary Отозвать API-ключ (админ)
+// @Description Ключ перестаёт приниматься сразу после отзыва
+// @Tags apikeys,admin
+// @Produce json
+// @Param id path int true "ID ключа"
+// @Success 200 {object} apikeys.APIKeyResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /apikeys/{id} [delete]
+func (handler *APIKeyHandler) Revoke() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+		if err != nil {
+			res.Json(w, map[string]string{"error": "invalid id"}, http.StatusBadRequest)
+			return
+		}
+		key, err := handler.service.Revoke(r.Context(), uint(id))
+		if err != nil {
+			if errors.Is(err, ErrKeyNotFound) {
+				res.Json(w, map[string]string{"error": "api key not found"}, http.StatusNotFound)
+				return
+			}
+			res.Json(w, map[string]string{"error": "failed to revoke api key"}, http.StatusInternalServerError)
+			return
+		}
+		res.Json(w, ToResponse(key), http.StatusOK)
+	}
+}
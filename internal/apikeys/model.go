@@ -0,0 +1,29 @@
+package apikeys
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// APIKey — ключ для межсервисного доступа. Сам ключ не хранится,
+// только его sha256-хэш и короткий префикс для отображения.
+type APIKey struct {
+	gorm.Model
+	Name        string         `gorm:"size:128;not null"`
+	Prefix      string         `gorm:"size:32;not null"`
+	KeyHash     string         `gorm:"size:64;uniqueIndex;not null"`
+	Scopes      pq.StringArray `gorm:"type:text[]"`
+	CreatedByID uint           `gorm:"index"`
+	ExpiresAt   *time.Time
+	LastUsedAt  *time.Time
+	RevokedAt   *time.Time
+}
+
+func (k *APIKey) Active(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
@@ -0,0 +1,52 @@
+package apikeys
+
+import "time"
+
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" validate:"required,max=128" example:"catalog-sync"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1" example:"products:write"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" example:"2026-01-01T00:00:00Z"`
+}
+
+type APIKeyResponse struct {
+	ID         uint     `json:"id"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix" example:"bk_live_AbCd"`
+	Scopes     []string `json:"scopes"`
+	CreatedBy  uint     `json:"created_by"`
+	CreatedAt  string   `json:"created_at"`
+	ExpiresAt  string   `json:"expires_at,omitempty"`
+	LastUsedAt string   `json:"last_used_at,omitempty"`
+	RevokedAt  string   `json:"revoked_at,omitempty"`
+}
+
+// CreateAPIKeyResponse содержит сам ключ — он показывается только один раз.
+type CreateAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key" example:"bk_live_AbCdEf..."`
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func ToResponse(k *APIKey) APIKeyResponse {
+	scopes := []string(k.Scopes)
+	if scopes == nil {
+		scopes = []string{}
+	}
+	return APIKeyResponse{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     scopes,
+		CreatedBy:  k.CreatedByID,
+		CreatedAt:  k.CreatedAt.Format(time.RFC3339),
+		ExpiresAt:  formatTime(k.ExpiresAt),
+		LastUsedAt: formatTime(k.LastUsedAt),
+		RevokedAt:  formatTime(k.RevokedAt),
+	}
+}
@@ -0,0 +1,62 @@
+package apikeys
+
+import (
+	"bike/pkg/db"
+	"time"
+)
+
+type APIKeyRepository struct {
+	database *db.Db
+}
+
+func NewAPIKeyRepository(database *db.Db) *APIKeyRepository {
+	return &APIKeyRepository{database: database}
+}
+
+func (r *APIKeyRepository) Create(key *APIKey) (*APIKey, error) {
+	result := r.database.DB.Create(key)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return key, nil
+}
+
+func (r *APIKeyRepository) FindByHash(hash string) (*APIKey, error) {
+	var key APIKey
+	result := r.database.DB.Where("key_hash = ?", hash).First(&key)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &key, nil
+}
+
+func (r *APIKeyRepository) FindByID(id uint) (*APIKey, error) {
+	var key APIKey
+	result := r.database.DB.First(&key, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &key, nil
+}
+
+func (r *APIKeyRepository) List() ([]APIKey, error) {
+	var list []APIKey
+	result := r.database.DB.Order("created_at desc").Find(&list)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return list, nil
+}
+
+// Revoke отзывает ключ; повторный отзыв не меняет исходную дату.
+func (r *APIKeyRepository) Revoke(id uint, at time.Time) error {
+	return r.database.DB.Model(&APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at).Error
+}
+
+func (r *APIKeyRepository) TouchLastUsed(id uint, at time.Time) error {
+	return r.database.DB.Model(&APIKey{}).
+		Where("id = ?", id).
+		UpdateColumn("last_used_at", at).Error
+}
@@ -0,0 +1,156 @@
+package apikeys
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"bike/internal/users"
+	"bike/pkg/middleware"
+	"bike/pkg/rbac"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+const (
+	KeyPrefix = "bk_live_"
+	// Сколько символов ключа (вместе с KeyPrefix) показывать в списке
+	displayPrefixLen = len(KeyPrefix) + 6
+	// Не чаще, чем раз в этот интервал, обновляем last_used_at одного ключа
+	lastUsedResolution = time.Minute
+)
+
+var (
+	ErrInvalidKey    = errors.New("invalid api key")
+	ErrKeyNotFound   = errors.New("api key not found")
+	ErrUnknownScope  = errors.New("unknown scope")
+	ErrExpiresInPast = errors.New("expires_at is in the past")
+)
+
+type APIKeyService struct {
+	repo     *APIKeyRepository
+	userRepo *users.UserRepository
+
+	mu       sync.Mutex
+	lastUsed map[uint]time.Time
+}
+
+func NewAPIKeyService(repo *APIKeyRepository, userRepo *users.UserRepository) *APIKeyService {
+	return &APIKeyService{
+		repo:     repo,
+		userRepo: userRepo,
+		lastUsed: make(map[uint]time.Time),
+	}
+}
+
+// Create выпускает новый ключ. Возвращает запись и сам ключ —
+// после ответа его уже нельзя получить повторно.
+func (s *APIKeyService) Create(ctx context.Context, creatorID uint, in CreateAPIKeyRequest) (*APIKey, string, error) {
+	scopes := make(pq.StringArray, 0, len(in.Scopes))
+	for _, scope := range in.Scopes {
+		if !rbac.Permission(scope).IsAPIKeyScope() {
+			return nil, "", ErrUnknownScope
+		}
+		scopes = append(scopes, scope)
+	}
+	if in.ExpiresAt != nil && !in.ExpiresAt.After(time.Now()) {
+		return nil, "", ErrExpiresInPast
+	}
+	creator, err := s.userRepo.FindByID(creatorID)
+	if err != nil {
+		return nil, "", err
+	}
+	raw, err := newKey()
+	if err != nil {
+		return nil, "", err
+	}
+	key, err := s.repo.Create(&APIKey{
+		Name:        in.Name,
+		Prefix:      raw[:displayPrefixLen],
+		KeyHash:     hashKey(raw),
+		Scopes:      scopes,
+		CreatedByID: creator.ID,
+		ExpiresAt:   in.ExpiresAt,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return key, raw, nil
+}
+
+func (s *APIKeyService) List(ctx context.Context) ([]APIKey, error) {
+	return s.repo.List()
+}
+
+func (s *APIKeyService) Revoke(ctx context.Context, id uint) (*APIKey, error) {
+	if _, err := s.repo.FindByID(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrKeyNotFound
+		}
+		return nil, err
+	}
+	if err := s.repo.Revoke(id, time.Now()); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByID(id)
+}
+
+// VerifyAPIKey реализует middleware.APIKeyVerifier.
+func (s *APIKeyService) VerifyAPIKey(ctx context.Context, raw string) (*middleware.APIKeyInfo, error) {
+	if !strings.HasPrefix(raw, KeyPrefix) {
+		return nil, ErrInvalidKey
+	}
+	key, err := s.repo.FindByHash(hashKey(raw))
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+	now := time.Now()
+	if !key.Active(now) {
+		return nil, ErrInvalidKey
+	}
+	s.touch(key.ID, now)
+
+	scopes := make([]rbac.Permission, 0, len(key.Scopes))
+	for _, scope := range key.Scopes {
+		scopes = append(scopes, rbac.Permission(scope))
+	}
+	return &middleware.APIKeyInfo{ID: key.ID, Name: key.Name, Scopes: scopes}, nil
+}
+
+// touch обновляет last_used_at в фоне, не чаще раза в lastUsedResolution.
+func (s *APIKeyService) touch(id uint, now time.Time) {
+	s.mu.Lock()
+	if last, ok := s.lastUsed[id]; ok && now.Sub(last) < lastUsedResolution {
+		s.mu.Unlock()
+		return
+	}
+	s.lastUsed[id] = now
+	s.mu.Unlock()
+
+	go func() {
+		if err := s.repo.TouchLastUsed(id, now); err != nil {
+			log.Printf("apikeys: update last_used_at for key %d: %v", id, err)
+		}
+	}()
+}
+
+func newKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return KeyPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"bike/internal/users"
+	"bike/pkg/mailer"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ChangePassword меняет пароль текущего пользователя, завершает все его сессии
+// и выдаёт новую пару токенов для текущего устройства.
+func (service *AuthService) ChangePassword(ctx context.Context, userID uint, currentPassword, newPassword string, client ClientInfo) (*TokenPair, error) {
+	user, err := service.UserRepository.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.HasPassword() || bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(currentPassword)) != nil {
+		return nil, errors.New(ErrWrongCredentials)
+	}
+	if err := service.setPassword(user, newPassword); err != nil {
+		return nil, err
+	}
+	if err := service.revokeAllSessions(ctx, user); err != nil {
+		return nil, err
+	}
+	return service.IssueTokens(ctx, user, client)
+}
+
+// RequestEmailChange отправляет на новый адрес письмо с подтверждением.
+// User.Email меняется только после перехода по ссылке (VerifyEmail).
+func (service *AuthService) RequestEmailChange(ctx context.Context, userID uint, currentPassword, newEmail string) error {
+	user, err := service.UserRepository.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if !user.HasPassword() || bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(currentPassword)) != nil {
+		return errors.New(ErrWrongCredentials)
+	}
+	newEmail = strings.TrimSpace(newEmail)
+	if strings.EqualFold(newEmail, user.Email) {
+		return errors.New(ErrSameEmail)
+	}
+	if existed, _ := service.UserRepository.FindByEmail(newEmail); existed != nil {
+		return errors.New(ErrUserAlreadyExists)
+	}
+
+	token, err := service.newUserToken(ctx, user.ID, PurposeEmailChange, newEmail, service.config.Auth.EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+	link := fmt.Sprintf("%s/auth/verify?token=%s", service.config.App.BaseURL, url.QueryEscape(token))
+	go service.sendMail(mailer.Message{
+		To:      newEmail,
+		Subject: "Подтверждение нового email",
+		Body: fmt.Sprintf("Здравствуйте, %s!\n\nЧтобы сменить email аккаунта на этот адрес, перейдите по ссылке:\n%s\n\nСсылка действует %s. Если вы не запрашивали смену email, просто проигнорируйте это письмо.\n",
+			user.Name, link, service.config.Auth.EmailVerificationTTL),
+	})
+	return nil
+}
+
+// confirmEmailChange применяет смену email по использованному токену.
+func (service *AuthService) confirmEmailChange(ctx context.Context, t *UserToken) (*users.User, error) {
+	user, err := service.UserRepository.FindByID(t.UserID)
+	if err != nil {
+		return nil, err
+	}
+	// Адрес могли занять, пока письмо шло
+	if existed, _ := service.UserRepository.FindByEmail(t.Payload); existed != nil {
+		return nil, errors.New(ErrUserAlreadyExists)
+	}
+
+	oldEmail := user.Email
+	now := time.Now()
+	user.Email = t.Payload
+	user.VerifiedAt = &now
+	if _, err := service.UserRepository.Update(user); err != nil {
+		return nil, err
+	}
+	// Access-токены содержат старый email — отзываем их, refresh-токены выдадут новые
+	if err := service.RevocationStore.RevokeSubject(ctx, user.ID, service.config.Auth.AccessTTL); err != nil {
+		return nil, err
+	}
+
+	go service.sendMail(mailer.Message{
+		To:      oldEmail,
+		Subject: "Email аккаунта изменён",
+		Body: fmt.Sprintf("Здравствуйте, %s!\n\nEmail вашего аккаунта изменён на %s. Если это были не вы, срочно сбросьте пароль и свяжитесь с поддержкой.\n",
+			user.Name, user.Email),
+	})
+	return user, nil
+}
+
+func (service *AuthService) setPassword(user *users.User, password string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	user.Password = string(hashedPassword)
+	_, err = service.UserRepository.Update(user)
+	return err
+}
+
+// RevokeUserSessions отзывает все токены и сессии пользователя — например,
+// при блокировке или смене роли администратором.
+func (service *AuthService) RevokeUserSessions(ctx context.Context, user *users.User) error {
+	return service.revokeAllSessions(ctx, user)
+}
+
+// revokeAllSessions отзывает все refresh-токены пользователя и выданные до этого момента access-токены.
+func (service *AuthService) revokeAllSessions(ctx context.Context, user *users.User) error {
+	if err := service.RefreshTokenRepository.RevokeAllForUser(ctx, user.ID); err != nil {
+		return err
+	}
+	if err := service.SessionRepository.RevokeAllForUser(ctx, user.ID); err != nil {
+		return err
+	}
+	return service.RevocationStore.RevokeSubject(ctx, user.ID, service.config.Auth.AccessTTL)
+}
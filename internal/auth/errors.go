@@ -1,6 +1,25 @@
 package auth
 
 const (
-	ErrUserAlreadyExists = "User already exists"
-	ErrWrongCredentials  = "Wrong credentials"
+	ErrUserAlreadyExists   = "User already exists"
+	ErrWrongCredentials    = "Wrong credentials"
+	ErrInvalidRefreshToken = "Invalid refresh token"
+	ErrInvalidToken        = "Invalid or expired token"
+	ErrAlreadyVerified     = "Email already verified"
+	ErrSameEmail           = "New email matches the current one"
+	ErrTwoFactorEnabled    = "Two-factor authentication already enabled"
+	ErrTwoFactorNotSetUp   = "Two-factor authentication is not set up"
+	ErrInvalidTwoFactor    = "Invalid two-factor code"
+	ErrUnknownProvider     = "Unknown identity provider"
+	ErrInvalidState        = "Invalid or expired state"
+	ErrProviderLogin       = "Identity provider login failed"
+	ErrProviderNoEmail     = "Identity provider did not return an email"
+	ErrProviderUnverified  = "Email is not verified by identity provider"
+	ErrSessionNotFound     = "Session not found"
+	ErrInvalidPhone        = "Invalid phone number"
+	ErrPhoneTaken          = "Phone number already in use"
+	ErrInvalidOTP          = "Invalid or expired code"
+	ErrPhoneNotSet         = "Phone number is not set"
+	ErrPhoneVerified       = "Phone number already verified"
+	ErrUserSuspended       = "Account suspended"
 )
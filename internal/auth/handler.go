@@ -2,15 +2,23 @@ package auth
 
 import (
 	"bike/configs"
+	"bike/internal/users"
 	"bike/pkg/jwt"
+	"bike/pkg/middleware"
+	"bike/pkg/rbac"
 	"bike/pkg/req"
 	"bike/pkg/res"
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 type AuthHandlerDeps struct {
 	*configs.Config
 	*AuthService
+	Auth *middleware.AuthDeps
 }
 
 type AuthHandler struct {
@@ -25,17 +33,56 @@ func NewAuthHandler(router *http.ServeMux, deps AuthHandlerDeps) {
 	}
 	router.HandleFunc("POST /auth/login", handler.Login())
 	router.HandleFunc("POST /auth/register", handler.Register())
+	router.HandleFunc("POST /auth/refresh", handler.Refresh())
+	router.Handle("POST /auth/logout", middleware.IsAuthenticated(handler.Logout(), deps.Auth))
+	router.Handle("POST /auth/logout-all", middleware.IsAuthenticated(middleware.ForbidImpersonation(handler.LogoutAll()), deps.Auth))
+	router.HandleFunc("GET /.well-known/jwks.json", handler.JWKS())
+	router.HandleFunc("POST /auth/password/forgot", handler.ForgotPassword())
+	router.HandleFunc("POST /auth/password/reset", handler.ResetPassword())
+	router.HandleFunc("GET /auth/verify", handler.VerifyEmail())
+	router.Handle("POST /auth/verify/resend", middleware.IsAuthenticated(handler.ResendVerification(), deps.Auth))
+
+	// Двухфакторная аутентификация (TOTP)
+	router.Handle("POST /auth/2fa/setup", middleware.IsAuthenticated(middleware.ForbidImpersonation(handler.TwoFactorSetup()), deps.Auth))
+	router.Handle("POST /auth/2fa/confirm", middleware.IsAuthenticated(middleware.ForbidImpersonation(handler.TwoFactorConfirm()), deps.Auth))
+	router.HandleFunc("POST /auth/2fa/verify", handler.TwoFactorVerify())
+
+	// Вход через внешних провайдеров (OpenID Connect)
+	router.HandleFunc("GET /auth/oidc/providers", handler.OIDCProviders())
+	router.HandleFunc("POST /auth/oidc/{provider}/start", handler.OIDCStart())
+	router.HandleFunc("POST /auth/oidc/{provider}/callback", handler.OIDCCallback())
+
+	// Вход по одноразовому коду из SMS
+	router.HandleFunc("POST /auth/otp/request", handler.RequestOTP())
+	router.HandleFunc("POST /auth/otp/verify", handler.VerifyOTP())
+
+	// Сессии (устройства) текущего пользователя
+	router.Handle("GET /auth/sessions", middleware.IsAuthenticated(handler.ListSessions(), deps.Auth))
+	router.Handle("DELETE /auth/sessions/{id}", middleware.IsAuthenticated(middleware.ForbidImpersonation(handler.RevokeSession()), deps.Auth))
+
+	// Смена учётных данных текущего пользователя
+	router.Handle("POST /users/me/password", middleware.IsAuthenticated(middleware.ForbidImpersonation(handler.ChangePassword()), deps.Auth))
+	router.Handle("POST /users/me/email", middleware.IsAuthenticated(middleware.ForbidImpersonation(handler.ChangeEmail()), deps.Auth))
+	router.Handle("POST /users/me/phone/verify", middleware.IsAuthenticated(middleware.ForbidImpersonation(handler.RequestPhoneVerification()), deps.Auth))
+	router.Handle("POST /users/me/phone/confirm", middleware.IsAuthenticated(middleware.ForbidImpersonation(handler.ConfirmPhone()), deps.Auth))
+
+	// Админские маршруты
+	router.Handle("POST /auth/unlock", middleware.IsAuthenticated(middleware.RequirePermission(handler.Unlock(), rbac.PermUsersWrite), deps.Auth))
 }
 
 // Login godoc
 // @Summary Авторизация пользователя
-// @Description Авторизация пользователя по email и паролю
+// @Description Авторизация пользователя по email и паролю. Возвращает короткоживущий access-токен и refresh-токен.
+// @Description После нескольких неудачных попыток вход временно блокируется (429 с заголовком Retry-After)
+// @Description Если у пользователя включена 2FA, возвращается 202 с токеном второго шага; токены выдаёт POST /auth/2fa/verify
 // @Tags auth,open,user
 // @Accept json
 // @Produce json
 // @Param request body auth.LoginRequest true "Данные для авторизации"
 // @Success 200 {object} auth.LoginResponse
+// @Success 202 {object} auth.TwoFactorChallengeResponse
 // @Failure 401 {object} map[string]string
+// @Failure 429 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /auth/login [post]
 func (handler *AuthHandler) Login() http.HandlerFunc {
@@ -44,34 +91,66 @@ func (handler *AuthHandler) Login() http.HandlerFunc {
 		if err != nil {
 			return
 		}
-		email, err := handler.AuthService.Login(body.Email, body.Password)
+		user, err := handler.AuthService.Login(r.Context(), body.Email, body.Password, req.ClientIP(r))
 		if err != nil {
+			var tooMany *TooManyAttemptsError
+			if errors.As(err, &tooMany) {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(tooMany.RetryAfter.Seconds()))))
+				http.Error(w, err.Error(), http.StatusTooManyRequests)
+				return
+			}
+			if err.Error() == ErrUserSuspended {
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			}
 			http.Error(w, err.Error(), http.StatusUnauthorized)
 			return
 		}
-		token, err := jwt.NewJWT(handler.Config.Auth.Secret).GenerateToken(jwt.JWTData{
-			Email: email,
-		})
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+		handler.completeLogin(w, r, user, clientInfo(r, body.DeviceName))
+	}
+}
+
+// completeLogin отвечает на успешную проверку первого фактора: токеном
+// второго шага, если у пользователя включена 2FA, иначе парой токенов.
+func (handler *AuthHandler) completeLogin(w http.ResponseWriter, r *http.Request, user *users.User, client ClientInfo) {
+	challenge, err := handler.AuthService.StartTwoFactor(r.Context(), user)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if challenge != nil {
+		res.Json(w, TwoFactorChallengeResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    challenge.Token,
+			ExpiresAt:         challenge.ExpiresAt.UTC().Format(time.RFC3339),
+		}, http.StatusAccepted)
+		return
+	}
+	tokens, err := handler.AuthService.IssueTokens(r.Context(), user, client)
+	if err != nil {
+		if err.Error() == ErrUserSuspended {
+			http.Error(w, err.Error(), http.StatusForbidden)
 			return
 		}
-		data := LoginResponse{
-			Token: token,
-		}
-		res.Json(w, data, 200)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
+	data := LoginResponse{
+		TokenResponse: toTokenResponse(tokens),
+	}
+	res.Json(w, data, 200)
 }
 
 // Register godoc
 // @Summary Регистрация пользователя
-// @Description Создаёт нового пользователя и возвращает JWT
+// @Description Создаёт нового пользователя, отправляет письмо для подтверждения email и возвращает пару токенов
 // @Tags auth,open,user
 // @Accept json
 // @Produce json
 // @Param request body auth.RegisterRequest true "Данные регистрации"
 // @Success 200 {object} auth.RegisterResponse
 // @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /auth/register [post]
 func (handler *AuthHandler) Register() http.HandlerFunc {
@@ -80,21 +159,758 @@ func (handler *AuthHandler) Register() http.HandlerFunc {
 		if err != nil {
 			return
 		}
-		email, err := handler.AuthService.Register(body.Email, body.Password, body.Name)
+		user, err := handler.AuthService.Register(r.Context(), body.Email, body.Password, body.Name, body.Phone)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusUnauthorized)
+			switch err.Error() {
+			case ErrInvalidPhone:
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			case ErrPhoneTaken:
+				http.Error(w, err.Error(), http.StatusConflict)
+			default:
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+			}
 			return
 		}
-		token, err := jwt.NewJWT(handler.Config.Auth.Secret).GenerateToken(jwt.JWTData{
-			Email: email,
-		})
+		tokens, err := handler.AuthService.IssueTokens(r.Context(), user, clientInfo(r, body.DeviceName))
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 		data := RegisterResponse{
-			Token: token,
+			TokenResponse: toTokenResponse(tokens),
+		}
+		res.Json(w, data, 200)
+	}
+}
+
+// Refresh godoc
+// @Summary Обновление токенов
+// @Description Обменивает refresh-токен на новую пару токенов. Старый refresh-токен становится недействительным; его повторное использование отзывает все токены этой сессии
+// @Tags auth,open,user
+// @Accept json
+// @Produce json
+// @Param request body auth.RefreshRequest true "Refresh-токен"
+// @Success 200 {object} auth.RefreshResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/refresh [post]
+func (handler *AuthHandler) Refresh() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := req.HandleBody[RefreshRequest](&w, r)
+		if err != nil {
+			return
+		}
+		tokens, err := handler.AuthService.Refresh(r.Context(), body.RefreshToken)
+		if err != nil {
+			if err.Error() == ErrInvalidRefreshToken {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+			if err.Error() == ErrUserSuspended {
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		data := RefreshResponse{
+			TokenResponse: toTokenResponse(tokens),
 		}
 		res.Json(w, data, 200)
 	}
 }
+
+// Logout godoc
+// @Summary Выход из текущей сессии
+// @Description Отзывает текущий access-токен. Если передан refresh-токен — отзывает и его
+// @Tags auth,jwt,user
+// @Accept json
+// @Param request body auth.LogoutRequest false "Refresh-токен текущей сессии"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/logout [post]
+func (handler *AuthHandler) Logout() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body LogoutRequest
+		// Тело необязательное
+		if r.ContentLength != 0 {
+			b, err := req.HandleBody[LogoutRequest](&w, r)
+			if err != nil {
+				return
+			}
+			body = *b
+		}
+		data, _ := r.Context().Value(middleware.ContextTokenKey).(*jwt.JWTData)
+		if err := handler.AuthService.Logout(r.Context(), data, body.RefreshToken); err != nil {
+			res.Json(w, map[string]string{"error": "failed to logout"}, http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// LogoutAll godoc
+// @Summary Выход со всех устройств
+// @Description Отзывает все access- и refresh-токены текущего пользователя
+// @Tags auth,jwt,user
+// @Success 204
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/logout-all [post]
+func (handler *AuthHandler) LogoutAll() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		data, _ := r.Context().Value(middleware.ContextTokenKey).(*jwt.JWTData)
+		if err := handler.AuthService.LogoutAll(r.Context(), data); err != nil {
+			res.Json(w, map[string]string{"error": "failed to logout"}, http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// JWKS godoc
+// @Summary Публичные ключи подписи JWT
+// @Description JWKS (RFC 7517) с публичными ключами, которыми можно проверить наши токены без общего секрета
+// @Tags auth,open
+// @Produce json
+// @Success 200 {object} jwt.JWKS
+// @Router /.well-known/jwks.json [get]
+func (handler *AuthHandler) JWKS() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		res.Json(w, handler.AuthService.jwt.Keys.JWKS(time.Now()), http.StatusOK)
+	}
+}
+
+// ForgotPassword godoc
+// @Summary Запрос сброса пароля
+// @Description Отправляет на email одноразовую ссылку для сброса пароля. Ответ одинаковый независимо от того, зарегистрирован ли email
+// @Tags auth,open,user
+// @Accept json
+// @Param request body auth.ForgotPasswordRequest true "Email"
+// @Success 202
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/password/forgot [post]
+func (handler *AuthHandler) ForgotPassword() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := req.HandleBody[ForgotPasswordRequest](&w, r)
+		if err != nil {
+			return
+		}
+		if err := handler.AuthService.ForgotPassword(r.Context(), body.Email); err != nil {
+			res.Json(w, map[string]string{"error": "failed to request password reset"}, http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+// ResetPassword godoc
+// @Summary Сброс пароля
+// @Description Устанавливает новый пароль по токену из письма. Токен одноразовый; после сброса все сессии пользователя завершаются
+// @Tags auth,open,user
+// @Accept json
+// @Param request body auth.ResetPasswordRequest true "Токен и новый пароль"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/password/reset [post]
+func (handler *AuthHandler) ResetPassword() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := req.HandleBody[ResetPasswordRequest](&w, r)
+		if err != nil {
+			return
+		}
+		if err := handler.AuthService.ResetPassword(r.Context(), body.Token, body.Password); err != nil {
+			if err.Error() == ErrInvalidToken {
+				res.Json(w, map[string]string{"error": err.Error()}, http.StatusBadRequest)
+				return
+			}
+			res.Json(w, map[string]string{"error": "failed to reset password"}, http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// VerifyEmail godoc
+// @Summary Подтверждение email
+// @Description Подтверждает email (или смену email) по одноразовому токену из письма
+// @Tags auth,open,user
+// @Produce json
+// @Param token query string true "Токен из письма"
+// @Success 200 {object} auth.VerifyResponse
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/verify [get]
+func (handler *AuthHandler) VerifyEmail() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("token")
+		if token == "" {
+			res.Json(w, map[string]string{"error": "token parameter is required"}, http.StatusBadRequest)
+			return
+		}
+		user, err := handler.AuthService.VerifyEmail(r.Context(), token)
+		if err != nil {
+			if err.Error() == ErrInvalidToken {
+				res.Json(w, map[string]string{"error": err.Error()}, http.StatusBadRequest)
+				return
+			}
+			if err.Error() == ErrUserAlreadyExists {
+				res.Json(w, map[string]string{"error": err.Error()}, http.StatusConflict)
+				return
+			}
+			res.Json(w, map[string]string{"error": "failed to verify email"}, http.StatusInternalServerError)
+			return
+		}
+		res.Json(w, VerifyResponse{
+			Email:      user.Email,
+			VerifiedAt: user.VerifiedAt.UTC().Format(time.RFC3339),
+		}, http.StatusOK)
+	}
+}
+
+// ResendVerification godoc
+// @Summary Повторная отправка письма подтверждения
+// @Description Отправляет новое письмо для подтверждения email текущего пользователя; предыдущие ссылки перестают работать
+// @Tags auth,jwt,user
+// @Success 202
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/verify/resend [post]
+func (handler *AuthHandler) ResendVerification() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := middleware.FromContext(r.Context())
+		if !ok {
+			res.Json(w, map[string]string{"error": "unauthorized"}, http.StatusUnauthorized)
+			return
+		}
+		if err := handler.AuthService.ResendVerification(r.Context(), principal.UserID); err != nil {
+			if err.Error() == ErrAlreadyVerified {
+				res.Json(w, map[string]string{"error": err.Error()}, http.StatusConflict)
+				return
+			}
+			res.Json(w, map[string]string{"error": "failed to send verification"}, http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+// Unlock godoc
+// @Summary Снять блокировку входа (админ)
+// @Description Сбрасывает счётчик неудачных попыток входа для email и/или IP
+// @Tags auth,admin
+// @Accept json
+// @Param request body auth.UnlockRequest true "Email и/или IP"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/unlock [post]
+func (handler *AuthHandler) Unlock() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := req.HandleBody[UnlockRequest](&w, r)
+		if err != nil {
+			return
+		}
+		if err := handler.AuthService.Unlock(r.Context(), body.Email, body.IP); err != nil {
+			res.Json(w, map[string]string{"error": "failed to unlock"}, http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// ChangePassword godoc
+// @Summary Смена пароля
+// @Description Меняет пароль текущего пользователя. Требует текущий пароль; все остальные сессии завершаются, для текущей выдаётся новая пара токенов
+// @Tags users,jwt,user
+// @Accept json
+// @Produce json
+// @Param request body auth.ChangePasswordRequest true "Текущий и новый пароль"
+// @Success 200 {object} auth.ChangePasswordResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/me/password [post]
+func (handler *AuthHandler) ChangePassword() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := req.HandleBody[ChangePasswordRequest](&w, r)
+		if err != nil {
+			return
+		}
+		principal, ok := middleware.FromContext(r.Context())
+		if !ok {
+			res.Json(w, map[string]string{"error": "unauthorized"}, http.StatusUnauthorized)
+			return
+		}
+		tokens, err := handler.AuthService.ChangePassword(r.Context(), principal.UserID, body.CurrentPassword, body.NewPassword, clientInfo(r, ""))
+		if err != nil {
+			if err.Error() == ErrWrongCredentials {
+				res.Json(w, map[string]string{"error": err.Error()}, http.StatusBadRequest)
+				return
+			}
+			res.Json(w, map[string]string{"error": "failed to change password"}, http.StatusInternalServerError)
+			return
+		}
+		res.Json(w, ChangePasswordResponse{
+			TokenResponse: toTokenResponse(tokens),
+		}, http.StatusOK)
+	}
+}
+
+// ChangeEmail godoc
+// @Summary Смена email
+// @Description Отправляет письмо с подтверждением на новый адрес. Email аккаунта меняется только после перехода по ссылке из письма (GET /auth/verify)
+// @Tags users,jwt,user
+// @Accept json
+// @Param request body auth.ChangeEmailRequest true "Новый email и текущий пароль"
+// @Success 202
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/me/email [post]
+func (handler *AuthHandler) ChangeEmail() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := req.HandleBody[ChangeEmailRequest](&w, r)
+		if err != nil {
+			return
+		}
+		principal, ok := middleware.FromContext(r.Context())
+		if !ok {
+			res.Json(w, map[string]string{"error": "unauthorized"}, http.StatusUnauthorized)
+			return
+		}
+		err = handler.AuthService.RequestEmailChange(r.Context(), principal.UserID, body.CurrentPassword, body.NewEmail)
+		if err != nil {
+			switch err.Error() {
+			case ErrWrongCredentials, ErrSameEmail:
+				res.Json(w, map[string]string{"error": err.Error()}, http.StatusBadRequest)
+			case ErrUserAlreadyExists:
+				res.Json(w, map[string]string{"error": err.Error()}, http.StatusConflict)
+			default:
+				res.Json(w, map[string]string{"error": "failed to change email"}, http.StatusInternalServerError)
+			}
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+// TwoFactorSetup godoc
+// @Summary Начать подключение 2FA
+// @Description Выпускает TOTP-секрет и otpauth-ссылку для приложения-аутентификатора. 2FA включится после POST /auth/2fa/confirm
+// @Tags auth,jwt,user
+// @Produce json
+// @Success 200 {object} auth.TwoFactorSetupResponse
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/2fa/setup [post]
+func (handler *AuthHandler) TwoFactorSetup() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := middleware.FromContext(r.Context())
+		if !ok {
+			res.Json(w, map[string]string{"error": "unauthorized"}, http.StatusUnauthorized)
+			return
+		}
+		setup, err := handler.AuthService.SetupTwoFactor(r.Context(), principal.UserID)
+		if err != nil {
+			if err.Error() == ErrTwoFactorEnabled {
+				res.Json(w, map[string]string{"error": err.Error()}, http.StatusConflict)
+				return
+			}
+			res.Json(w, map[string]string{"error": "failed to set up two-factor authentication"}, http.StatusInternalServerError)
+			return
+		}
+		res.Json(w, TwoFactorSetupResponse{Secret: setup.Secret, URI: setup.URI}, http.StatusOK)
+	}
+}
+
+// TwoFactorConfirm godoc
+// @Summary Включить 2FA
+// @Description Подтверждает подключение кодом из приложения и возвращает одноразовые коды восстановления (показываются один раз)
+// @Tags auth,jwt,user
+// @Accept json
+// @Produce json
+// @Param request body auth.TwoFactorConfirmRequest true "Код из приложения"
+// @Success 200 {object} auth.TwoFactorConfirmResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/2fa/confirm [post]
+func (handler *AuthHandler) TwoFactorConfirm() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := req.HandleBody[TwoFactorConfirmRequest](&w, r)
+		if err != nil {
+			return
+		}
+		principal, ok := middleware.FromContext(r.Context())
+		if !ok {
+			res.Json(w, map[string]string{"error": "unauthorized"}, http.StatusUnauthorized)
+			return
+		}
+		codes, err := handler.AuthService.ConfirmTwoFactor(r.Context(), principal.UserID, body.Code)
+		if err != nil {
+			switch err.Error() {
+			case ErrInvalidTwoFactor, ErrTwoFactorNotSetUp:
+				res.Json(w, map[string]string{"error": err.Error()}, http.StatusBadRequest)
+			case ErrTwoFactorEnabled:
+				res.Json(w, map[string]string{"error": err.Error()}, http.StatusConflict)
+			default:
+				res.Json(w, map[string]string{"error": "failed to enable two-factor authentication"}, http.StatusInternalServerError)
+			}
+			return
+		}
+		res.Json(w, TwoFactorConfirmResponse{RecoveryCodes: codes}, http.StatusOK)
+	}
+}
+
+// TwoFactorVerify godoc
+// @Summary Второй шаг входа
+// @Description Обменивает токен второго шага из /auth/login и код из приложения (или код восстановления) на пару токенов
+// @Tags auth,open,user
+// @Accept json
+// @Produce json
+// @Param request body auth.TwoFactorVerifyRequest true "Токен второго шага и код"
+// @Success 200 {object} auth.TwoFactorVerifyResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/2fa/verify [post]
+func (handler *AuthHandler) TwoFactorVerify() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := req.HandleBody[TwoFactorVerifyRequest](&w, r)
+		if err != nil {
+			return
+		}
+		tokens, err := handler.AuthService.VerifyTwoFactor(r.Context(), body.ChallengeToken, body.Code, clientInfo(r, body.DeviceName))
+		if err != nil {
+			var tooMany *TooManyAttemptsError
+			switch {
+			case errors.As(err, &tooMany):
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(tooMany.RetryAfter.Seconds()))))
+				http.Error(w, err.Error(), http.StatusTooManyRequests)
+			case err.Error() == ErrInvalidToken || err.Error() == ErrInvalidTwoFactor:
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+			case err.Error() == ErrUserSuspended:
+				http.Error(w, err.Error(), http.StatusForbidden)
+			default:
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		res.Json(w, TwoFactorVerifyResponse{TokenResponse: toTokenResponse(tokens)}, http.StatusOK)
+	}
+}
+
+// OIDCProviders godoc
+// @Summary Провайдеры входа
+// @Description Список настроенных провайдеров OpenID Connect
+// @Tags auth,open,user
+// @Produce json
+// @Success 200 {object} auth.OIDCProvidersResponse
+// @Router /auth/oidc/providers [get]
+func (handler *AuthHandler) OIDCProviders() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		res.Json(w, OIDCProvidersResponse{Providers: handler.AuthService.OIDCProviders.Names()}, http.StatusOK)
+	}
+}
+
+// OIDCStart godoc
+// @Summary Начать вход через провайдера
+// @Description Возвращает ссылку на страницу входа провайдера (authorization code flow с PKCE).
+// @Description После входа провайдер вернёт пользователя на настроенный redirect URL с параметрами code и state — их нужно передать в /auth/oidc/{provider}/callback
+// @Tags auth,open,user
+// @Produce json
+// @Param provider path string true "Имя провайдера, например google"
+// @Success 200 {object} auth.OIDCStartResponse
+// @Failure 404 {object} map[string]string
+// @Failure 502 {object} map[string]string
+// @Router /auth/oidc/{provider}/start [post]
+func (handler *AuthHandler) OIDCStart() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth, err := handler.AuthService.StartOIDC(r.Context(), r.PathValue("provider"))
+		if err != nil {
+			if err.Error() == ErrUnknownProvider {
+				res.Json(w, map[string]string{"error": err.Error()}, http.StatusNotFound)
+				return
+			}
+			res.Json(w, map[string]string{"error": "identity provider unavailable"}, http.StatusBadGateway)
+			return
+		}
+		res.Json(w, OIDCStartResponse{
+			AuthorizationURL: auth.URL,
+			State:            auth.State,
+			ExpiresAt:        auth.ExpiresAt.UTC().Format(time.RFC3339),
+		}, http.StatusOK)
+	}
+}
+
+// OIDCCallback godoc
+// @Summary Завершить вход через провайдера
+// @Description Обменивает code от провайдера на пару токенов (или токен второго шага, если включена 2FA).
+// @Description Аккаунт провайдера привязывается к пользователю с тем же email, если провайдер подтвердил email; иначе создаётся новый пользователь
+// @Tags auth,open,user
+// @Accept json
+// @Produce json
+// @Param provider path string true "Имя провайдера, например google"
+// @Param request body auth.OIDCCallbackRequest true "code и state из redirect URL"
+// @Success 200 {object} auth.LoginResponse
+// @Success 202 {object} auth.TwoFactorChallengeResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/oidc/{provider}/callback [post]
+func (handler *AuthHandler) OIDCCallback() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := req.HandleBody[OIDCCallbackRequest](&w, r)
+		if err != nil {
+			return
+		}
+		user, err := handler.AuthService.CompleteOIDC(r.Context(), r.PathValue("provider"), body.Code, body.State)
+		if err != nil {
+			switch err.Error() {
+			case ErrUnknownProvider:
+				res.Json(w, map[string]string{"error": err.Error()}, http.StatusNotFound)
+			case ErrInvalidState, ErrProviderNoEmail:
+				res.Json(w, map[string]string{"error": err.Error()}, http.StatusBadRequest)
+			case ErrProviderLogin:
+				res.Json(w, map[string]string{"error": err.Error()}, http.StatusUnauthorized)
+			case ErrProviderUnverified:
+				res.Json(w, map[string]string{"error": err.Error()}, http.StatusConflict)
+			default:
+				res.Json(w, map[string]string{"error": "failed to sign in"}, http.StatusInternalServerError)
+			}
+			return
+		}
+		handler.completeLogin(w, r, user, clientInfo(r, body.DeviceName))
+	}
+}
+
+// clientInfo описывает устройство, с которого пришёл запрос. Название
+// устройства берётся из тела запроса или из заголовка X-Device-Name.
+func clientInfo(r *http.Request, deviceName string) ClientInfo {
+	if deviceName == "" {
+		deviceName = r.Header.Get("X-Device-Name")
+	}
+	return ClientInfo{
+		DeviceName: deviceName,
+		UserAgent:  r.UserAgent(),
+		IP:         req.ClientIP(r),
+	}
+}
+
+// ListSessions godoc
+// @Summary Активные сессии
+// @Description Возвращает устройства, на которых выполнен вход. Текущая сессия отмечена полем current
+// @Tags auth,jwt,user
+// @Produce json
+// @Success 200 {array} auth.SessionResponse
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/sessions [get]
+func (handler *AuthHandler) ListSessions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		data, _ := r.Context().Value(middleware.ContextTokenKey).(*jwt.JWTData)
+		list, err := handler.AuthService.ListSessions(r.Context(), data.UserID)
+		if err != nil {
+			res.Json(w, map[string]string{"error": "failed to list sessions"}, http.StatusInternalServerError)
+			return
+		}
+		out := make([]SessionResponse, 0, len(list))
+		for i := range list {
+			out = append(out, toSessionResponse(&list[i], data.SessionID))
+		}
+		res.Json(w, out, http.StatusOK)
+	}
+}
+
+// RevokeSession godoc
+// @Summary Завершить сессию
+// @Description Выходит из аккаунта на выбранном устройстве: его access- и refresh-токены перестают действовать
+// @Tags auth,jwt,user
+// @Param id path string true "ID сессии"
+// @Success 204
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/sessions/{id} [delete]
+func (handler *AuthHandler) RevokeSession() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := middleware.FromContext(r.Context())
+		if !ok {
+			res.Json(w, map[string]string{"error": "unauthorized"}, http.StatusUnauthorized)
+			return
+		}
+		if err := handler.AuthService.RevokeSession(r.Context(), principal.UserID, r.PathValue("id")); err != nil {
+			if err.Error() == ErrSessionNotFound {
+				res.Json(w, map[string]string{"error": err.Error()}, http.StatusNotFound)
+				return
+			}
+			res.Json(w, map[string]string{"error": "failed to revoke session"}, http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// RequestOTP godoc
+// @Summary Запросить код входа по SMS
+// @Description Отправляет 6-значный код на подтверждённый телефон, привязанный к аккаунту. Ответ не зависит от того, зарегистрирован ли номер.
+// @Description Повторный запрос возможен после паузы (429 с заголовком Retry-After)
+// @Tags auth,open,user
+// @Accept json
+// @Param request body auth.OTPRequest true "Телефон"
+// @Success 202
+// @Failure 400 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/otp/request [post]
+func (handler *AuthHandler) RequestOTP() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := req.HandleBody[OTPRequest](&w, r)
+		if err != nil {
+			return
+		}
+		if err := handler.AuthService.RequestOTP(r.Context(), body.Phone); err != nil {
+			var tooMany *TooManyAttemptsError
+			switch {
+			case errors.As(err, &tooMany):
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(tooMany.RetryAfter.Seconds()))))
+				res.Json(w, map[string]string{"error": "code already sent, retry later"}, http.StatusTooManyRequests)
+			case err.Error() == ErrInvalidPhone:
+				res.Json(w, map[string]string{"error": err.Error()}, http.StatusBadRequest)
+			default:
+				res.Json(w, map[string]string{"error": "failed to send code"}, http.StatusInternalServerError)
+			}
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+// VerifyOTP godoc
+// @Summary Войти по коду из SMS
+// @Description Проверяет код и возвращает пару токенов (или токен второго шага, если включена 2FA). Число попыток ввода одного кода ограничено.
+// @Description Вход возможен только по телефону, подтверждённому через POST /users/me/phone/confirm
+// @Tags auth,open,user
+// @Accept json
+// @Produce json
+// @Param request body auth.OTPVerifyRequest true "Телефон и код"
+// @Success 200 {object} auth.LoginResponse
+// @Success 202 {object} auth.TwoFactorChallengeResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/otp/verify [post]
+func (handler *AuthHandler) VerifyOTP() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := req.HandleBody[OTPVerifyRequest](&w, r)
+		if err != nil {
+			return
+		}
+		user, err := handler.AuthService.VerifyOTP(r.Context(), body.Phone, body.Code)
+		if err != nil {
+			switch err.Error() {
+			case ErrInvalidPhone:
+				res.Json(w, map[string]string{"error": err.Error()}, http.StatusBadRequest)
+			case ErrInvalidOTP:
+				res.Json(w, map[string]string{"error": err.Error()}, http.StatusUnauthorized)
+			default:
+				res.Json(w, map[string]string{"error": "failed to sign in"}, http.StatusInternalServerError)
+			}
+			return
+		}
+		handler.completeLogin(w, r, user, clientInfo(r, body.DeviceName))
+	}
+}
+
+// RequestPhoneVerification godoc
+// @Summary Запросить код подтверждения телефона
+// @Description Отправляет 6-значный код на телефон из профиля. Пока телефон не подтверждён, войти по SMS нельзя
+// @Tags auth,jwt,user
+// @Success 202
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/me/phone/verify [post]
+func (handler *AuthHandler) RequestPhoneVerification() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := middleware.FromContext(r.Context())
+		if !ok {
+			res.Json(w, map[string]string{"error": "unauthorized"}, http.StatusUnauthorized)
+			return
+		}
+		if err := handler.AuthService.RequestPhoneVerification(r.Context(), principal.UserID); err != nil {
+			var tooMany *TooManyAttemptsError
+			switch {
+			case errors.As(err, &tooMany):
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(tooMany.RetryAfter.Seconds()))))
+				res.Json(w, map[string]string{"error": "code already sent, retry later"}, http.StatusTooManyRequests)
+			case err.Error() == ErrPhoneNotSet, err.Error() == ErrPhoneVerified:
+				res.Json(w, map[string]string{"error": err.Error()}, http.StatusConflict)
+			default:
+				res.Json(w, map[string]string{"error": "failed to send code"}, http.StatusInternalServerError)
+			}
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+// ConfirmPhone godoc
+// @Summary Подтвердить телефон
+// @Description Проверяет код из SMS и отмечает телефон подтверждённым
+// @Tags auth,jwt,user
+// @Accept json
+// @Produce json
+// @Param request body auth.PhoneConfirmRequest true "Код"
+// @Success 200 {object} users.UserResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/me/phone/confirm [post]
+func (handler *AuthHandler) ConfirmPhone() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := req.HandleBody[PhoneConfirmRequest](&w, r)
+		if err != nil {
+			return
+		}
+		principal, ok := middleware.FromContext(r.Context())
+		if !ok {
+			res.Json(w, map[string]string{"error": "unauthorized"}, http.StatusUnauthorized)
+			return
+		}
+		user, err := handler.AuthService.ConfirmPhone(r.Context(), principal.UserID, body.Code)
+		if err != nil {
+			switch err.Error() {
+			case ErrInvalidOTP:
+				res.Json(w, map[string]string{"error": err.Error()}, http.StatusBadRequest)
+			case ErrPhoneNotSet, ErrPhoneVerified:
+				res.Json(w, map[string]string{"error": err.Error()}, http.StatusConflict)
+			default:
+				res.Json(w, map[string]string{"error": "failed to confirm phone"}, http.StatusInternalServerError)
+			}
+			return
+		}
+		res.Json(w, users.ToResponse(user), http.StatusOK)
+	}
+}
@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"bike/configs"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// LoginAttempt — счётчик неудачных попыток входа по ключу (email или IP).
+// Используется и как модель таблицы для PostgresAttemptStore.
+type LoginAttempt struct {
+	Key           string `gorm:"primaryKey;size:320"`
+	Failures      int    `gorm:"not null"`
+	LastFailureAt time.Time
+	LockedUntil   *time.Time
+}
+
+// AttemptStore хранит счётчики неудачных попыток входа.
+type AttemptStore interface {
+	// Get возвращает состояние ключа или nil, если попыток не было.
+	Get(ctx context.Context, key string) (*LoginAttempt, error)
+	// Increment атомарно увеличивает счётчик; неудачи старше window забываются.
+	Increment(ctx context.Context, key string, now time.Time, window time.Duration) (*LoginAttempt, error)
+	// Lock блокирует ключ до until.
+	Lock(ctx context.Context, key string, until time.Time) error
+	// Reset сбрасывает счётчик и блокировку.
+	Reset(ctx context.Context, key string) error
+}
+
+// TooManyAttemptsError — вход временно запрещён.
+type TooManyAttemptsError struct {
+	RetryAfter time.Duration
+}
+
+func (e *TooManyAttemptsError) Error() string {
+	return fmt.Sprintf("Too many login attempts, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+// LoginLimiter применяет экспоненциальную задержку и временную блокировку
+// к неудачным попыткам входа по email и по IP.
+type LoginLimiter struct {
+	store AttemptStore
+	conf  configs.LockoutConfig
+}
+
+func NewLoginLimiter(store AttemptStore, conf configs.LockoutConfig) *LoginLimiter {
+	return &LoginLimiter{store: store, conf: conf}
+}
+
+func emailAttemptKey(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipAttemptKey(ip string) string {
+	return "ip:" + ip
+}
+
+// Check возвращает *TooManyAttemptsError, если по email или IP сейчас входить нельзя.
+func (l *LoginLimiter) Check(ctx context.Context, email, ip string) error {
+	now := time.Now()
+	var wait time.Duration
+	for _, key := range []string{emailAttemptKey(email), ipAttemptKey(ip)} {
+		a, err := l.store.Get(ctx, key)
+		if err != nil {
+			return err
+		}
+		if a == nil {
+			continue
+		}
+		if d := l.retryAfter(a, now); d > wait {
+			wait = d
+		}
+	}
+	if wait > 0 {
+		return &TooManyAttemptsError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// Fail учитывает неудачную попытку и при превышении порога блокирует ключ.
+func (l *LoginLimiter) Fail(ctx context.Context, email, ip string) {
+	now := time.Now()
+	l.fail(ctx, emailAttemptKey(email), l.conf.MaxFailures, now)
+	l.fail(ctx, ipAttemptKey(ip), l.conf.IPMaxFailures, now)
+}
+
+// Succeed сбрасывает счётчик по email. Счётчик IP не сбрасываем: иначе,
+// зная пароль от одного аккаунта, можно было бы перебирать остальные.
+func (l *LoginLimiter) Succeed(ctx context.Context, email string) {
+	if err := l.store.Reset(ctx, emailAttemptKey(email)); err != nil {
+		log.Printf("login limiter: failed to reset %s: %v", email, err)
+	}
+}
+
+// Unlock снимает блокировку с email (и, если указан, с IP).
+func (l *LoginLimiter) Unlock(ctx context.Context, email, ip string) error {
+	if email != "" {
+		if err := l.store.Reset(ctx, emailAttemptKey(email)); err != nil {
+			return err
+		}
+	}
+	if ip != "" {
+		if err := l.store.Reset(ctx, ipAttemptKey(ip)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *LoginLimiter) fail(ctx context.Context, key string, maxFailures int, now time.Time) {
+	a, err := l.store.Increment(ctx, key, now, l.conf.LockDuration)
+	if err != nil {
+		log.Printf("login limiter: failed to record failure for %s: %v", key, err)
+		return
+	}
+	if maxFailures > 0 && a.Failures >= maxFailures {
+		log.Printf("login limiter: %s locked after %d failures", key, a.Failures)
+		if err := l.store.Lock(ctx, key, now.Add(l.conf.LockDuration)); err != nil {
+			log.Printf("login limiter: failed to lock %s: %v", key, err)
+		}
+	}
+}
+
+func (l *LoginLimiter) retryAfter(a *LoginAttempt, now time.Time) time.Duration {
+	var wait time.Duration
+	if a.LockedUntil != nil && a.LockedUntil.After(now) {
+		wait = a.LockedUntil.Sub(now)
+	}
+	if now.Sub(a.LastFailureAt) > l.conf.LockDuration {
+		return wait
+	}
+	if d := a.LastFailureAt.Add(l.backoff(a.Failures)).Sub(now); d > wait {
+		wait = d
+	}
+	return wait
+}
+
+// backoff — задержка после failures неудач подряд.
+func (l *LoginLimiter) backoff(failures int) time.Duration {
+	n := failures - l.conf.FreeAttempts
+	if n <= 0 {
+		return 0
+	}
+	d := l.conf.BaseDelay
+	for i := 1; i < n; i++ {
+		d *= 2
+		if d >= l.conf.MaxDelay {
+			return l.conf.MaxDelay
+		}
+	}
+	return min(d, l.conf.MaxDelay)
+}
@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"bike/pkg/db"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MemoryAttemptStore хранит счётчики в памяти процесса — подходит для одного инстанса.
+type MemoryAttemptStore struct {
+	mu        sync.Mutex
+	attempts  map[string]*LoginAttempt
+	lastPrune time.Time
+}
+
+func NewMemoryAttemptStore() *MemoryAttemptStore {
+	return &MemoryAttemptStore{attempts: make(map[string]*LoginAttempt)}
+}
+
+func (s *MemoryAttemptStore) Get(ctx context.Context, key string) (*LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.attempts[key]
+	if !ok {
+		return nil, nil
+	}
+	cp := *a
+	return &cp, nil
+}
+
+func (s *MemoryAttemptStore) Increment(ctx context.Context, key string, now time.Time, window time.Duration) (*LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune(now, window)
+	a, ok := s.attempts[key]
+	if !ok || now.Sub(a.LastFailureAt) > window {
+		a = &LoginAttempt{Key: key}
+		s.attempts[key] = a
+	}
+	a.Failures++
+	a.LastFailureAt = now
+	cp := *a
+	return &cp, nil
+}
+
+func (s *MemoryAttemptStore) Lock(ctx context.Context, key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.attempts[key]
+	if !ok {
+		a = &LoginAttempt{Key: key, LastFailureAt: time.Now()}
+		s.attempts[key] = a
+	}
+	a.LockedUntil = &until
+	return nil
+}
+
+func (s *MemoryAttemptStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.attempts, key)
+	return nil
+}
+
+// prune раз в window выбрасывает устаревшие записи, чтобы карта не росла бесконечно.
+func (s *MemoryAttemptStore) prune(now time.Time, window time.Duration) {
+	if now.Sub(s.lastPrune) < window {
+		return
+	}
+	s.lastPrune = now
+	for key, a := range s.attempts {
+		locked := a.LockedUntil != nil && a.LockedUntil.After(now)
+		if !locked && now.Sub(a.LastFailureAt) > window {
+			delete(s.attempts, key)
+		}
+	}
+}
+
+// PostgresAttemptStore хранит счётчики в таблице login_attempts — общий
+// для всех инстансов API.
+type PostgresAttemptStore struct {
+	database *db.Db
+}
+
+func NewPostgresAttemptStore(database *db.Db) *PostgresAttemptStore {
+	return &PostgresAttemptStore{database: database}
+}
+
+func (s *PostgresAttemptStore) Get(ctx context.Context, key string) (*LoginAttempt, error) {
+	var a LoginAttempt
+	result := s.database.DB.WithContext(ctx).First(&a, "key = ?", key)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &a, nil
+}
+
+func (s *PostgresAttemptStore) Increment(ctx context.Context, key string, now time.Time, window time.Duration) (*LoginAttempt, error) {
+	var a LoginAttempt
+	result := s.database.DB.WithContext(ctx).Raw(`
+		INSERT INTO login_attempts (key, failures, last_failure_at)
+		VALUES (?, 1, ?)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN login_attempts.last_failure_at < ? THEN 1 ELSE login_attempts.failures + 1 END,
+			last_failure_at = EXCLUDED.last_failure_at
+		RETURNING key, failures, last_failure_at, locked_until`,
+		key, now, now.Add(-window),
+	).Scan(&a)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &a, nil
+}
+
+func (s *PostgresAttemptStore) Lock(ctx context.Context, key string, until time.Time) error {
+	return s.database.DB.WithContext(ctx).Model(&LoginAttempt{}).
+		Where("key = ?", key).
+		Update("locked_until", until).Error
+}
+
+func (s *PostgresAttemptStore) Reset(ctx context.Context, key string) error {
+	return s.database.DB.WithContext(ctx).Delete(&LoginAttempt{}, "key = ?", key).Error
+}
@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RefreshToken — выданный refresh-токен. В базе хранится только sha256-хэш.
+// Все токены, полученные ротацией от одного логина, образуют семейство (FamilyID).
+type RefreshToken struct {
+	gorm.Model
+	UserID    uint      `gorm:"index;not null"`
+	FamilyID  string    `gorm:"size:36;index;not null"`
+	TokenHash string    `gorm:"size:64;uniqueIndex;not null"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+}
+
+// TokenRevocation — запись об отзыве access-токенов.
+// Если заполнен JTI — отозван один токен; если SessionID — все токены
+// сессии; если Subject — все токены пользователя, выданные раньше IssuedBefore. Запись нужна только
+// до ExpiresAt: после этого отозванные токены истекают сами.
+type TokenRevocation struct {
+	ID           uint   `gorm:"primaryKey"`
+	JTI          string `gorm:"size:36;index"`
+	Subject      string `gorm:"size:255;index"`
+	SessionID    string `gorm:"size:36;index"`
+	IssuedBefore time.Time
+	ExpiresAt    time.Time `gorm:"index;not null"`
+	CreatedAt    time.Time
+}
+
+// Назначение одноразового токена
+const (
+	PurposePasswordReset     = "password_reset"
+	PurposeEmailVerification = "email_verification"
+	PurposeEmailChange       = "email_change"
+	PurposeTwoFactorLogin    = "two_factor_login"
+)
+
+// UserToken — одноразовый токен для действий по ссылке из письма (сброс пароля и т.п.).
+// Как и refresh-токены, хранится только хэш.
+type UserToken struct {
+	gorm.Model
+	UserID    uint   `gorm:"index;not null"`
+	Purpose   string `gorm:"size:32;index;not null"`
+	TokenHash string `gorm:"size:64;uniqueIndex;not null"`
+	// Данные действия, например новый email для PurposeEmailChange
+	Payload   string    `gorm:"size:320"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+}
+
+// TwoFactor — TOTP-секрет пользователя. Пока ConfirmedAt пуст, 2FA не включена.
+// LastStep — шаг последнего принятого кода, чтобы один код нельзя было использовать дважды.
+type TwoFactor struct {
+	gorm.Model
+	UserID      uint   `gorm:"uniqueIndex;not null"`
+	Secret      string `gorm:"size:64;not null"`
+	ConfirmedAt *time.Time
+	LastStep    int64
+}
+
+func (t *TwoFactor) Enabled() bool {
+	return t != nil && t.ConfirmedAt != nil
+}
+
+// RecoveryCode — одноразовый код восстановления на случай потери аутентификатора.
+type RecoveryCode struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"index;not null"`
+	CodeHash  string `gorm:"size:64;not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// Identity — аккаунт внешнего провайдера OIDC, привязанный к пользователю.
+type Identity struct {
+	gorm.Model
+	UserID   uint   `gorm:"index;not null"`
+	Provider string `gorm:"size:32;not null;uniqueIndex:idx_identity_provider_subject"`
+	Subject  string `gorm:"size:255;not null;uniqueIndex:idx_identity_provider_subject"`
+	// Email у провайдера на момент последнего входа
+	Email string `gorm:"size:320"`
+}
+
+// OIDCState — незавершённый вход через провайдера: state, nonce и PKCE
+// code_verifier, которые нужно сверить при возврате пользователя.
+type OIDCState struct {
+	ID        uint      `gorm:"primaryKey"`
+	StateHash string    `gorm:"size:64;uniqueIndex;not null"`
+	Provider  string    `gorm:"size:32;not null"`
+	Nonce     string    `gorm:"size:64;not null"`
+	Verifier  string    `gorm:"size:128;not null"`
+	ExpiresAt time.Time `gorm:"index;not null"`
+	CreatedAt time.Time
+}
+
+// Session — вход пользователя с одного устройства. ID совпадает с
+// RefreshToken.FamilyID и передаётся в access-токене как claim sid.
+type Session struct {
+	ID         string `gorm:"primaryKey;size:36"`
+	UserID     uint   `gorm:"index;not null"`
+	DeviceName string `gorm:"size:128"`
+	UserAgent  string `gorm:"size:512"`
+	IP         string `gorm:"size:64"`
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	RevokedAt  *time.Time
+}
+
+// PhoneCode — одноразовый код входа, отправленный по SMS. Хранится bcrypt-хэш
+// кода: 6 цифр по sha256 перебираются мгновенно.
+type PhoneCode struct {
+	ID        uint      `gorm:"primaryKey"`
+	Phone     string    `gorm:"size:32;index;not null"`
+	CodeHash  string    `gorm:"size:64;not null"`
+	Attempts  int       `gorm:"not null;default:0"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
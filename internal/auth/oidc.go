@@ -0,0 +1,183 @@
+package auth
+
+import (
+	"bike/internal/users"
+	"bike/pkg/oidc"
+	"bike/pkg/rbac"
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"log"
+	"strings"
+	"time"
+)
+
+// OIDCAuthorization — куда отправить пользователя для входа через провайдера.
+type OIDCAuthorization struct {
+	URL       string
+	State     string
+	ExpiresAt time.Time
+}
+
+// StartOIDC начинает вход через провайдера: сохраняет state, nonce и
+// PKCE code_verifier и возвращает ссылку на страницу входа провайдера.
+func (service *AuthService) StartOIDC(ctx context.Context, providerName string) (*OIDCAuthorization, error) {
+	provider, err := service.OIDCProviders.Get(providerName)
+	if err != nil {
+		return nil, errors.New(ErrUnknownProvider)
+	}
+	state, err := oidc.RandomString()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := oidc.RandomString()
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := oidc.RandomString()
+	if err != nil {
+		return nil, err
+	}
+	url, err := provider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(service.config.OIDC.StateTTL)
+	err = service.IdentityRepository.CreateState(ctx, &OIDCState{
+		StateHash: hashToken(state),
+		Provider:  provider.Name,
+		Nonce:     nonce,
+		Verifier:  verifier,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &OIDCAuthorization{URL: url, State: state, ExpiresAt: expiresAt}, nil
+}
+
+// CompleteOIDC завершает вход: обменивает code на ID-токен, проверяет его
+// и находит пользователя по привязанному аккаунту провайдера. Если привязки
+// нет, аккаунт привязывается к пользователю с тем же email (только если
+// провайдер подтвердил email) или создаётся новый пользователь.
+func (service *AuthService) CompleteOIDC(ctx context.Context, providerName, code, state string) (*users.User, error) {
+	provider, err := service.OIDCProviders.Get(providerName)
+	if err != nil {
+		return nil, errors.New(ErrUnknownProvider)
+	}
+	stored, err := service.IdentityRepository.TakeState(ctx, hashToken(state))
+	if err != nil || stored.Provider != provider.Name || time.Now().After(stored.ExpiresAt) {
+		return nil, errors.New(ErrInvalidState)
+	}
+	tokens, err := provider.Exchange(ctx, code, stored.Verifier)
+	if err != nil {
+		log.Printf("oidc login: %v", err)
+		return nil, errors.New(ErrProviderLogin)
+	}
+	claims, err := provider.VerifyIDToken(ctx, tokens.IDToken, stored.Nonce)
+	if err != nil {
+		log.Printf("oidc login: %v", err)
+		return nil, errors.New(ErrProviderLogin)
+	}
+
+	identity, err := service.IdentityRepository.Find(ctx, provider.Name, claims.Subject)
+	if err == nil {
+		user, err := service.UserRepository.FindByID(identity.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if claims.Email != "" && identity.Email != claims.Email {
+			identity.Email = claims.Email
+			if err := service.IdentityRepository.Save(ctx, identity); err != nil {
+				return nil, err
+			}
+		}
+		return user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	user, err := service.userForIdentity(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+	err = service.IdentityRepository.Save(ctx, &Identity{
+		UserID:   user.ID,
+		Provider: provider.Name,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// userForIdentity находит пользователя по email из ID-токена или создаёт нового.
+func (service *AuthService) userForIdentity(ctx context.Context, claims *oidc.Claims) (*users.User, error) {
+	if claims.Email == "" {
+		return nil, errors.New(ErrProviderNoEmail)
+	}
+	existedUser, _ := service.UserRepository.FindByEmail(claims.Email)
+	if existedUser != nil {
+		// Иначе чужой аккаунт у провайдера с тем же, но не подтверждённым
+		// email получил бы доступ к пользователю
+		if !claims.EmailVerified {
+			return nil, errors.New(ErrProviderUnverified)
+		}
+		if !existedUser.IsVerified() {
+			if err := service.claimUnverifiedUser(ctx, existedUser); err != nil {
+				return nil, err
+			}
+		}
+		return existedUser, nil
+	}
+
+	name := claims.Name
+	if name == "" {
+		name, _, _ = strings.Cut(claims.Email, "@")
+	}
+	// Пароля у такого пользователя нет (см. User.HasPassword); задать его
+	// можно через сброс пароля
+	user := &users.User{
+		Email: claims.Email,
+		Name:  name,
+		Role:  rbac.RoleCustomer,
+	}
+	if claims.EmailVerified {
+		now := time.Now()
+		user.VerifiedAt = &now
+	}
+	if _, err := service.UserRepository.Create(user); err != nil {
+		return nil, err
+	}
+	if !user.IsVerified() {
+		if err := service.SendVerification(ctx, user); err != nil {
+			log.Printf("failed to send verification to %s: %v", user.Email, err)
+		}
+	}
+	return user, nil
+}
+
+// claimUnverifiedUser передаёт владельцу email аккаунт, который никто не
+// подтверждал. Такой аккаунт мог заранее зарегистрировать кто угодно, поэтому
+// всё, что задал регистрировавший, сбрасывается: пароль, телефон, 2FA,
+// одноразовые токены (например, начатая смена email) и все сессии.
+func (service *AuthService) claimUnverifiedUser(ctx context.Context, user *users.User) error {
+	now := time.Now()
+	user.VerifiedAt = &now
+	user.Password = ""
+	user.Phone = nil
+	user.PhoneVerifiedAt = nil
+	if _, err := service.UserRepository.Update(user); err != nil {
+		return err
+	}
+	if err := service.TwoFactorRepository.DeleteForUser(ctx, user.ID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if err := service.UserTokenRepository.DeleteForUser(ctx, user.ID); err != nil {
+		return err
+	}
+	return service.revokeAllSessions(ctx, user)
+}
@@ -0,0 +1,227 @@
+package auth
+
+import (
+	"bike/configs"
+	"bike/internal/users"
+	"bike/pkg/db"
+	"bike/pkg/oidc"
+	"bike/pkg/oidc/oidctest"
+	"bike/pkg/rbac"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// Тесты входа через OIDC ходят в настоящий PostgreSQL: задайте TEST_DSN.
+func newOIDCTestService(t *testing.T) (*oidctest.Server, *AuthService, *db.Db) {
+	t.Helper()
+	dsn := os.Getenv("TEST_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DSN is not set")
+	}
+	gormDB, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	err = gormDB.AutoMigrate(&users.User{}, &Identity{}, &OIDCState{},
+		&RefreshToken{}, &Session{}, &TokenRevocation{}, &TwoFactor{}, &RecoveryCode{}, &UserToken{})
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	database := &db.Db{DB: gormDB}
+
+	srv := oidctest.NewServer("bike-app")
+	t.Cleanup(srv.Close)
+	provider := oidc.NewProvider(configs.OIDCProviderConfig{
+		Name:        "test",
+		Issuer:      srv.Issuer(),
+		ClientID:    "bike-app",
+		RedirectURL: "bike://oidc/callback",
+		Scopes:      []string{"openid", "email"},
+	}, srv.Client())
+
+	service := NewAuthService(AuthServiceDeps{
+		UserRepository:         users.NewUserRepository(database),
+		RefreshTokenRepository: NewRefreshTokenRepository(database),
+		UserTokenRepository:    NewUserTokenRepository(database),
+		TwoFactorRepository:    NewTwoFactorRepository(database),
+		IdentityRepository:     NewIdentityRepository(database),
+		SessionRepository:      NewSessionRepository(database),
+		RevocationStore:        NewRevocationStore(NewRevocationRepository(database)),
+		OIDCProviders:          oidc.Registry{provider.Name: provider},
+		Config: &configs.Config{
+			Auth: configs.AuthConfig{AccessTTL: time.Minute},
+			OIDC: configs.OIDCConfig{StateTTL: time.Minute},
+		},
+	})
+	return srv, service, database
+}
+
+// loginWithOIDC проходит весь вход: StartOIDC, страница провайдера, CompleteOIDC.
+func loginWithOIDC(t *testing.T, srv *oidctest.Server, service *AuthService, subject, email string, emailVerified bool) (*users.User, error) {
+	t.Helper()
+	ctx := context.Background()
+	start, err := service.StartOIDC(ctx, "test")
+	if err != nil {
+		t.Fatalf("StartOIDC: %v", err)
+	}
+	u, err := url.Parse(start.URL)
+	if err != nil {
+		t.Fatalf("parse %q: %v", start.URL, err)
+	}
+	claims := srv.Claims(subject, u.Query().Get("nonce"))
+	claims["email"] = email
+	claims["email_verified"] = emailVerified
+	code := srv.Authorize(u.Query().Get("code_challenge"), claims)
+	return service.CompleteOIDC(ctx, "test", code, start.State)
+}
+
+// createTestUser создаёт пользователя с паролем; verified — подтверждён ли email.
+func createTestUser(t *testing.T, database *db.Db, verified bool) *users.User {
+	t.Helper()
+	user := &users.User{
+		Email:    "oidc-" + strings.ToLower(rand.Text()) + "@example.com",
+		Password: "$2a$10$notarealhashnotarealhashnotarealhashnotarealhashnotar",
+		Name:     "Rider",
+		Role:     rbac.RoleCustomer,
+	}
+	if verified {
+		now := time.Now()
+		user.VerifiedAt = &now
+	}
+	if err := database.Create(user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	t.Cleanup(func() {
+		database.Unscoped().Where("user_id = ?", user.ID).Delete(&Identity{})
+		database.Unscoped().Where("user_id = ?", user.ID).Delete(&RefreshToken{})
+		database.Unscoped().Delete(user)
+	})
+	return user
+}
+
+func TestCompleteOIDCLinksVerifiedEmail(t *testing.T) {
+	srv, service, database := newOIDCTestService(t)
+	existing := createTestUser(t, database, true)
+	subject := rand.Text()
+
+	user, err := loginWithOIDC(t, srv, service, subject, existing.Email, true)
+	if err != nil {
+		t.Fatalf("CompleteOIDC: %v", err)
+	}
+	if user.ID != existing.ID {
+		t.Fatalf("logged in as user %d, want existing user %d", user.ID, existing.ID)
+	}
+	if !user.HasPassword() {
+		t.Error("password of a verified account must be kept")
+	}
+	identity, err := service.IdentityRepository.Find(context.Background(), "test", subject)
+	if err != nil {
+		t.Fatalf("identity not saved: %v", err)
+	}
+	if identity.UserID != existing.ID {
+		t.Errorf("identity linked to user %d, want %d", identity.UserID, existing.ID)
+	}
+
+	// Повторный вход находит пользователя по привязке, даже без подтверждённого email
+	again, err := loginWithOIDC(t, srv, service, subject, existing.Email, false)
+	if err != nil {
+		t.Fatalf("second CompleteOIDC: %v", err)
+	}
+	if again.ID != existing.ID {
+		t.Errorf("second login as user %d, want %d", again.ID, existing.ID)
+	}
+}
+
+// Аккаунт с чужим email, зарегистрированный заранее и не подтверждённый,
+// достаётся владельцу email без пароля, телефона и сессий регистрировавшего.
+func TestCompleteOIDCClaimsUnverifiedAccount(t *testing.T) {
+	srv, service, database := newOIDCTestService(t)
+	existing := createTestUser(t, database, false)
+	now := time.Now()
+	phone := fmt.Sprintf("+7999%07d", now.UnixNano()%10_000_000)
+	existing.Phone = &phone
+	existing.PhoneVerifiedAt = &now
+	if err := database.Save(existing).Error; err != nil {
+		t.Fatalf("save user: %v", err)
+	}
+	refresh := &RefreshToken{
+		UserID:    existing.ID,
+		FamilyID:  rand.Text()[:26],
+		TokenHash: hashToken(rand.Text()),
+		ExpiresAt: now.Add(time.Hour),
+	}
+	if err := database.Create(refresh).Error; err != nil {
+		t.Fatalf("create refresh token: %v", err)
+	}
+
+	user, err := loginWithOIDC(t, srv, service, rand.Text(), existing.Email, true)
+	if err != nil {
+		t.Fatalf("CompleteOIDC: %v", err)
+	}
+	if user.ID != existing.ID {
+		t.Fatalf("logged in as user %d, want existing user %d", user.ID, existing.ID)
+	}
+
+	var stored users.User
+	if err := database.First(&stored, existing.ID).Error; err != nil {
+		t.Fatalf("reload user: %v", err)
+	}
+	if !stored.IsVerified() {
+		t.Error("email confirmed by provider, user should become verified")
+	}
+	if stored.HasPassword() {
+		t.Error("password set by whoever registered the account must be cleared")
+	}
+	if stored.Phone != nil || stored.PhoneVerifiedAt != nil {
+		t.Errorf("phone = %v, verified at %v; want cleared", stored.Phone, stored.PhoneVerifiedAt)
+	}
+	if err := database.First(refresh, refresh.ID).Error; err != nil {
+		t.Fatalf("reload refresh token: %v", err)
+	}
+	if refresh.RevokedAt == nil {
+		t.Error("refresh tokens issued before linking must be revoked")
+	}
+}
+
+func TestCompleteOIDCDoesNotLinkUnverifiedEmail(t *testing.T) {
+	srv, service, database := newOIDCTestService(t)
+	existing := createTestUser(t, database, true)
+	subject := rand.Text()
+
+	_, err := loginWithOIDC(t, srv, service, subject, existing.Email, false)
+	if err == nil || err.Error() != ErrProviderUnverified {
+		t.Fatalf("CompleteOIDC error = %v, want %q", err, ErrProviderUnverified)
+	}
+	if _, err := service.IdentityRepository.Find(context.Background(), "test", subject); err == nil {
+		t.Error("identity must not be linked when provider did not verify email")
+	}
+}
+
+func TestCompleteOIDCCreatesUserWithoutPassword(t *testing.T) {
+	srv, service, database := newOIDCTestService(t)
+	email := "oidc-" + strings.ToLower(rand.Text()) + "@example.com"
+
+	user, err := loginWithOIDC(t, srv, service, rand.Text(), email, true)
+	if err != nil {
+		t.Fatalf("CompleteOIDC: %v", err)
+	}
+	t.Cleanup(func() {
+		database.Unscoped().Where("user_id = ?", user.ID).Delete(&Identity{})
+		database.Unscoped().Delete(user)
+	})
+	if user.Email != email || !user.IsVerified() {
+		t.Errorf("user = %+v", user)
+	}
+	if user.HasPassword() {
+		t.Error("user created via OIDC must not have a password")
+	}
+}
@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"bike/internal/users"
+	"bike/pkg/phone"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// RequestOTP отправляет одноразовый код входа на телефон. Чтобы по ответу
+// нельзя было узнать, зарегистрирован ли номер, код создаётся для любого
+// номера, а SMS уходит только владельцу аккаунта с подтверждённым телефоном.
+// Повторно запросить код можно не раньше, чем через OTP_RESEND_COOLDOWN.
+func (service *AuthService) RequestOTP(ctx context.Context, rawPhone string) error {
+	number, err := phone.Normalize(rawPhone)
+	if err != nil {
+		return errors.New(ErrInvalidPhone)
+	}
+	code, err := service.issuePhoneCode(ctx, number)
+	if err != nil {
+		return err
+	}
+
+	user, err := service.UserRepository.FindByPhone(number)
+	if err != nil || user.PhoneVerifiedAt == nil {
+		return nil
+	}
+	text := fmt.Sprintf("Код для входа: %s. Никому его не сообщайте.", code)
+	go service.sendSMS(number, text)
+	return nil
+}
+
+// VerifyOTP проверяет код из SMS и возвращает владельца номера. Войти можно
+// только по подтверждённому телефону (см. ConfirmPhone), иначе номер, указанный
+// в профиле без проверки, давал бы вход в аккаунт.
+func (service *AuthService) VerifyOTP(ctx context.Context, rawPhone, code string) (*users.User, error) {
+	number, err := phone.Normalize(rawPhone)
+	if err != nil {
+		return nil, errors.New(ErrInvalidPhone)
+	}
+	if err := service.checkPhoneCode(ctx, number, code); err != nil {
+		return nil, err
+	}
+	user, err := service.UserRepository.FindByPhone(number)
+	if err != nil || user.PhoneVerifiedAt == nil {
+		return nil, errors.New(ErrInvalidOTP)
+	}
+	return user, nil
+}
+
+// RequestPhoneVerification отправляет код подтверждения на телефон из профиля пользователя.
+func (service *AuthService) RequestPhoneVerification(ctx context.Context, userID uint) error {
+	user, err := service.UserRepository.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.Phone == nil {
+		return errors.New(ErrPhoneNotSet)
+	}
+	if user.PhoneVerifiedAt != nil {
+		return errors.New(ErrPhoneVerified)
+	}
+	code, err := service.issuePhoneCode(ctx, *user.Phone)
+	if err != nil {
+		return err
+	}
+	text := fmt.Sprintf("Код подтверждения телефона: %s. Никому его не сообщайте.", code)
+	go service.sendSMS(*user.Phone, text)
+	return nil
+}
+
+// ConfirmPhone проверяет код из SMS и отмечает телефон пользователя подтверждённым.
+func (service *AuthService) ConfirmPhone(ctx context.Context, userID uint, code string) (*users.User, error) {
+	user, err := service.UserRepository.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Phone == nil {
+		return nil, errors.New(ErrPhoneNotSet)
+	}
+	if user.PhoneVerifiedAt != nil {
+		return nil, errors.New(ErrPhoneVerified)
+	}
+	if err := service.checkPhoneCode(ctx, *user.Phone, code); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	user.PhoneVerifiedAt = &now
+	return service.UserRepository.Update(user)
+}
+
+// issuePhoneCode создаёт новый код для номера, отменяя прежние, и возвращает его.
+func (service *AuthService) issuePhoneCode(ctx context.Context, number string) (string, error) {
+	conf := service.config.OTP
+	latest, err := service.PhoneCodeRepository.Latest(ctx, number)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", err
+	}
+	if latest != nil {
+		if wait := conf.ResendCooldown - time.Since(latest.CreatedAt); wait > 0 {
+			return "", &TooManyAttemptsError{RetryAfter: wait}
+		}
+	}
+
+	code, err := newOTPCode()
+	if err != nil {
+		return "", err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	if err := service.PhoneCodeRepository.InvalidateForPhone(ctx, number); err != nil {
+		return "", err
+	}
+	err = service.PhoneCodeRepository.Create(ctx, &PhoneCode{
+		Phone:     number,
+		CodeHash:  string(hash),
+		ExpiresAt: time.Now().Add(conf.TTL),
+	})
+	if err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+// checkPhoneCode проверяет и гасит код для номера.
+// После OTP_MAX_ATTEMPTS неверных попыток код перестаёт действовать.
+func (service *AuthService) checkPhoneCode(ctx context.Context, number, code string) error {
+	stored, err := service.PhoneCodeRepository.FindActive(ctx, number)
+	if err != nil {
+		return errors.New(ErrInvalidOTP)
+	}
+	attempts, err := service.PhoneCodeRepository.IncrementAttempts(ctx, stored.ID)
+	if err != nil {
+		return err
+	}
+	if attempts > service.config.OTP.MaxAttempts {
+		service.PhoneCodeRepository.MarkUsed(ctx, stored.ID)
+		return errors.New(ErrInvalidOTP)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(stored.CodeHash), []byte(code)) != nil {
+		return errors.New(ErrInvalidOTP)
+	}
+	ok, err := service.PhoneCodeRepository.MarkUsed(ctx, stored.ID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New(ErrInvalidOTP)
+	}
+	return nil
+}
+
+func (service *AuthService) sendSMS(to, text string) {
+	if err := service.SMSSender.Send(context.Background(), to, text); err != nil {
+		log.Printf("failed to send sms to %s: %v", to, err)
+	}
+}
+
+// newOTPCode генерирует случайный 6-значный код.
+func newOTPCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"bike/pkg/mailer"
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+)
+
+// ForgotPassword отправляет письмо со ссылкой для сброса пароля.
+// Ответ не зависит от того, зарегистрирован ли email: письмо уходит
+// в фоне, так что и время ответа одинаковое.
+func (service *AuthService) ForgotPassword(ctx context.Context, email string) error {
+	user, err := service.UserRepository.FindByEmail(email)
+	if err != nil {
+		return nil
+	}
+	token, err := service.newUserToken(ctx, user.ID, PurposePasswordReset, "", service.config.Auth.PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Здравствуйте, %s!\n\nЧтобы задать новый пароль, используйте код:\n%s\n", user.Name, token)
+	if service.config.Auth.PasswordResetURL != "" {
+		body += fmt.Sprintf("\nили перейдите по ссылке:\n%s?token=%s\n", service.config.Auth.PasswordResetURL, url.QueryEscape(token))
+	}
+	body += fmt.Sprintf("\nСсылка действует %s. Если вы не запрашивали сброс пароля, просто проигнорируйте это письмо.\n",
+		service.config.Auth.PasswordResetTTL)
+
+	go service.sendMail(mailer.Message{
+		To:      user.Email,
+		Subject: "Сброс пароля",
+		Body:    body,
+	})
+	return nil
+}
+
+// ResetPassword меняет пароль по одноразовому токену и завершает все сессии пользователя.
+func (service *AuthService) ResetPassword(ctx context.Context, token, password string) error {
+	stored, err := service.useUserToken(ctx, token, PurposePasswordReset)
+	if err != nil {
+		return err
+	}
+	user, err := service.UserRepository.FindByID(stored.UserID)
+	if err != nil {
+		return err
+	}
+	if err := service.setPassword(user, password); err != nil {
+		return err
+	}
+	// Старый пароль мог быть скомпрометирован — выходим со всех устройств
+	return service.revokeAllSessions(ctx, user)
+}
+
+// sendMail отправляет письмо вне контекста запроса и только логирует ошибку.
+func (service *AuthService) sendMail(msg mailer.Message) {
+	if err := service.Mailer.Send(context.Background(), msg); err != nil {
+		log.Printf("failed to send mail to %s: %v", msg.To, err)
+	}
+}
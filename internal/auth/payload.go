@@ -1,20 +1,173 @@
 package auth
 
+import "time"
+
 type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email" example:"email@example.com"`
 	Password string `json:"password" validate:"required" example:"secret"`
+	// Название устройства для списка сессий (иначе берётся из заголовка X-Device-Name)
+	DeviceName string `json:"device_name,omitempty" validate:"max=128" example:"iPhone Ивана"`
+}
+
+// TokenResponse — пара токенов со сроками действия (RFC 3339).
+type TokenResponse struct {
+	Token            string `json:"token" example:"eyJhbGciOi..."`
+	ExpiresAt        string `json:"expires_at" example:"2025-10-07T12:15:00Z"`
+	RefreshToken     string `json:"refresh_token" example:"q7v1Jm0xk3..."`
+	RefreshExpiresAt string `json:"refresh_expires_at" example:"2025-11-06T12:00:00Z"`
 }
 
 type LoginResponse struct {
-	Token string `json:"token" example:"eyJhbGciOi..."`
+	TokenResponse
 }
 
 type RegisterRequest struct {
-	Name     string `json:"name" validate:"required" example:"Ivan"`
-	Email    string `json:"email" validate:"required,email" example:"email@example.com"`
-	Password string `json:"password" validate:"required" example:"secret"`
+	Name       string `json:"name" validate:"required" example:"Ivan"`
+	Email      string `json:"email" validate:"required,email" example:"email@example.com"`
+	Password   string `json:"password" validate:"required" example:"secret"`
+	Phone      string `json:"phone,omitempty" example:"+7 900 123-45-67"`
+	DeviceName string `json:"device_name,omitempty" validate:"max=128" example:"iPhone Ивана"`
 }
 
 type RegisterResponse struct {
-	Token string `json:"token" example:"eyJhbGciOi..."`
+	TokenResponse
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required" example:"q7v1Jm0xk3..."`
+}
+
+type RefreshResponse struct {
+	TokenResponse
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token,omitempty" example:"q7v1Jm0xk3..."`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email" example:"email@example.com"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required" example:"q7v1Jm0xk3..."`
+	Password string `json:"password" validate:"required,min=8" example:"new-secret"`
+}
+
+type VerifyResponse struct {
+	Email      string `json:"email" example:"email@example.com"`
+	VerifiedAt string `json:"verified_at" example:"2025-10-07T12:00:00Z"`
+}
+
+type UnlockRequest struct {
+	Email string `json:"email" validate:"required_without=IP,omitempty,email" example:"email@example.com"`
+	IP    string `json:"ip" validate:"required_without=Email,omitempty,ip" example:"203.0.113.7"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required" example:"secret"`
+	NewPassword     string `json:"new_password" validate:"required,min=8" example:"new-secret"`
+}
+
+type ChangePasswordResponse struct {
+	TokenResponse
+}
+
+type ChangeEmailRequest struct {
+	NewEmail        string `json:"new_email" validate:"required,email" example:"new@example.com"`
+	CurrentPassword string `json:"current_password" validate:"required" example:"secret"`
+}
+
+func toTokenResponse(p *TokenPair) TokenResponse {
+	return TokenResponse{
+		Token:            p.AccessToken,
+		ExpiresAt:        p.AccessExpiresAt.UTC().Format(time.RFC3339),
+		RefreshToken:     p.RefreshToken,
+		RefreshExpiresAt: p.RefreshExpiresAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// TwoFactorChallengeResponse — ответ /auth/login, если у пользователя включена 2FA.
+// Токены выдаются после POST /auth/2fa/verify.
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired bool   `json:"two_factor_required" example:"true"`
+	ChallengeToken    string `json:"challenge_token" example:"Zk3r9QpL..."`
+	ExpiresAt         string `json:"expires_at" example:"2025-10-07T12:05:00Z"`
+}
+
+type TwoFactorSetupResponse struct {
+	Secret string `json:"secret" example:"JBSWY3DPEHPK3PXP"`
+	URI    string `json:"otpauth_uri" example:"otpauth://totp/API-Bike:email@example.com?secret=JBSWY3DPEHPK3PXP&issuer=API-Bike"`
+}
+
+type TwoFactorConfirmRequest struct {
+	Code string `json:"code" validate:"required,numeric,len=6" example:"123456"`
+}
+
+type TwoFactorConfirmResponse struct {
+	RecoveryCodes []string `json:"recovery_codes" example:"k2f7q-x9m3a"`
+}
+
+type TwoFactorVerifyRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required" example:"Zk3r9QpL..."`
+	// 6-значный код из приложения или код восстановления
+	Code       string `json:"code" validate:"required" example:"123456"`
+	DeviceName string `json:"device_name,omitempty" validate:"max=128" example:"iPhone Ивана"`
+}
+
+type TwoFactorVerifyResponse struct {
+	TokenResponse
+}
+
+type OIDCProvidersResponse struct {
+	Providers []string `json:"providers" example:"google"`
+}
+
+type OIDCStartResponse struct {
+	AuthorizationURL string `json:"authorization_url" example:"https://accounts.google.com/o/oauth2/v2/auth?client_id=..."`
+	State            string `json:"state" example:"3JmQ0oV..."`
+	ExpiresAt        string `json:"expires_at" example:"2025-10-07T12:10:00Z"`
+}
+
+type OIDCCallbackRequest struct {
+	Code       string `json:"code" validate:"required" example:"4/0AX4XfWh..."`
+	State      string `json:"state" validate:"required" example:"3JmQ0oV..."`
+	DeviceName string `json:"device_name,omitempty" validate:"max=128" example:"iPhone Ивана"`
+}
+
+type SessionResponse struct {
+	ID         string `json:"id" example:"3f1c2a9e-5b7d-4c1e-9a0b-2d4e6f8a0c1e"`
+	DeviceName string `json:"device_name,omitempty" example:"iPhone Ивана"`
+	UserAgent  string `json:"user_agent,omitempty" example:"BikeApp/2.3 (iOS 18.1)"`
+	IP         string `json:"ip,omitempty" example:"203.0.113.7"`
+	CreatedAt  string `json:"created_at" example:"2025-10-07T12:00:00Z"`
+	LastSeenAt string `json:"last_seen_at" example:"2025-10-07T12:30:00Z"`
+	// Сессия, в которой выдан токен текущего запроса
+	Current bool `json:"current"`
+}
+
+func toSessionResponse(s *Session, currentID string) SessionResponse {
+	return SessionResponse{
+		ID:         s.ID,
+		DeviceName: s.DeviceName,
+		UserAgent:  s.UserAgent,
+		IP:         s.IP,
+		CreatedAt:  s.CreatedAt.UTC().Format(time.RFC3339),
+		LastSeenAt: s.LastSeenAt.UTC().Format(time.RFC3339),
+		Current:    s.ID == currentID,
+	}
+}
+
+type OTPRequest struct {
+	Phone string `json:"phone" validate:"required" example:"+7 900 123-45-67"`
+}
+
+type PhoneConfirmRequest struct {
+	Code string `json:"code" validate:"required,numeric,len=6" example:"123456"`
+}
+
+type OTPVerifyRequest struct {
+	Phone      string `json:"phone" validate:"required" example:"+7 900 123-45-67"`
+	Code       string `json:"code" validate:"required,numeric,len=6" example:"123456"`
+	DeviceName string `json:"device_name,omitempty" validate:"max=128" example:"iPhone Ивана"`
 }
@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"bike/internal/users"
+	"bike/pkg/db"
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// PersonalData — данные пользователя, которые хранит пакет auth.
+type PersonalData struct {
+	Sessions         []Session
+	Identities       []Identity
+	TwoFactorEnabled bool
+}
+
+// PersonalData собирает данные пользователя для выгрузки.
+func (service *AuthService) PersonalData(ctx context.Context, userID uint) (*PersonalData, error) {
+	sessions, err := service.SessionRepository.ListForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	identities, err := service.IdentityRepository.ListForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	enabled, err := service.twoFactorEnabled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &PersonalData{
+		Sessions:         sessions,
+		Identities:       identities,
+		TwoFactorEnabled: enabled,
+	}, nil
+}
+
+// ForgetUser отзывает все токены пользователя и удаляет связанные с ним
+// данные входа: сессии, привязанные аккаунты, 2FA, одноразовые токены, коды
+// из SMS и счётчики неудачных входов по email. Всё пишется в транзакции tx
+// вызывающего. Вызывается до анонимизации записи: счётчики и коды привязаны
+// к текущим email и телефону.
+func (service *AuthService) ForgetUser(ctx context.Context, tx *gorm.DB, user *users.User) error {
+	database := &db.Db{DB: tx}
+	if err := NewRefreshTokenRepository(database).RevokeAllForUser(ctx, user.ID); err != nil {
+		return err
+	}
+	if err := NewSessionRepository(database).DeleteForUser(ctx, user.ID); err != nil {
+		return err
+	}
+	if err := service.RevocationStore.RevokeSubjectTx(ctx, tx, user.ID, service.config.Auth.AccessTTL); err != nil {
+		return err
+	}
+	if err := NewIdentityRepository(database).DeleteForUser(ctx, user.ID); err != nil {
+		return err
+	}
+	if err := NewTwoFactorRepository(database).DeleteForUser(ctx, user.ID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if err := NewUserTokenRepository(database).DeleteForUser(ctx, user.ID); err != nil {
+		return err
+	}
+	if phone := user.PhoneNumber(); phone != "" {
+		if err := NewPhoneCodeRepository(database).DeleteForPhone(ctx, phone); err != nil {
+			return err
+		}
+	}
+	// Таблица login_attempts есть всегда; при LOCKOUT_STORE=memory она просто пуста
+	return NewPostgresAttemptStore(database).Reset(ctx, emailAttemptKey(user.Email))
+}
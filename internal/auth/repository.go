@@ -0,0 +1,415 @@
+package auth
+
+import (
+	"bike/pkg/db"
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type RefreshTokenRepository struct {
+	database *db.Db
+}
+
+func NewRefreshTokenRepository(database *db.Db) *RefreshTokenRepository {
+	return &RefreshTokenRepository{database: database}
+}
+
+func (repo *RefreshTokenRepository) Create(ctx context.Context, t *RefreshToken) (*RefreshToken, error) {
+	result := repo.database.DB.WithContext(ctx).Create(t)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return t, nil
+}
+
+func (repo *RefreshTokenRepository) FindByHash(ctx context.Context, hash string) (*RefreshToken, error) {
+	var t RefreshToken
+	result := repo.database.DB.WithContext(ctx).First(&t, "token_hash = ?", hash)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &t, nil
+}
+
+// MarkUsed помечает токен использованным. Возвращает false, если токен уже был
+// использован или отозван (например, параллельным запросом).
+func (repo *RefreshTokenRepository) MarkUsed(ctx context.Context, id uint) (bool, error) {
+	result := repo.database.DB.WithContext(ctx).Model(&RefreshToken{}).
+		Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// RevokeFamily отзывает все токены семейства.
+func (repo *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	return repo.database.DB.WithContext(ctx).Model(&RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeAllForUser отзывает все действующие refresh-токены пользователя.
+func (repo *RefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID uint) error {
+	return repo.database.DB.WithContext(ctx).Model(&RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+type RevocationRepository struct {
+	database *db.Db
+}
+
+func NewRevocationRepository(database *db.Db) *RevocationRepository {
+	return &RevocationRepository{database: database}
+}
+
+func (repo *RevocationRepository) Create(ctx context.Context, rev *TokenRevocation) error {
+	return repo.database.DB.WithContext(ctx).Create(rev).Error
+}
+
+// ListActive возвращает записи, которые ещё не истекли.
+func (repo *RevocationRepository) ListActive(ctx context.Context) ([]TokenRevocation, error) {
+	var list []TokenRevocation
+	result := repo.database.DB.WithContext(ctx).
+		Where("expires_at > ?", time.Now()).
+		Find(&list)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return list, nil
+}
+
+// DeleteExpired удаляет записи об отзыве токенов, которые уже истекли сами.
+func (repo *RevocationRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	result := repo.database.DB.WithContext(ctx).
+		Where("expires_at <= ?", time.Now()).
+		Delete(&TokenRevocation{})
+	return result.RowsAffected, result.Error
+}
+
+type UserTokenRepository struct {
+	database *db.Db
+}
+
+func NewUserTokenRepository(database *db.Db) *UserTokenRepository {
+	return &UserTokenRepository{database: database}
+}
+
+func (repo *UserTokenRepository) Create(ctx context.Context, t *UserToken) (*UserToken, error) {
+	result := repo.database.DB.WithContext(ctx).Create(t)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return t, nil
+}
+
+// FindActive ищет неиспользованный и неистёкший токен с данным назначением.
+func (repo *UserTokenRepository) FindActive(ctx context.Context, hash, purpose string) (*UserToken, error) {
+	var t UserToken
+	result := repo.database.DB.WithContext(ctx).
+		Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", hash, purpose, time.Now()).
+		First(&t)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &t, nil
+}
+
+// MarkUsed гасит токен. Возвращает false, если его уже использовали.
+func (repo *UserTokenRepository) MarkUsed(ctx context.Context, id uint) (bool, error) {
+	result := repo.database.DB.WithContext(ctx).Model(&UserToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// DeleteForUser удаляет все одноразовые токены пользователя (в Payload может быть email).
+func (repo *UserTokenRepository) DeleteForUser(ctx context.Context, userID uint) error {
+	return repo.database.DB.WithContext(ctx).Unscoped().Where("user_id = ?", userID).Delete(&UserToken{}).Error
+}
+
+// InvalidateForUser гасит все неиспользованные токены пользователя с данным назначением.
+func (repo *UserTokenRepository) InvalidateForUser(ctx context.Context, userID uint, purpose string) error {
+	return repo.database.DB.WithContext(ctx).Model(&UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", time.Now()).Error
+}
+
+type TwoFactorRepository struct {
+	database *db.Db
+}
+
+func NewTwoFactorRepository(database *db.Db) *TwoFactorRepository {
+	return &TwoFactorRepository{database: database}
+}
+
+func (repo *TwoFactorRepository) FindByUserID(ctx context.Context, userID uint) (*TwoFactor, error) {
+	var t TwoFactor
+	result := repo.database.DB.WithContext(ctx).Where("user_id = ?", userID).First(&t)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &t, nil
+}
+
+func (repo *TwoFactorRepository) Save(ctx context.Context, t *TwoFactor) error {
+	return repo.database.DB.WithContext(ctx).Save(t).Error
+}
+
+// AdvanceStep запоминает шаг принятого кода. Возвращает false, если
+// этот или более поздний код уже использовали.
+func (repo *TwoFactorRepository) AdvanceStep(ctx context.Context, id uint, step int64) (bool, error) {
+	result := repo.database.DB.WithContext(ctx).Model(&TwoFactor{}).
+		Where("id = ? AND last_step < ?", id, step).
+		Update("last_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// DeleteForUser удаляет TOTP-секрет и коды восстановления пользователя.
+func (repo *TwoFactorRepository) DeleteForUser(ctx context.Context, userID uint) error {
+	return repo.database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("user_id = ?", userID).Delete(&TwoFactor{}).Error
+	})
+}
+
+// ReplaceRecoveryCodes удаляет старые коды восстановления пользователя и сохраняет новые.
+func (repo *TwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID uint, hashes []string) error {
+	return repo.database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]RecoveryCode, 0, len(hashes))
+		for _, hash := range hashes {
+			codes = append(codes, RecoveryCode{UserID: userID, CodeHash: hash})
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// UseRecoveryCode гасит код восстановления. Возвращает false, если кода нет или он уже использован.
+func (repo *TwoFactorRepository) UseRecoveryCode(ctx context.Context, userID uint, hash string) (bool, error) {
+	result := repo.database.DB.WithContext(ctx).Model(&RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+type IdentityRepository struct {
+	database *db.Db
+}
+
+func NewIdentityRepository(database *db.Db) *IdentityRepository {
+	return &IdentityRepository{database: database}
+}
+
+func (repo *IdentityRepository) Find(ctx context.Context, provider, subject string) (*Identity, error) {
+	var identity Identity
+	result := repo.database.DB.WithContext(ctx).
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(&identity)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &identity, nil
+}
+
+func (repo *IdentityRepository) Save(ctx context.Context, identity *Identity) error {
+	return repo.database.DB.WithContext(ctx).Save(identity).Error
+}
+
+func (repo *IdentityRepository) ListForUser(ctx context.Context, userID uint) ([]Identity, error) {
+	var list []Identity
+	result := repo.database.DB.WithContext(ctx).Where("user_id = ?", userID).Order("created_at").Find(&list)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return list, nil
+}
+
+func (repo *IdentityRepository) DeleteForUser(ctx context.Context, userID uint) error {
+	return repo.database.DB.WithContext(ctx).Unscoped().Where("user_id = ?", userID).Delete(&Identity{}).Error
+}
+
+func (repo *IdentityRepository) CreateState(ctx context.Context, state *OIDCState) error {
+	return repo.database.DB.WithContext(ctx).Create(state).Error
+}
+
+// TakeState достаёт и удаляет state, чтобы его нельзя было использовать повторно.
+// Заодно удаляет истёкшие записи.
+func (repo *IdentityRepository) TakeState(ctx context.Context, hash string) (*OIDCState, error) {
+	var state OIDCState
+	err := repo.database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expires_at < ?", time.Now()).Delete(&OIDCState{}).Error; err != nil {
+			return err
+		}
+		result := tx.Clauses(clause.Returning{}).
+			Where("state_hash = ?", hash).
+			Delete(&state)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+type SessionRepository struct {
+	database *db.Db
+}
+
+func NewSessionRepository(database *db.Db) *SessionRepository {
+	return &SessionRepository{database: database}
+}
+
+func (repo *SessionRepository) Create(ctx context.Context, session *Session) error {
+	return repo.database.DB.WithContext(ctx).Create(session).Error
+}
+
+func (repo *SessionRepository) FindByID(ctx context.Context, id string) (*Session, error) {
+	var session Session
+	result := repo.database.DB.WithContext(ctx).Where("id = ?", id).First(&session)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &session, nil
+}
+
+// ListActive возвращает неотозванные сессии пользователя, активные после since.
+func (repo *SessionRepository) ListActive(ctx context.Context, userID uint, since time.Time) ([]Session, error) {
+	var list []Session
+	result := repo.database.DB.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND last_seen_at > ?", userID, since).
+		Order("last_seen_at desc").
+		Find(&list)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return list, nil
+}
+
+// ListForUser возвращает все сессии пользователя, включая завершённые.
+func (repo *SessionRepository) ListForUser(ctx context.Context, userID uint) ([]Session, error) {
+	var list []Session
+	result := repo.database.DB.WithContext(ctx).Where("user_id = ?", userID).Order("created_at desc").Find(&list)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return list, nil
+}
+
+func (repo *SessionRepository) DeleteForUser(ctx context.Context, userID uint) error {
+	return repo.database.DB.WithContext(ctx).Where("user_id = ?", userID).Delete(&Session{}).Error
+}
+
+func (repo *SessionRepository) Touch(ctx context.Context, id, ip string, at time.Time) error {
+	updates := map[string]interface{}{"last_seen_at": at}
+	if ip != "" {
+		updates["ip"] = ip
+	}
+	return repo.database.DB.WithContext(ctx).Model(&Session{}).
+		Where("id = ?", id).
+		UpdateColumns(updates).Error
+}
+
+func (repo *SessionRepository) Revoke(ctx context.Context, id string) error {
+	return repo.database.DB.WithContext(ctx).Model(&Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (repo *SessionRepository) RevokeAllForUser(ctx context.Context, userID uint) error {
+	return repo.database.DB.WithContext(ctx).Model(&Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+type PhoneCodeRepository struct {
+	database *db.Db
+}
+
+func NewPhoneCodeRepository(database *db.Db) *PhoneCodeRepository {
+	return &PhoneCodeRepository{database: database}
+}
+
+func (repo *PhoneCodeRepository) Create(ctx context.Context, code *PhoneCode) error {
+	return repo.database.DB.WithContext(ctx).Create(code).Error
+}
+
+// Latest возвращает последний выданный на номер код (в том числе использованный).
+func (repo *PhoneCodeRepository) Latest(ctx context.Context, phone string) (*PhoneCode, error) {
+	var code PhoneCode
+	result := repo.database.DB.WithContext(ctx).
+		Where("phone = ?", phone).
+		Order("created_at desc").
+		First(&code)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &code, nil
+}
+
+// FindActive возвращает действующий код для номера.
+func (repo *PhoneCodeRepository) FindActive(ctx context.Context, phone string) (*PhoneCode, error) {
+	var code PhoneCode
+	result := repo.database.DB.WithContext(ctx).
+		Where("phone = ? AND used_at IS NULL AND expires_at > ?", phone, time.Now()).
+		Order("created_at desc").
+		First(&code)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &code, nil
+}
+
+// IncrementAttempts атомарно увеличивает счётчик попыток и возвращает новое значение.
+func (repo *PhoneCodeRepository) IncrementAttempts(ctx context.Context, id uint) (int, error) {
+	var attempts int
+	result := repo.database.DB.WithContext(ctx).Raw(
+		`UPDATE phone_codes SET attempts = attempts + 1 WHERE id = ? RETURNING attempts`, id,
+	).Scan(&attempts)
+	return attempts, result.Error
+}
+
+// MarkUsed гасит код. Возвращает false, если его уже использовали.
+func (repo *PhoneCodeRepository) MarkUsed(ctx context.Context, id uint) (bool, error) {
+	result := repo.database.DB.WithContext(ctx).Model(&PhoneCode{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// InvalidateForPhone гасит все неиспользованные коды номера.
+func (repo *PhoneCodeRepository) InvalidateForPhone(ctx context.Context, phone string) error {
+	return repo.database.DB.WithContext(ctx).Model(&PhoneCode{}).
+		Where("phone = ? AND used_at IS NULL", phone).
+		Update("used_at", time.Now()).Error
+}
+
+func (repo *PhoneCodeRepository) DeleteForPhone(ctx context.Context, phone string) error {
+	return repo.database.DB.WithContext(ctx).Where("phone = ?", phone).Delete(&PhoneCode{}).Error
+}
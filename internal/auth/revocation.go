@@ -0,0 +1,177 @@
+package auth
+
+import (
+	"bike/pkg/db"
+	"bike/pkg/jwt"
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RevocationStore хранит отозванные access-токены в Postgres и держит их копию в памяти,
+// чтобы middleware.IsAuthenticated не ходил в базу на каждый запрос.
+// Кэш периодически перечитывается из базы — так до инстанса доходят отзывы,
+// сделанные другими инстансами.
+type RevocationStore struct {
+	repo *RevocationRepository
+
+	mu       sync.RWMutex
+	tokens   map[string]time.Time // jti -> exp
+	sessions map[string]time.Time // sid -> до когда хранить запись
+	subjects map[string]time.Time // sub (ID пользователя) -> отозваны токены, выданные раньше
+}
+
+func NewRevocationStore(repo *RevocationRepository) *RevocationStore {
+	return &RevocationStore{
+		repo:     repo,
+		tokens:   make(map[string]time.Time),
+		sessions: make(map[string]time.Time),
+		subjects: make(map[string]time.Time),
+	}
+}
+
+// RevokeToken отзывает один токен до момента его истечения.
+func (s *RevocationStore) RevokeToken(ctx context.Context, data *jwt.JWTData) error {
+	err := s.repo.Create(ctx, &TokenRevocation{
+		JTI:       data.ID,
+		ExpiresAt: data.ExpiresAt,
+	})
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.tokens[data.ID] = data.ExpiresAt
+	s.mu.Unlock()
+	return nil
+}
+
+// RevokeSession отзывает все токены сессии. ttl — максимальное время жизни
+// access-токена, после него запись не нужна.
+func (s *RevocationStore) RevokeSession(ctx context.Context, sessionID string, ttl time.Duration) error {
+	expiresAt := time.Now().Add(ttl)
+	err := s.repo.Create(ctx, &TokenRevocation{
+		SessionID: sessionID,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.sessions[sessionID] = expiresAt
+	s.mu.Unlock()
+	return nil
+}
+
+// RevokeSubject отзывает все токены пользователя, выданные до текущего момента.
+// ttl — максимальное время жизни таких токенов, после него запись не нужна.
+// Запись привязана к claim sub, а не к email: email в токене может устареть.
+func (s *RevocationStore) RevokeSubject(ctx context.Context, userID uint, ttl time.Duration) error {
+	return s.revokeSubject(ctx, s.repo, jwt.Subject(userID), ttl)
+}
+
+// RevokeSubjectTx — то же, что RevokeSubject, но запись в базу идёт в
+// транзакции tx. Кэш обновляется сразу: если транзакция откатится, лишняя
+// запись в нём доживёт только до следующего Load.
+func (s *RevocationStore) RevokeSubjectTx(ctx context.Context, tx *gorm.DB, userID uint, ttl time.Duration) error {
+	return s.revokeSubject(ctx, NewRevocationRepository(&db.Db{DB: tx}), jwt.Subject(userID), ttl)
+}
+
+func (s *RevocationStore) revokeSubject(ctx context.Context, repo *RevocationRepository, subject string, ttl time.Duration) error {
+	// Точность iat в токенах и timestamp в Postgres — микросекунда
+	now := time.Now().Truncate(time.Microsecond)
+	err := repo.Create(ctx, &TokenRevocation{
+		Subject:      subject,
+		IssuedBefore: now,
+		ExpiresAt:    now.Add(ttl),
+	})
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	if now.After(s.subjects[subject]) {
+		s.subjects[subject] = now
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// IsRevoked проверяет токен по кэшу в памяти.
+func (s *RevocationStore) IsRevoked(data *jwt.JWTData) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, ok := s.tokens[data.ID]; ok {
+		return true
+	}
+	if data.SessionID != "" {
+		if _, ok := s.sessions[data.SessionID]; ok {
+			return true
+		}
+	}
+	if s.subjectRevoked(data.UserID, data.IssuedAt) {
+		return true
+	}
+	// Токен имперсонации отзывается и вместе со всеми токенами администратора
+	return data.Actor != nil && s.subjectRevoked(data.Actor.UserID, data.IssuedAt)
+}
+
+// subjectRevoked сообщает, отозваны ли токены пользователя, выданные в issuedAt.
+// Вызывается под s.mu.
+func (s *RevocationStore) subjectRevoked(userID uint, issuedAt time.Time) bool {
+	before, ok := s.subjects[jwt.Subject(userID)]
+	// Токен, выданный в ту же микросекунду, что и отзыв, тоже отозван:
+	// новые токены выдаются только после записи отзыва в базу
+	return ok && !issuedAt.After(before)
+}
+
+// Load удаляет истёкшие записи из базы и перечитывает кэш.
+func (s *RevocationStore) Load(ctx context.Context) error {
+	if n, err := s.repo.DeleteExpired(ctx); err != nil {
+		return err
+	} else if n > 0 {
+		log.Printf("revocation sweep: removed %d expired entries", n)
+	}
+	list, err := s.repo.ListActive(ctx)
+	if err != nil {
+		return err
+	}
+	tokens := make(map[string]time.Time, len(list))
+	sessions := make(map[string]time.Time)
+	subjects := make(map[string]time.Time)
+	for _, rev := range list {
+		if rev.JTI != "" {
+			tokens[rev.JTI] = rev.ExpiresAt
+		}
+		if rev.SessionID != "" {
+			sessions[rev.SessionID] = rev.ExpiresAt
+		}
+		if rev.Subject != "" && rev.IssuedBefore.After(subjects[rev.Subject]) {
+			subjects[rev.Subject] = rev.IssuedBefore
+		}
+	}
+	s.mu.Lock()
+	s.tokens = tokens
+	s.sessions = sessions
+	s.subjects = subjects
+	s.mu.Unlock()
+	return nil
+}
+
+// Run каждые interval чистит истёкшие записи и обновляет кэш.
+// Блокируется до отмены ctx.
+func (s *RevocationStore) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Load(ctx); err != nil {
+				log.Printf("revocation store: reload failed: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"bike/pkg/jwt"
+	"testing"
+	"time"
+)
+
+// Отзыв всех токенов пользователя не должен терять секунду, в которую он сделан,
+// и распространяется на токены имперсонации, выданные этим администратором.
+func TestIsRevokedSubjectWithinSameSecond(t *testing.T) {
+	tokens := jwt.NewJWT("test-secret")
+	cutoff := time.Now().Truncate(time.Second).Add(500 * time.Millisecond)
+	store := NewRevocationStore(nil)
+	store.subjects[jwt.Subject(7)] = cutoff
+
+	issue := func(userID, actorID uint, at time.Time) *jwt.JWTData {
+		t.Helper()
+		claims := jwt.JWTData{
+			UserID:    userID,
+			Email:     "rider@example.com",
+			IssuedAt:  at,
+			ExpiresAt: at.Add(time.Hour),
+		}
+		if actorID != 0 {
+			claims.Actor = &jwt.Actor{UserID: actorID, Email: "admin@example.com"}
+		}
+		raw, err := tokens.GenerateToken(claims)
+		if err != nil {
+			t.Fatalf("GenerateToken: %v", err)
+		}
+		ok, data := tokens.ParseToken(raw)
+		if !ok {
+			t.Fatal("ParseToken: token rejected")
+		}
+		return data
+	}
+
+	tests := []struct {
+		name     string
+		userID   uint
+		actorID  uint
+		issuedAt time.Time
+		revoked  bool
+	}{
+		{"earlier second", 7, 0, cutoff.Add(-time.Second), true},
+		{"same second, before revocation", 7, 0, cutoff.Add(-time.Millisecond), true},
+		{"at revocation", 7, 0, cutoff, true},
+		{"same second, after revocation", 7, 0, cutoff.Add(time.Millisecond), false},
+		{"other user", 8, 0, cutoff.Add(-time.Second), false},
+		{"impersonated by revoked admin", 8, 7, cutoff.Add(-time.Millisecond), true},
+		{"impersonation after admin revocation", 8, 7, cutoff.Add(time.Millisecond), false},
+		{"impersonated by other admin", 8, 9, cutoff.Add(-time.Second), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := issue(tt.userID, tt.actorID, tt.issuedAt)
+			if got := store.IsRevoked(data); got != tt.revoked {
+				t.Errorf("IsRevoked = %v, want %v", got, tt.revoked)
+			}
+		})
+	}
+}
@@ -1,49 +1,147 @@
 package auth
 
 import (
+	"bike/configs"
 	"bike/internal/users"
+	"bike/pkg/jwt"
+	"bike/pkg/mailer"
+	"bike/pkg/oidc"
+	"bike/pkg/phone"
+	"bike/pkg/rbac"
+	"bike/pkg/sms"
+	"context"
 	"errors"
 	"golang.org/x/crypto/bcrypt"
+	"log"
 )
 
+type AuthServiceDeps struct {
+	UserRepository         *users.UserRepository
+	RefreshTokenRepository *RefreshTokenRepository
+	UserTokenRepository    *UserTokenRepository
+	TwoFactorRepository    *TwoFactorRepository
+	IdentityRepository     *IdentityRepository
+	SessionRepository      *SessionRepository
+	PhoneCodeRepository    *PhoneCodeRepository
+	RevocationStore        *RevocationStore
+	LoginLimiter           *LoginLimiter
+	Mailer                 mailer.Mailer
+	SMSSender              sms.SMSSender
+	OIDCProviders          oidc.Registry
+	JWT                    *jwt.JWT
+	Config                 *configs.Config
+}
+
 type AuthService struct {
-	UserRepository *users.UserRepository
+	UserRepository         *users.UserRepository
+	RefreshTokenRepository *RefreshTokenRepository
+	UserTokenRepository    *UserTokenRepository
+	TwoFactorRepository    *TwoFactorRepository
+	IdentityRepository     *IdentityRepository
+	SessionRepository      *SessionRepository
+	PhoneCodeRepository    *PhoneCodeRepository
+	RevocationStore        *RevocationStore
+	LoginLimiter           *LoginLimiter
+	Mailer                 mailer.Mailer
+	SMSSender              sms.SMSSender
+	OIDCProviders          oidc.Registry
+	config                 *configs.Config
+	jwt                    *jwt.JWT
 }
 
-func NewAuthService(userRepository *users.UserRepository) *AuthService {
-	return &AuthService{UserRepository: userRepository}
+func NewAuthService(deps AuthServiceDeps) *AuthService {
+	return &AuthService{
+		UserRepository:         deps.UserRepository,
+		RefreshTokenRepository: deps.RefreshTokenRepository,
+		UserTokenRepository:    deps.UserTokenRepository,
+		TwoFactorRepository:    deps.TwoFactorRepository,
+		IdentityRepository:     deps.IdentityRepository,
+		SessionRepository:      deps.SessionRepository,
+		PhoneCodeRepository:    deps.PhoneCodeRepository,
+		RevocationStore:        deps.RevocationStore,
+		LoginLimiter:           deps.LoginLimiter,
+		Mailer:                 deps.Mailer,
+		SMSSender:              deps.SMSSender,
+		OIDCProviders:          deps.OIDCProviders,
+		config:                 deps.Config,
+		jwt:                    deps.JWT,
+	}
 }
 
-func (service *AuthService) Login(email, password string) (string, error) {
+// Login проверяет email и пароль. Неудачные попытки учитываются по email и IP;
+// при превышении лимита возвращается *TooManyAttemptsError без проверки пароля.
+// Если у пользователя включена 2FA, счётчик неудач сбрасывается только
+// после второго шага (VerifyTwoFactor).
+func (service *AuthService) Login(ctx context.Context, email, password, ip string) (*users.User, error) {
+	if err := service.LoginLimiter.Check(ctx, email, ip); err != nil {
+		return nil, err
+	}
 	existedUser, _ := service.UserRepository.FindByEmail(email)
 	if existedUser == nil {
-		return "", errors.New(ErrWrongCredentials)
+		service.LoginLimiter.Fail(ctx, email, ip)
+		return nil, errors.New(ErrWrongCredentials)
+	}
+	// Пустой хэш — пользователь без пароля (создан при входе через OIDC)
+	if !existedUser.HasPassword() || bcrypt.CompareHashAndPassword([]byte(existedUser.Password), []byte(password)) != nil {
+		service.LoginLimiter.Fail(ctx, email, ip)
+		return nil, errors.New(ErrWrongCredentials)
+	}
+	// Проверяем после пароля, чтобы не раскрывать статус аккаунта без него
+	if existedUser.IsSuspended() {
+		return nil, errors.New(ErrUserSuspended)
 	}
-	err := bcrypt.CompareHashAndPassword([]byte(existedUser.Password), []byte(password))
+	enabled, err := service.twoFactorEnabled(ctx, existedUser.ID)
 	if err != nil {
-		return "", errors.New(ErrWrongCredentials)
+		return nil, err
 	}
-	return existedUser.Email, nil
+	if !enabled {
+		service.LoginLimiter.Succeed(ctx, email)
+	}
+	return existedUser, nil
+}
+
+// Unlock снимает блокировку входа с email и/или IP.
+func (service *AuthService) Unlock(ctx context.Context, email, ip string) error {
+	return service.LoginLimiter.Unlock(ctx, email, ip)
 }
 
-func (service *AuthService) Register(email, password, name string) (string, error) {
+// Register создаёт пользователя. Телефон необязателен; по нему потом можно
+// входить без пароля (RequestOTP / VerifyOTP).
+func (service *AuthService) Register(ctx context.Context, email, password, name, rawPhone string) (*users.User, error) {
 	existedUser, _ := service.UserRepository.FindByEmail(email)
 	if existedUser != nil {
-		return "", errors.New(ErrUserAlreadyExists)
+		return nil, errors.New(ErrUserAlreadyExists)
+	}
+	var userPhone *string
+	if rawPhone != "" {
+		number, err := phone.Normalize(rawPhone)
+		if err != nil {
+			return nil, errors.New(ErrInvalidPhone)
+		}
+		if taken, _ := service.UserRepository.FindByPhone(number); taken != nil {
+			return nil, errors.New(ErrPhoneTaken)
+		}
+		userPhone = &number
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	user := &users.User{
 		Email:    email,
 		Password: string(hashedPassword),
 		Name:     name,
+		Role:     rbac.RoleCustomer,
+		Phone:    userPhone,
 	}
 	_, err = service.UserRepository.Create(user)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	// Аккаунт уже создан: если письмо не ушло, его можно запросить повторно
+	if err := service.SendVerification(ctx, user); err != nil {
+		log.Printf("failed to send verification to %s: %v", user.Email, err)
 	}
-	return user.Email, nil
+	return user, nil
 }
@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"bike/pkg/jwt"
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Не чаще, чем раз в этот интервал, обновляем last_seen_at одной сессии
+const sessionTouchResolution = time.Minute
+
+// ClientInfo — устройство, с которого выполняется вход.
+type ClientInfo struct {
+	DeviceName string
+	UserAgent  string
+	IP         string
+}
+
+// ListSessions возвращает активные сессии пользователя. Сессии, которые не
+// использовались дольше срока жизни refresh-токена, считаются завершёнными.
+func (service *AuthService) ListSessions(ctx context.Context, userID uint) ([]Session, error) {
+	since := time.Now().Add(-service.config.Auth.RefreshTTL)
+	return service.SessionRepository.ListActive(ctx, userID, since)
+}
+
+// RevokeSession завершает сессию пользователя на другом (или текущем) устройстве.
+func (service *AuthService) RevokeSession(ctx context.Context, userID uint, sessionID string) error {
+	session, err := service.SessionRepository.FindByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New(ErrSessionNotFound)
+		}
+		return err
+	}
+	if session.UserID != userID || session.RevokedAt != nil {
+		return errors.New(ErrSessionNotFound)
+	}
+	return service.revokeSession(ctx, session.ID)
+}
+
+// revokeSession отзывает refresh-токены сессии и её ещё не истёкшие access-токены.
+func (service *AuthService) revokeSession(ctx context.Context, sessionID string) error {
+	if err := service.SessionRepository.Revoke(ctx, sessionID); err != nil {
+		return err
+	}
+	if err := service.RefreshTokenRepository.RevokeFamily(ctx, sessionID); err != nil {
+		return err
+	}
+	return service.RevocationStore.RevokeSession(ctx, sessionID, service.config.Auth.AccessTTL)
+}
+
+// SessionTracker обновляет last_seen_at сессий для middleware.IsAuthenticated.
+// Запись в базу идёт в фоне и не чаще раза в sessionTouchResolution на сессию.
+type SessionTracker struct {
+	repo *SessionRepository
+
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+func NewSessionTracker(repo *SessionRepository) *SessionTracker {
+	return &SessionTracker{
+		repo: repo,
+		seen: make(map[string]time.Time),
+	}
+}
+
+func (t *SessionTracker) TouchSession(data *jwt.JWTData, ip string) {
+	now := time.Now()
+	t.mu.Lock()
+	if last, ok := t.seen[data.SessionID]; ok && now.Sub(last) < sessionTouchResolution {
+		t.mu.Unlock()
+		return
+	}
+	t.seen[data.SessionID] = now
+	t.prune(now)
+	t.mu.Unlock()
+
+	go func() {
+		if err := t.repo.Touch(context.Background(), data.SessionID, ip, now); err != nil {
+			log.Printf("failed to update session %s: %v", data.SessionID, err)
+		}
+	}()
+}
+
+// prune удаляет устаревшие отметки, чтобы карта не росла бесконечно.
+// Вызывается под t.mu.
+func (t *SessionTracker) prune(now time.Time) {
+	if len(t.seen) < 10000 {
+		return
+	}
+	for id, last := range t.seen {
+		if now.Sub(last) >= sessionTouchResolution {
+			delete(t.seen, id)
+		}
+	}
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	// Не разрезаем многобайтовый символ
+	for n > 0 && n < len(s) && s[n]&0xC0 == 0x80 {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,216 @@
+package auth
+
+import (
+	"bike/internal/users"
+	"bike/pkg/jwt"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TokenPair — access-токен и refresh-токен, выдаваемые после входа.
+type TokenPair struct {
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
+
+// IssueTokens открывает новую сессию на устройстве client и выдаёт пару токенов.
+// ID сессии служит и FamilyID для refresh-токенов.
+func (service *AuthService) IssueTokens(ctx context.Context, user *users.User, client ClientInfo) (*TokenPair, error) {
+	if user.IsSuspended() {
+		return nil, errors.New(ErrUserSuspended)
+	}
+	now := time.Now()
+	session := &Session{
+		ID:         uuid.NewString(),
+		UserID:     user.ID,
+		DeviceName: truncate(client.DeviceName, 128),
+		UserAgent:  truncate(client.UserAgent, 512),
+		IP:         truncate(client.IP, 64),
+		LastSeenAt: now,
+	}
+	if err := service.SessionRepository.Create(ctx, session); err != nil {
+		return nil, err
+	}
+	return service.issueTokens(ctx, user, session.ID)
+}
+
+// Refresh обменивает refresh-токен на новую пару (ротация).
+// Повторное предъявление уже использованного токена считается кражей:
+// всё семейство отзывается, и пользователю придётся войти заново.
+func (service *AuthService) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	stored, err := service.RefreshTokenRepository.FindByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		return nil, errors.New(ErrInvalidRefreshToken)
+	}
+	if stored.UsedAt != nil || stored.RevokedAt != nil {
+		service.revokeFamily(ctx, stored)
+		return nil, errors.New(ErrInvalidRefreshToken)
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, errors.New(ErrInvalidRefreshToken)
+	}
+
+	ok, err := service.RefreshTokenRepository.MarkUsed(ctx, stored.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// Токен успели использовать параллельно — тоже повторное использование
+		service.revokeFamily(ctx, stored)
+		return nil, errors.New(ErrInvalidRefreshToken)
+	}
+
+	user, err := service.UserRepository.FindByID(stored.UserID)
+	if err != nil {
+		return nil, errors.New(ErrInvalidRefreshToken)
+	}
+	if err := service.SessionRepository.Touch(ctx, stored.FamilyID, "", time.Now()); err != nil {
+		log.Printf("failed to update session %s: %v", stored.FamilyID, err)
+	}
+	return service.issueTokens(ctx, user, stored.FamilyID)
+}
+
+// Logout завершает текущую сессию: отзывает её access- и refresh-токены.
+// Для токенов без sid отзывается текущий access-токен и, если передан,
+// refresh-токен той же сессии.
+func (service *AuthService) Logout(ctx context.Context, data *jwt.JWTData, refreshToken string) error {
+	if data.SessionID != "" {
+		return service.revokeSession(ctx, data.SessionID)
+	}
+	if err := service.RevocationStore.RevokeToken(ctx, data); err != nil {
+		return err
+	}
+	if refreshToken == "" {
+		return nil
+	}
+	stored, err := service.RefreshTokenRepository.FindByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		// Неизвестный refresh-токен — отзывать нечего
+		return nil
+	}
+	if stored.UserID != data.UserID {
+		return nil
+	}
+	return service.RefreshTokenRepository.RevokeFamily(ctx, stored.FamilyID)
+}
+
+// LogoutAll отзывает все access- и refresh-токены пользователя.
+func (service *AuthService) LogoutAll(ctx context.Context, data *jwt.JWTData) error {
+	user, err := service.UserRepository.FindByID(data.UserID)
+	if err != nil {
+		return err
+	}
+	return service.revokeAllSessions(ctx, user)
+}
+
+func (service *AuthService) revokeFamily(ctx context.Context, t *RefreshToken) {
+	log.Printf("refresh token reuse detected: user_id=%d family=%s", t.UserID, t.FamilyID)
+	if err := service.revokeSession(ctx, t.FamilyID); err != nil {
+		log.Printf("failed to revoke session %s: %v", t.FamilyID, err)
+	}
+}
+
+// issueTokens — единая точка выдачи токенов для всех способов входа и refresh,
+// поэтому блокировка аккаунта проверяется здесь.
+func (service *AuthService) issueTokens(ctx context.Context, user *users.User, familyID string) (*TokenPair, error) {
+	if user.IsSuspended() {
+		return nil, errors.New(ErrUserSuspended)
+	}
+	now := time.Now()
+	accessExp := now.Add(service.config.Auth.AccessTTL)
+	access, err := service.jwt.GenerateToken(jwt.JWTData{
+		UserID:    user.ID,
+		Email:     user.Email,
+		Role:      string(user.Role),
+		SessionID: familyID,
+		IssuedAt:  now,
+		ExpiresAt: accessExp,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	refresh, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	refreshExp := now.Add(service.config.Auth.RefreshTTL)
+	_, err = service.RefreshTokenRepository.Create(ctx, &RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(refresh),
+		ExpiresAt: refreshExp,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:      access,
+		AccessExpiresAt:  accessExp,
+		RefreshToken:     refresh,
+		RefreshExpiresAt: refreshExp,
+	}, nil
+}
+
+// newOpaqueToken генерирует случайный токен (256 бит) в base64url.
+func newOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// newUserToken создаёт одноразовый токен с назначением purpose, гася выданные ранее.
+func (service *AuthService) newUserToken(ctx context.Context, userID uint, purpose, payload string, ttl time.Duration) (string, error) {
+	if err := service.UserTokenRepository.InvalidateForUser(ctx, userID, purpose); err != nil {
+		return "", err
+	}
+	token, err := newOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	_, err = service.UserTokenRepository.Create(ctx, &UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hashToken(token),
+		Payload:   payload,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// useUserToken проверяет и гасит одноразовый токен.
+func (service *AuthService) useUserToken(ctx context.Context, token, purpose string) (*UserToken, error) {
+	stored, err := service.UserTokenRepository.FindActive(ctx, hashToken(token), purpose)
+	if err != nil {
+		return nil, errors.New(ErrInvalidToken)
+	}
+	ok, err := service.UserTokenRepository.MarkUsed(ctx, stored.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New(ErrInvalidToken)
+	}
+	return stored, nil
+}
@@ -0,0 +1,196 @@
+package auth
+
+import (
+	"bike/internal/users"
+	"bike/pkg/totp"
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const recoveryCodeCount = 10
+
+// TwoFactorSetup — данные для добавления аккаунта в приложение-аутентификатор.
+type TwoFactorSetup struct {
+	Secret string
+	URI    string
+}
+
+// TwoFactorChallenge — токен второго шага входа.
+type TwoFactorChallenge struct {
+	Token     string
+	ExpiresAt time.Time
+}
+
+// SetupTwoFactor выпускает новый TOTP-секрет. 2FA включается только после
+// ConfirmTwoFactor, до этого секрет можно перевыпустить.
+func (service *AuthService) SetupTwoFactor(ctx context.Context, userID uint) (*TwoFactorSetup, error) {
+	user, err := service.UserRepository.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	tf, err := service.TwoFactorRepository.FindByUserID(ctx, user.ID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		tf = &TwoFactor{UserID: user.ID}
+	}
+	if tf.Enabled() {
+		return nil, errors.New(ErrTwoFactorEnabled)
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	tf.Secret = secret
+	tf.LastStep = 0
+	if err := service.TwoFactorRepository.Save(ctx, tf); err != nil {
+		return nil, err
+	}
+	return &TwoFactorSetup{
+		Secret: secret,
+		URI:    totp.URI(service.config.Auth.TwoFactorIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTwoFactor включает 2FA по первому коду из приложения и возвращает
+// коды восстановления — они показываются пользователю один раз.
+func (service *AuthService) ConfirmTwoFactor(ctx context.Context, userID uint, code string) ([]string, error) {
+	user, err := service.UserRepository.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	tf, err := service.TwoFactorRepository.FindByUserID(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(ErrTwoFactorNotSetUp)
+		}
+		return nil, err
+	}
+	if tf.Enabled() {
+		return nil, errors.New(ErrTwoFactorEnabled)
+	}
+	step, ok := totp.Validate(tf.Secret, code, time.Now())
+	if !ok {
+		return nil, errors.New(ErrInvalidTwoFactor)
+	}
+	now := time.Now()
+	tf.ConfirmedAt = &now
+	tf.LastStep = step
+	if err := service.TwoFactorRepository.Save(ctx, tf); err != nil {
+		return nil, err
+	}
+	return service.newRecoveryCodes(ctx, user.ID)
+}
+
+// StartTwoFactor выдаёт токен второго шага, если у пользователя включена 2FA.
+// Если 2FA не включена, возвращает nil.
+func (service *AuthService) StartTwoFactor(ctx context.Context, user *users.User) (*TwoFactorChallenge, error) {
+	enabled, err := service.twoFactorEnabled(ctx, user.ID)
+	if err != nil || !enabled {
+		return nil, err
+	}
+	ttl := service.config.Auth.TwoFactorChallengeTTL
+	token, err := service.newUserToken(ctx, user.ID, PurposeTwoFactorLogin, "", ttl)
+	if err != nil {
+		return nil, err
+	}
+	return &TwoFactorChallenge{Token: token, ExpiresAt: time.Now().Add(ttl)}, nil
+}
+
+// VerifyTwoFactor завершает вход: проверяет TOTP-код или код восстановления
+// и обменивает токен второго шага на пару токенов. Неверные коды учитываются
+// тем же LoginLimiter, что и неверные пароли.
+func (service *AuthService) VerifyTwoFactor(ctx context.Context, challenge, code string, client ClientInfo) (*TokenPair, error) {
+	ip := client.IP
+	stored, err := service.UserTokenRepository.FindActive(ctx, hashToken(challenge), PurposeTwoFactorLogin)
+	if err != nil {
+		return nil, errors.New(ErrInvalidToken)
+	}
+	user, err := service.UserRepository.FindByID(stored.UserID)
+	if err != nil {
+		return nil, errors.New(ErrInvalidToken)
+	}
+	if err := service.LoginLimiter.Check(ctx, user.Email, ip); err != nil {
+		return nil, err
+	}
+	tf, err := service.TwoFactorRepository.FindByUserID(ctx, user.ID)
+	if err != nil || !tf.Enabled() {
+		return nil, errors.New(ErrInvalidToken)
+	}
+	ok, err := service.checkSecondFactor(ctx, tf, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		service.LoginLimiter.Fail(ctx, user.Email, ip)
+		return nil, errors.New(ErrInvalidTwoFactor)
+	}
+	used, err := service.UserTokenRepository.MarkUsed(ctx, stored.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		return nil, errors.New(ErrInvalidToken)
+	}
+	service.LoginLimiter.Succeed(ctx, user.Email)
+	return service.IssueTokens(ctx, user, client)
+}
+
+// checkSecondFactor принимает 6-значный TOTP-код (каждый не более одного раза)
+// или неиспользованный код восстановления.
+func (service *AuthService) checkSecondFactor(ctx context.Context, tf *TwoFactor, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(tf.Secret, code, time.Now())
+		if !ok {
+			return false, nil
+		}
+		return service.TwoFactorRepository.AdvanceStep(ctx, tf.ID, step)
+	}
+	return service.TwoFactorRepository.UseRecoveryCode(ctx, tf.UserID, hashToken(normalizeRecoveryCode(code)))
+}
+
+func (service *AuthService) twoFactorEnabled(ctx context.Context, userID uint) (bool, error) {
+	tf, err := service.TwoFactorRepository.FindByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return tf.Enabled(), nil
+}
+
+// newRecoveryCodes заменяет коды восстановления пользователя новыми
+// вида xxxxx-xxxxx. В базе хранятся только хэши.
+func (service *AuthService) newRecoveryCodes(ctx context.Context, userID uint) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		raw := strings.ToLower(encoding.EncodeToString(b))[:10]
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+		hashes = append(hashes, hashToken(raw))
+	}
+	if err := service.TwoFactorRepository.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
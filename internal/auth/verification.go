@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"bike/internal/users"
+	"bike/pkg/mailer"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// SendVerification отправляет письмо со ссылкой подтверждения email.
+func (service *AuthService) SendVerification(ctx context.Context, user *users.User) error {
+	if user.IsVerified() {
+		return errors.New(ErrAlreadyVerified)
+	}
+	token, err := service.newUserToken(ctx, user.ID, PurposeEmailVerification, "", service.config.Auth.EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+	link := fmt.Sprintf("%s/auth/verify?token=%s", service.config.App.BaseURL, url.QueryEscape(token))
+	go service.sendMail(mailer.Message{
+		To:      user.Email,
+		Subject: "Подтверждение email",
+		Body: fmt.Sprintf("Здравствуйте, %s!\n\nПодтвердите адрес электронной почты, перейдя по ссылке:\n%s\n\nСсылка действует %s.\n",
+			user.Name, link, service.config.Auth.EmailVerificationTTL),
+	})
+	return nil
+}
+
+// ResendVerification повторно отправляет письмо подтверждения текущему пользователю.
+func (service *AuthService) ResendVerification(ctx context.Context, userID uint) error {
+	user, err := service.UserRepository.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	return service.SendVerification(ctx, user)
+}
+
+// VerifyEmail подтверждает email по одноразовому токену из письма.
+// Тот же токен подтверждает и смену email (см. RequestEmailChange).
+func (service *AuthService) VerifyEmail(ctx context.Context, token string) (*users.User, error) {
+	stored, err := service.useUserToken(ctx, token, PurposeEmailVerification)
+	if err != nil {
+		if change, changeErr := service.useUserToken(ctx, token, PurposeEmailChange); changeErr == nil {
+			return service.confirmEmailChange(ctx, change)
+		}
+		return nil, err
+	}
+	user, err := service.UserRepository.FindByID(stored.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsVerified() {
+		return user, nil
+	}
+	now := time.Now()
+	user.VerifiedAt = &now
+	return service.UserRepository.Update(user)
+}
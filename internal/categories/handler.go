@@ -0,0 +1,196 @@
+package categories
+
+import (
+	"bike/pkg/middleware"
+	"bike/pkg/rbac"
+	"bike/pkg/req"
+	"bike/pkg/res"
+	"errors"
+	"net/http"
+)
+
+type CategoryHandlerDeps struct {
+	CategoryService CategoryService
+	Auth            *middleware.AuthDeps
+}
+
+type CategoryHandler struct {
+	service CategoryService
+}
+
+func NewCategoryHandler(router *http.ServeMux, deps CategoryHandlerDeps) {
+	handler := &CategoryHandler{
+		service: deps.CategoryService,
+	}
+	router.HandleFunc("GET /categories", handler.Tree())
+	router.HandleFunc("GET /categories/{slug}", handler.Get())
+
+	// Управление категориями — право products:write, как и для каталога
+	router.Handle("GET /categories/all", middleware.IsAuthenticatedOrAPIKey(middleware.RequirePermission(handler.TreeAll(), rbac.PermProductsWrite), deps.Auth))
+	router.Handle("POST /categories", middleware.IsAuthenticatedOrAPIKey(middleware.RequirePermission(handler.Create(), rbac.PermProductsWrite), deps.Auth))
+	router.Handle("PATCH /categories/{slug}", middleware.IsAuthenticatedOrAPIKey(middleware.RequirePermission(handler.Update(), rbac.PermProductsWrite), deps.Auth))
+	router.Handle("DELETE /categories/{slug}", middleware.IsAuthenticatedOrAPIKey(middleware.RequirePermission(handler.Delete(), rbac.PermProductsWrite), deps.Auth))
+}
+
+// Tree godoc
+// @Summary Дерево категорий
+// @Description Видимые категории с подкатегориями, упорядоченные по sort_order и имени
+// @Tags categories,open
+// @Produce json
+// @Success 200 {object} categories.CategoryTreeResponse
+// @Failure 500 {object} map[string]string
+// @Router /categories [get]
+func (handler *CategoryHandler) Tree() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tree, err := handler.service.Tree(r.Context(), false)
+		if err != nil {
+			res.Json(w, map[string]string{"error": "failed to list categories"}, http.StatusInternalServerError)
+			return
+		}
+		res.Json(w, CategoryTreeResponse{Categories: tree}, http.StatusOK)
+	}
+}
+
+// TreeAll godoc
+// @Summary Дерево категорий со скрытыми (админ)
+// @Tags categories,admin
+// @Produce json
+// @Success 200 {object} categories.CategoryTreeResponse
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /categories/all [get]
+func (handler *CategoryHandler) TreeAll() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tree, err := handler.service.Tree(r.Context(), true)
+		if err != nil {
+			res.Json(w, map[string]string{"error": "failed to list categories"}, http.StatusInternalServerError)
+			return
+		}
+		res.Json(w, CategoryTreeResponse{Categories: tree}, http.StatusOK)
+	}
+}
+
+// Get godoc
+// @Summary Категория по slug
+// @Description Видимая категория с подкатегориями
+// @Tags categories,open
+// @Produce json
+// @Param slug path string true "slug"
+// @Success 200 {object} categories.CategoryNode
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /categories/{slug} [get]
+func (handler *CategoryHandler) Get() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		node, err := handler.service.Get(r.Context(), r.PathValue("slug"), false)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				res.Json(w, map[string]string{"error": "category not found"}, http.StatusNotFound)
+				return
+			}
+			res.Json(w, map[string]string{"error": "failed to get category"}, http.StatusInternalServerError)
+			return
+		}
+		res.Json(w, node, http.StatusOK)
+	}
+}
+
+// Create godoc
+// @Summary Создать категорию (админ)
+// @Description Slug строится из имени, если не задан. Slug «all» зарезервирован
+// @Tags categories,admin
+// @Accept json
+// @Produce json
+// @Param request body categories.CategoryCreateRequest true "Данные категории"
+// @Success 201 {object} categories.CategoryNode
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /categories [post]
+func (handler *CategoryHandler) Create() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := req.HandleBody[CategoryCreateRequest](&w, r)
+		if err != nil {
+			return
+		}
+		created, err := handler.service.Create(r.Context(), *body)
+		if err != nil {
+			if errors.Is(err, ErrValidation) {
+				res.Json(w, map[string]string{"error": err.Error()}, http.StatusBadRequest)
+				return
+			}
+			res.Json(w, map[string]string{"error": "failed to create category"}, http.StatusInternalServerError)
+			return
+		}
+		res.Json(w, toNode(created), http.StatusCreated)
+	}
+}
+
+// Update godoc
+// @Summary Обновить категорию (админ)
+// @Description Частичное обновление. parent_id = 0 переносит категорию в корень; перенос в собственное поддерево запрещён
+// @Tags categories,admin
+// @Accept json
+// @Produce json
+// @Param slug path string true "slug"
+// @Param request body categories.CategoryUpdateRequest true "Поля для обновления"
+// @Success 200 {object} categories.CategoryNode
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /categories/{slug} [patch]
+func (handler *CategoryHandler) Update() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := req.HandleBody[CategoryUpdateRequest](&w, r)
+		if err != nil {
+			return
+		}
+		updated, err := handler.service.Update(r.Context(), r.PathValue("slug"), *body)
+		switch {
+		case errors.Is(err, ErrValidation):
+			res.Json(w, map[string]string{"error": err.Error()}, http.StatusBadRequest)
+			return
+		case errors.Is(err, ErrNotFound):
+			res.Json(w, map[string]string{"error": "category not found"}, http.StatusNotFound)
+			return
+		case err != nil:
+			res.Json(w, map[string]string{"error": "failed to update category"}, http.StatusInternalServerError)
+			return
+		}
+		res.Json(w, toNode(updated), http.StatusOK)
+	}
+}
+
+// Delete godoc
+// @Summary Удалить категорию (админ)
+// @Description Удаляет категорию без подкатегорий и отвязывает от неё продукты
+// @Tags categories,admin
+// @Param slug path string true "slug"
+// @Success 204
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /categories/{slug} [delete]
+func (handler *CategoryHandler) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := handler.service.Delete(r.Context(), r.PathValue("slug"))
+		switch {
+		case errors.Is(err, ErrNotFound):
+			res.Json(w, map[string]string{"error": "category not found"}, http.StatusNotFound)
+			return
+		case errors.Is(err, ErrHasChildren):
+			res.Json(w, map[string]string{"error": err.Error()}, http.StatusConflict)
+			return
+		case err != nil:
+			res.Json(w, map[string]string{"error": "failed to delete category"}, http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
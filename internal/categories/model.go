@@ -0,0 +1,22 @@
+package categories
+
+import "gorm.io/gorm"
+
+// Category — раздел каталога. Категории образуют дерево через ParentID,
+// скрытая категория скрывает и всё своё поддерево.
+type Category struct {
+	gorm.Model `swaggerignore:"true"`
+	// Родительская категория; nil — корень
+	ParentID  *uint  `json:"parent_id" gorm:"index"`
+	Slug      string `json:"slug" gorm:"size:128;uniqueIndex;not null"`
+	Name      string `json:"name" gorm:"size:128;not null"`
+	SortOrder int    `json:"sort_order" gorm:"not null;default:0"`
+	Visible   bool   `json:"visible" gorm:"not null"`
+}
+
+// ProductsJoinTable — таблица связи продуктов и категорий (many2many в products.Product).
+const ProductsJoinTable = "product_categories"
+
+// ModifierGroupsJoinTable — таблица связи категорий и групп модификаторов
+// (many2many в products.ModifierGroup).
+const ModifierGroupsJoinTable = "category_modifier_groups"
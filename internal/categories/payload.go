@@ -0,0 +1,35 @@
+package categories
+
+type CategoryCreateRequest struct {
+	Name string `json:"name" validate:"required,max=128" example:"Кофе"`
+	// Если не задан — строится из имени
+	Slug      string `json:"slug" validate:"omitempty,max=128" example:"coffee"`
+	ParentID  *uint  `json:"parent_id" example:"2"`
+	SortOrder int    `json:"sort_order" example:"10"`
+	// По умолчанию true
+	Visible *bool `json:"visible" example:"true"`
+}
+
+type CategoryUpdateRequest struct {
+	Name *string `json:"name" validate:"omitempty,min=1,max=128"`
+	Slug *string `json:"slug" validate:"omitempty,min=1,max=128"`
+	// 0 — перенести в корень
+	ParentID  *uint `json:"parent_id"`
+	SortOrder *int  `json:"sort_order"`
+	Visible   *bool `json:"visible"`
+}
+
+// CategoryNode — категория с подкатегориями.
+type CategoryNode struct {
+	ID        uint           `json:"id" example:"3"`
+	ParentID  *uint          `json:"parent_id" example:"2"`
+	Slug      string         `json:"slug" example:"coffee"`
+	Name      string         `json:"name" example:"Кофе"`
+	SortOrder int            `json:"sort_order" example:"10"`
+	Visible   bool           `json:"visible" example:"true"`
+	Children  []CategoryNode `json:"children"`
+}
+
+type CategoryTreeResponse struct {
+	Categories []CategoryNode `json:"categories"`
+}
@@ -0,0 +1,104 @@
+package categories
+
+import (
+	"bike/pkg/db"
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+type CategoryRepository struct {
+	Database *db.Db
+}
+
+func NewCategoryRepository(database *db.Db) *CategoryRepository {
+	return &CategoryRepository{
+		Database: database,
+	}
+}
+
+func (r *CategoryRepository) Create(ctx context.Context, c *Category) (*Category, error) {
+	if err := r.Database.DB.WithContext(ctx).Create(c).Error; err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (r *CategoryRepository) Save(ctx context.Context, c *Category) (*Category, error) {
+	if err := r.Database.DB.WithContext(ctx).Save(c).Error; err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// ExistsSlug учитывает и удалённые категории: уникальный индекс по slug
+// включает их, поэтому их slug повторно не выдаётся.
+func (r *CategoryRepository) ExistsSlug(ctx context.Context, slug string) (bool, error) {
+	var cnt int64
+	err := r.Database.DB.WithContext(ctx).Unscoped().Model(&Category{}).
+		Where("slug = ?", slug).Count(&cnt).Error
+	return cnt > 0, err
+}
+
+func (r *CategoryRepository) FindBySlug(ctx context.Context, slug string) (*Category, error) {
+	var c Category
+	res := r.Database.DB.WithContext(ctx).Where("slug = ?", slug).First(&c)
+	if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &c, res.Error
+}
+
+// FindByIDs возвращает найденные категории из ids; отсутствующие пропускаются.
+func (r *CategoryRepository) FindByIDs(ctx context.Context, ids []uint) ([]Category, error) {
+	var list []Category
+	if len(ids) == 0 {
+		return list, nil
+	}
+	err := r.Database.DB.WithContext(ctx).Where("id IN ?", ids).Find(&list).Error
+	return list, err
+}
+
+// List возвращает все категории в порядке показа.
+func (r *CategoryRepository) List(ctx context.Context) ([]Category, error) {
+	var list []Category
+	err := r.Database.DB.WithContext(ctx).Order("sort_order, name, id").Find(&list).Error
+	return list, err
+}
+
+// CountChildren возвращает число прямых подкатегорий.
+func (r *CategoryRepository) CountChildren(ctx context.Context, id uint) (int64, error) {
+	var cnt int64
+	err := r.Database.DB.WithContext(ctx).Model(&Category{}).
+		Where("parent_id = ?", id).Count(&cnt).Error
+	return cnt, err
+}
+
+// Delete удаляет категорию (soft delete) и её связи с продуктами и группами
+// модификаторов.
+func (r *CategoryRepository) Delete(ctx context.Context, c *Category) error {
+	return r.Database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, table := range []string{ProductsJoinTable, ModifierGroupsJoinTable} {
+			if err := tx.Exec("DELETE FROM "+table+" WHERE category_id = ?", c.ID).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Delete(c).Error
+	})
+}
+
+// WithAncestors возвращает ids вместе с ID всех их предков.
+func (r *CategoryRepository) WithAncestors(ctx context.Context, ids []uint) ([]uint, error) {
+	var out []uint
+	if len(ids) == 0 {
+		return out, nil
+	}
+	err := r.Database.DB.WithContext(ctx).Raw(`WITH RECURSIVE up AS (
+			SELECT id, parent_id FROM categories WHERE id IN ? AND deleted_at IS NULL
+			UNION
+			SELECT c.id, c.parent_id FROM categories c JOIN up ON c.id = up.parent_id WHERE c.deleted_at IS NULL
+		)
+		SELECT id FROM up`, ids).Scan(&out).Error
+	return out, err
+}
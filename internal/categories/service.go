@@ -0,0 +1,289 @@
+package categories
+
+import (
+	"bike/pkg/slug"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrValidation  = errors.New("validation error")
+	ErrNotFound    = errors.New("not found")
+	ErrHasChildren = errors.New("category has subcategories")
+)
+
+// reservedSlugs заняты маршрутами /categories/...
+var reservedSlugs = map[string]bool{"all": true}
+
+type CategoryService interface {
+	Tree(ctx context.Context, includeHidden bool) ([]CategoryNode, error)
+	Get(ctx context.Context, slug string, includeHidden bool) (*CategoryNode, error)
+	SubtreeIDs(ctx context.Context, slug string) ([]uint, error)
+	Create(ctx context.Context, in CategoryCreateRequest) (*Category, error)
+	Update(ctx context.Context, slug string, in CategoryUpdateRequest) (*Category, error)
+	Delete(ctx context.Context, slug string) error
+}
+
+type categoryService struct{ repo *CategoryRepository }
+
+func NewCategoryService(repo *CategoryRepository) CategoryService {
+	return &categoryService{repo: repo}
+}
+
+// Tree возвращает дерево категорий. Без includeHidden скрытые категории
+// отбрасываются вместе с поддеревом.
+func (s *categoryService) Tree(ctx context.Context, includeHidden bool) ([]CategoryNode, error) {
+	list, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return buildTree(list, includeHidden), nil
+}
+
+// Get возвращает категорию с поддеревом. Категория в скрытой ветке без
+// includeHidden считается ненайденной.
+func (s *categoryService) Get(ctx context.Context, sl string, includeHidden bool) (*CategoryNode, error) {
+	tree, err := s.Tree(ctx, includeHidden)
+	if err != nil {
+		return nil, err
+	}
+	node := findNode(tree, sl)
+	if node == nil {
+		return nil, ErrNotFound
+	}
+	return node, nil
+}
+
+// SubtreeIDs возвращает ID видимой категории и всех её видимых потомков.
+func (s *categoryService) SubtreeIDs(ctx context.Context, sl string) ([]uint, error) {
+	node, err := s.Get(ctx, sl, false)
+	if err != nil {
+		return nil, err
+	}
+	return collectIDs(node, nil), nil
+}
+
+func (s *categoryService) Create(ctx context.Context, in CategoryCreateRequest) (*Category, error) {
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrValidation)
+	}
+	use, err := s.pickSlug(ctx, in.Slug, name)
+	if err != nil {
+		return nil, err
+	}
+	if in.ParentID != nil {
+		if err := s.checkParent(ctx, 0, *in.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	c := &Category{
+		ParentID:  in.ParentID,
+		Slug:      use,
+		Name:      name,
+		SortOrder: in.SortOrder,
+		Visible:   in.Visible == nil || *in.Visible,
+	}
+	return s.repo.Create(ctx, c)
+}
+
+func (s *categoryService) Update(ctx context.Context, sl string, in CategoryUpdateRequest) (*Category, error) {
+	if in.Name == nil && in.Slug == nil && in.ParentID == nil && in.SortOrder == nil && in.Visible == nil {
+		return nil, fmt.Errorf("%w: at least one field required", ErrValidation)
+	}
+	c, err := s.find(ctx, sl)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Name != nil {
+		name := strings.TrimSpace(*in.Name)
+		if name == "" {
+			return nil, fmt.Errorf("%w: name is required", ErrValidation)
+		}
+		c.Name = name
+	}
+	if in.Slug != nil && slug.Clean(*in.Slug) != c.Slug {
+		use, err := s.pickSlug(ctx, *in.Slug, "")
+		if err != nil {
+			return nil, err
+		}
+		c.Slug = use
+	}
+	if in.ParentID != nil {
+		if *in.ParentID == 0 {
+			c.ParentID = nil
+		} else {
+			if err := s.checkParent(ctx, c.ID, *in.ParentID); err != nil {
+				return nil, err
+			}
+			c.ParentID = in.ParentID
+		}
+	}
+	if in.SortOrder != nil {
+		c.SortOrder = *in.SortOrder
+	}
+	if in.Visible != nil {
+		c.Visible = *in.Visible
+	}
+	return s.repo.Save(ctx, c)
+}
+
+// Delete удаляет пустую ветку: подкатегории нужно сначала перенести или удалить.
+func (s *categoryService) Delete(ctx context.Context, sl string) error {
+	c, err := s.find(ctx, sl)
+	if err != nil {
+		return err
+	}
+	children, err := s.repo.CountChildren(ctx, c.ID)
+	if err != nil {
+		return err
+	}
+	if children > 0 {
+		return ErrHasChildren
+	}
+	return s.repo.Delete(ctx, c)
+}
+
+func (s *categoryService) find(ctx context.Context, sl string) (*Category, error) {
+	c, err := s.repo.FindBySlug(ctx, sl)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return c, err
+}
+
+// pickSlug возвращает свободный slug. Явно заданный slug должен быть
+// свободен; построенный из имени дополняется суффиксом -2, -3...
+// Если в исходной строке нет ни букв, ни цифр, slug не строится.
+func (s *categoryService) pickSlug(ctx context.Context, explicit, name string) (string, error) {
+	if explicit != "" {
+		use := slug.Clean(explicit)
+		if use == "" {
+			return "", fmt.Errorf("%w: slug must contain letters or digits", ErrValidation)
+		}
+		taken, err := s.slugTaken(ctx, use)
+		if err != nil {
+			return "", err
+		}
+		if taken {
+			return "", fmt.Errorf("%w: slug already exists", ErrValidation)
+		}
+		return use, nil
+	}
+
+	base := slug.Clean(name)
+	if base == "" {
+		return "", fmt.Errorf("%w: name must contain letters or digits", ErrValidation)
+	}
+	use := base
+	for n := 2; ; n++ {
+		taken, err := s.slugTaken(ctx, use)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return use, nil
+		}
+		use = slug.WithSuffix(base, n)
+	}
+}
+
+func (s *categoryService) slugTaken(ctx context.Context, sl string) (bool, error) {
+	if reservedSlugs[sl] {
+		return true, nil
+	}
+	return s.repo.ExistsSlug(ctx, sl)
+}
+
+// checkParent проверяет, что parentID существует и не лежит в поддереве
+// категории id (id == 0 — новая категория).
+func (s *categoryService) checkParent(ctx context.Context, id, parentID uint) error {
+	if parentID == id {
+		return fmt.Errorf("%w: category cannot be its own parent", ErrValidation)
+	}
+	list, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	parents := make(map[uint]*uint, len(list))
+	for _, c := range list {
+		parents[c.ID] = c.ParentID
+	}
+	if _, ok := parents[parentID]; !ok {
+		return fmt.Errorf("%w: parent category not found", ErrValidation)
+	}
+	// Поднимаемся от нового родителя к корню; шагов не больше, чем категорий
+	cur := &parentID
+	for i := 0; cur != nil && i <= len(list); i++ {
+		if id != 0 && *cur == id {
+			return fmt.Errorf("%w: category cannot be moved under its own subcategory", ErrValidation)
+		}
+		cur = parents[*cur]
+	}
+	return nil
+}
+
+func toNode(c *Category) CategoryNode {
+	return CategoryNode{
+		ID:        c.ID,
+		ParentID:  c.ParentID,
+		Slug:      c.Slug,
+		Name:      c.Name,
+		SortOrder: c.SortOrder,
+		Visible:   c.Visible,
+	}
+}
+
+// buildTree собирает дерево из списка в порядке показа. Категории, чей
+// родитель отброшен или отсутствует, в дерево не попадают.
+func buildTree(list []Category, includeHidden bool) []CategoryNode {
+	children := make(map[uint][]Category)
+	for _, c := range list {
+		if !includeHidden && !c.Visible {
+			continue
+		}
+		var parent uint
+		if c.ParentID != nil {
+			parent = *c.ParentID
+		}
+		children[parent] = append(children[parent], c)
+	}
+
+	var build func(parent uint) []CategoryNode
+	build = func(parent uint) []CategoryNode {
+		nodes := make([]CategoryNode, 0, len(children[parent]))
+		for _, c := range children[parent] {
+			node := toNode(&c)
+			node.Children = build(c.ID)
+			nodes = append(nodes, node)
+		}
+		return nodes
+	}
+	return build(0)
+}
+
+func findNode(nodes []CategoryNode, sl string) *CategoryNode {
+	for i := range nodes {
+		if nodes[i].Slug == sl {
+			return &nodes[i]
+		}
+		if found := findNode(nodes[i].Children, sl); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func collectIDs(node *CategoryNode, ids []uint) []uint {
+	ids = append(ids, node.ID)
+	for i := range node.Children {
+		ids = collectIDs(&node.Children[i], ids)
+	}
+	return ids
+}
@@ -0,0 +1,184 @@
+package impersonation
+
+import (
+	"bike/pkg/middleware"
+	"bike/pkg/rbac"
+	"bike/pkg/req"
+	"bike/pkg/res"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+type ImpersonationHandlerDeps struct {
+	ImpersonationService *ImpersonationService
+	Auth                 *middleware.AuthDeps
+}
+
+type ImpersonationHandler struct {
+	service *ImpersonationService
+}
+
+func NewImpersonationHandler(router *http.ServeMux, deps ImpersonationHandlerDeps) {
+	handler := &ImpersonationHandler{service: deps.ImpersonationService}
+
+	// Только администратор по JWT: API-ключам это право не выдаётся
+	router.Handle("POST /users/{id}/impersonate", middleware.IsAuthenticated(middleware.RequirePermission(handler.Start(), rbac.PermUsersImpersonate), deps.Auth))
+	router.Handle("GET /impersonations", middleware.IsAuthenticated(middleware.RequirePermission(handler.List(), rbac.PermUsersImpersonate), deps.Auth))
+	router.Handle("GET /impersonations/{id}", middleware.IsAuthenticated(middleware.RequirePermission(handler.Get(), rbac.PermUsersImpersonate), deps.Auth))
+}
+
+// Start godoc
+// @Summary Войти от имени пользователя (админ)
+// @Description Выдаёт короткоживущий access-токен пользователя с claim act (ID администратора). Refresh-токен не выдаётся.
+// @Description С таким токеном нельзя менять пароль, email, 2FA и сессии, удалять аккаунт. Начало и каждый запрос записываются в журнал. Администраторов имперсонировать нельзя
+// @Tags users,admin
+// @Accept json
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Param request body impersonation.StartRequest true "Причина"
+// @Success 201 {object} impersonation.StartResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/{id}/impersonate [post]
+func (handler *ImpersonationHandler) Start() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 32)
+		if err != nil {
+			res.Json(w, map[string]string{"error": "invalid id"}, http.StatusBadRequest)
+			return
+		}
+		body, err := req.HandleBody[StartRequest](&w, r)
+		if err != nil {
+			return
+		}
+		actor, ok := middleware.FromContext(r.Context())
+		if !ok {
+			res.Json(w, map[string]string{"error": "unauthorized"}, http.StatusUnauthorized)
+			return
+		}
+		imp, token, err := handler.service.Start(r.Context(), actor, uint(id), body.Reason, req.ClientIP(r))
+		if err != nil {
+			switch {
+			case errors.Is(err, ErrUserNotFound):
+				res.Json(w, map[string]string{"error": err.Error()}, http.StatusNotFound)
+			case errors.Is(err, ErrSelf):
+				res.Json(w, map[string]string{"error": err.Error()}, http.StatusBadRequest)
+			case errors.Is(err, ErrNested), errors.Is(err, ErrTargetAdmin):
+				res.Json(w, map[string]string{"error": err.Error()}, http.StatusForbidden)
+			case errors.Is(err, ErrUserSuspended):
+				res.Json(w, map[string]string{"error": err.Error()}, http.StatusConflict)
+			default:
+				res.Json(w, map[string]string{"error": "failed to start impersonation"}, http.StatusInternalServerError)
+			}
+			return
+		}
+		res.Json(w, StartResponse{ImpersonationResponse: toResponse(imp), Token: token}, http.StatusCreated)
+	}
+}
+
+// List godoc
+// @Summary Журнал имперсонаций (админ)
+// @Tags admin
+// @Produce json
+// @Param actor_id query int false "ID администратора"
+// @Param user_id query int false "ID пользователя"
+// @Param page query int false "page" default(1)
+// @Param limit query int false "limit" default(20)
+// @Success 200 {object} impersonation.ImpersonationListResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /impersonations [get]
+func (handler *ImpersonationHandler) List() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page < 1 {
+			page = 1
+		}
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		if limit < 1 {
+			limit = 20
+		}
+		if limit > 100 {
+			limit = 100
+		}
+		actorID, ok := queryID(w, r, "actor_id")
+		if !ok {
+			return
+		}
+		userID, ok := queryID(w, r, "user_id")
+		if !ok {
+			return
+		}
+
+		list, total, err := handler.service.List(actorID, userID, limit, (page-1)*limit)
+		if err != nil {
+			res.Json(w, map[string]string{"error": "failed to list impersonations"}, http.StatusInternalServerError)
+			return
+		}
+		out := make([]ImpersonationResponse, 0, len(list))
+		for i := range list {
+			out = append(out, toResponse(&list[i]))
+		}
+		res.Json(w, ImpersonationListResponse{
+			Impersonations: out,
+			Total:          total,
+			Page:           page,
+			Limit:          limit,
+			TotalPages:     (int(total) + limit - 1) / limit,
+		}, http.StatusOK)
+	}
+}
+
+// Get godoc
+// @Summary Имперсонация и выполненные запросы (админ)
+// @Tags admin
+// @Produce json
+// @Param id path string true "ID имперсонации (jti токена)"
+// @Success 200 {object} impersonation.ImpersonationDetailsResponse
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /impersonations/{id} [get]
+func (handler *ImpersonationHandler) Get() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		imp, actions, err := handler.service.Get(r.PathValue("id"))
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				res.Json(w, map[string]string{"error": err.Error()}, http.StatusNotFound)
+				return
+			}
+			res.Json(w, map[string]string{"error": "failed to get impersonation"}, http.StatusInternalServerError)
+			return
+		}
+		out := ImpersonationDetailsResponse{
+			ImpersonationResponse: toResponse(imp),
+			Actions:               make([]ActionResponse, 0, len(actions)),
+		}
+		for i := range actions {
+			out.Actions = append(out.Actions, toActionResponse(&actions[i]))
+		}
+		res.Json(w, out, http.StatusOK)
+	}
+}
+
+// queryID разбирает необязательный числовой параметр; при ошибке сам отвечает 400.
+func queryID(w http.ResponseWriter, r *http.Request, name string) (uint, bool) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return 0, true
+	}
+	id, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil {
+		res.Json(w, map[string]string{"error": "invalid " + name}, http.StatusBadRequest)
+		return 0, false
+	}
+	return uint(id), true
+}
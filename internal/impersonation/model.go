@@ -0,0 +1,30 @@
+package impersonation
+
+import "time"
+
+// Impersonation — вход администратора от имени пользователя.
+// ID совпадает с jti выданного токена.
+type Impersonation struct {
+	ID         string `gorm:"primaryKey;size:36"`
+	ActorID    uint   `gorm:"index;not null"`
+	ActorEmail string `gorm:"size:320"`
+	UserID     uint   `gorm:"index;not null"`
+	Reason     string `gorm:"size:500;not null"`
+	IP         string `gorm:"size:64"`
+	ExpiresAt  time.Time
+	CreatedAt  time.Time
+}
+
+// Action — запрос, выполненный с токеном имперсонации.
+type Action struct {
+	ID              uint   `gorm:"primaryKey"`
+	ImpersonationID string `gorm:"size:36;index;not null"`
+	Method          string `gorm:"size:8"`
+	Path            string `gorm:"size:512"`
+	Status          int
+	CreatedAt       time.Time
+}
+
+func (Action) TableName() string {
+	return "impersonation_actions"
+}
@@ -0,0 +1,64 @@
+package impersonation
+
+type StartRequest struct {
+	Reason string `json:"reason" validate:"required,max=500" example:"Обращение №1542: не оформляется заказ"`
+}
+
+type StartResponse struct {
+	ImpersonationResponse
+	// Access-токен пользователя с claim act; refresh-токен не выдаётся
+	Token string `json:"token"`
+}
+
+type ImpersonationResponse struct {
+	ID         string `json:"id"`
+	ActorID    uint   `json:"actor_id"`
+	ActorEmail string `json:"actor_email"`
+	UserID     uint   `json:"user_id"`
+	Reason     string `json:"reason"`
+	IP         string `json:"ip,omitempty"`
+	ExpiresAt  string `json:"expires_at"`
+	CreatedAt  string `json:"created_at"`
+}
+
+type ActionResponse struct {
+	Method    string `json:"method"`
+	Path      string `json:"path"`
+	Status    int    `json:"status"`
+	CreatedAt string `json:"created_at"`
+}
+
+type ImpersonationDetailsResponse struct {
+	ImpersonationResponse
+	Actions []ActionResponse `json:"actions"`
+}
+
+type ImpersonationListResponse struct {
+	Impersonations []ImpersonationResponse `json:"impersonations"`
+	Total          int64                   `json:"total"`
+	Page           int                     `json:"page"`
+	Limit          int                     `json:"limit"`
+	TotalPages     int                     `json:"total_pages"`
+}
+
+func toResponse(imp *Impersonation) ImpersonationResponse {
+	return ImpersonationResponse{
+		ID:         imp.ID,
+		ActorID:    imp.ActorID,
+		ActorEmail: imp.ActorEmail,
+		UserID:     imp.UserID,
+		Reason:     imp.Reason,
+		IP:         imp.IP,
+		ExpiresAt:  imp.ExpiresAt.Format("2006-01-02T15:04:05Z07:00"),
+		CreatedAt:  imp.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+func toActionResponse(a *Action) ActionResponse {
+	return ActionResponse{
+		Method:    a.Method,
+		Path:      a.Path,
+		Status:    a.Status,
+		CreatedAt: a.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
@@ -0,0 +1,59 @@
+package impersonation
+
+import "bike/pkg/db"
+
+type ImpersonationRepository struct {
+	database *db.Db
+}
+
+func NewImpersonationRepository(database *db.Db) *ImpersonationRepository {
+	return &ImpersonationRepository{database: database}
+}
+
+func (r *ImpersonationRepository) Create(imp *Impersonation) error {
+	return r.database.DB.Create(imp).Error
+}
+
+func (r *ImpersonationRepository) FindByID(id string) (*Impersonation, error) {
+	var imp Impersonation
+	result := r.database.DB.Where("id = ?", id).First(&imp)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &imp, nil
+}
+
+// List возвращает записи, новые первыми. Нулевые actorID и userID — без фильтра.
+func (r *ImpersonationRepository) List(actorID, userID uint, limit, offset int) ([]Impersonation, int64, error) {
+	query := r.database.DB.Model(&Impersonation{})
+	if actorID != 0 {
+		query = query.Where("actor_id = ?", actorID)
+	}
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []Impersonation
+	result := query.Order("created_at desc").Limit(limit).Offset(offset).Find(&list)
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+	return list, total, nil
+}
+
+func (r *ImpersonationRepository) CreateAction(action *Action) error {
+	return r.database.DB.Create(action).Error
+}
+
+// ListActions возвращает запросы, выполненные в рамках имперсонации, по порядку.
+func (r *ImpersonationRepository) ListActions(impersonationID string) ([]Action, error) {
+	var list []Action
+	result := r.database.DB.Where("impersonation_id = ?", impersonationID).Order("created_at, id").Find(&list)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return list, nil
+}
@@ -0,0 +1,146 @@
+package impersonation
+
+import (
+	"bike/configs"
+	"bike/internal/users"
+	"bike/pkg/jwt"
+	"bike/pkg/middleware"
+	"bike/pkg/rbac"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUserNotFound  = errors.New("user not found")
+	ErrNested        = errors.New("already impersonating")
+	ErrSelf          = errors.New("cannot impersonate yourself")
+	ErrTargetAdmin   = errors.New("cannot impersonate an administrator")
+	ErrUserSuspended = errors.New("user is suspended")
+	ErrNotFound      = errors.New("impersonation not found")
+)
+
+type ImpersonationServiceDeps struct {
+	ImpersonationRepository *ImpersonationRepository
+	UserRepository          *users.UserRepository
+	JWT                     *jwt.JWT
+	Config                  *configs.Config
+}
+
+type ImpersonationService struct {
+	repo   *ImpersonationRepository
+	users  *users.UserRepository
+	jwt    *jwt.JWT
+	config *configs.Config
+}
+
+func NewImpersonationService(deps ImpersonationServiceDeps) *ImpersonationService {
+	return &ImpersonationService{
+		repo:   deps.ImpersonationRepository,
+		users:  deps.UserRepository,
+		jwt:    deps.JWT,
+		config: deps.Config,
+	}
+}
+
+// Start выдаёт администратору actor короткий access-токен пользователя userID
+// с claim act. Запись в журнале создаётся до выдачи токена: токена без записи
+// быть не может.
+func (s *ImpersonationService) Start(ctx context.Context, actor *middleware.Principal, userID uint, reason, ip string) (*Impersonation, string, error) {
+	if actor.Impersonated() {
+		return nil, "", ErrNested
+	}
+	if actor.UserID == userID {
+		return nil, "", ErrSelf
+	}
+	user, err := s.users.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", ErrUserNotFound
+		}
+		return nil, "", err
+	}
+	if user.Role == rbac.RoleAdmin {
+		return nil, "", ErrTargetAdmin
+	}
+	if user.IsSuspended() {
+		return nil, "", ErrUserSuspended
+	}
+
+	now := time.Now()
+	imp := &Impersonation{
+		ID:         uuid.NewString(),
+		ActorID:    actor.UserID,
+		ActorEmail: actor.Email,
+		UserID:     user.ID,
+		Reason:     strings.TrimSpace(reason),
+		IP:         ip,
+		ExpiresAt:  now.Add(s.config.Auth.ImpersonationTTL),
+	}
+	if err := s.repo.Create(imp); err != nil {
+		return nil, "", err
+	}
+	token, err := s.jwt.GenerateToken(jwt.JWTData{
+		UserID:    user.ID,
+		Email:     user.Email,
+		Role:      string(user.Role),
+		ID:        imp.ID,
+		IssuedAt:  now,
+		ExpiresAt: imp.ExpiresAt,
+		Actor:     &jwt.Actor{UserID: actor.UserID, Email: actor.Email},
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	log.Printf("impersonation started: actor=%d user=%d id=%s", actor.UserID, user.ID, imp.ID)
+	return imp, token, nil
+}
+
+// RecordAction записывает запрос, выполненный с токеном имперсонации.
+// Запись идёт в фоне, чтобы не задерживать ответ.
+func (s *ImpersonationService) RecordAction(p *middleware.Principal, r *http.Request, status int) {
+	action := &Action{
+		ImpersonationID: p.TokenID,
+		Method:          r.Method,
+		Path:            truncate(r.URL.Path, 512),
+		Status:          status,
+	}
+	go func() {
+		if err := s.repo.CreateAction(action); err != nil {
+			log.Printf("failed to record impersonated request %s %s: %v", action.Method, action.Path, err)
+		}
+	}()
+}
+
+func (s *ImpersonationService) List(actorID, userID uint, limit, offset int) ([]Impersonation, int64, error) {
+	return s.repo.List(actorID, userID, limit, offset)
+}
+
+// Get возвращает запись и все запросы, выполненные в её рамках.
+func (s *ImpersonationService) Get(id string) (*Impersonation, []Action, error) {
+	imp, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, err
+	}
+	actions, err := s.repo.ListActions(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return imp, actions, nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
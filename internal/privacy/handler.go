@@ -0,0 +1,282 @@
+package privacy
+
+import (
+	"archive/zip"
+	"bike/pkg/middleware"
+	"bike/pkg/rbac"
+	"bike/pkg/req"
+	"bike/pkg/res"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+type PrivacyHandlerDeps struct {
+	PrivacyService *PrivacyService
+	Auth           *middleware.AuthDeps
+}
+
+type PrivacyHandler struct {
+	service *PrivacyService
+}
+
+func NewPrivacyHandler(router *http.ServeMux, deps PrivacyHandlerDeps) {
+	handler := &PrivacyHandler{service: deps.PrivacyService}
+
+	// Выгрузка и удаление своих данных
+	router.Handle("GET /users/me/export", middleware.IsAuthenticated(handler.ExportMe(), deps.Auth))
+	router.Handle("DELETE /users/me", middleware.IsAuthenticated(middleware.ForbidImpersonation(handler.EraseMe()), deps.Auth))
+
+	// Обращения, полученные вне API (по почте), обрабатывает администратор
+	router.Handle("GET /privacy/users/{id}/export", middleware.IsAuthenticated(middleware.RequirePermission(handler.AdminExport(), rbac.PermUsersWrite), deps.Auth))
+	router.Handle("POST /privacy/users/{id}/erase", middleware.IsAuthenticated(middleware.RequirePermission(handler.AdminErase(), rbac.PermUsersWrite), deps.Auth))
+	router.Handle("GET /privacy/erasures", middleware.IsAuthenticated(middleware.RequirePermission(handler.ListErasures(), rbac.PermUsersWrite), deps.Auth))
+}
+
+// ExportMe godoc
+// @Summary Выгрузить мои данные
+// @Description Возвращает все данные, которые сервис хранит о пользователе: профиль, адреса, сессии, привязанные аккаунты. format=zip отдаёт zip-архив с JSON-файлами
+// @Tags users,privacy,jwt,user
+// @Produce json
+// @Produce application/zip
+// @Param format query string false "json или zip" default(json)
+// @Success 200 {object} privacy.ExportBundle
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/me/export [get]
+func (handler *PrivacyHandler) ExportMe() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := middleware.FromContext(r.Context())
+		if !ok {
+			res.Json(w, map[string]string{"error": "unauthorized"}, http.StatusUnauthorized)
+			return
+		}
+		handler.export(w, r, principal.UserID)
+	}
+}
+
+// EraseMe godoc
+// @Summary Удалить мой аккаунт
+// @Description Обезличивает профиль, безвозвратно удаляет адреса, отзывает все токены и сессии. Если у аккаунта есть пароль, его нужно передать
+// @Tags users,privacy,jwt,user
+// @Accept json
+// @Produce json
+// @Param request body privacy.EraseMeRequest true "Подтверждение паролем"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/me [delete]
+func (handler *PrivacyHandler) EraseMe() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := req.HandleBody[EraseMeRequest](&w, r)
+		if err != nil {
+			return
+		}
+		principal, ok := middleware.FromContext(r.Context())
+		if !ok {
+			res.Json(w, map[string]string{"error": "unauthorized"}, http.StatusUnauthorized)
+			return
+		}
+		if _, err := handler.service.EraseSelf(r.Context(), principal.UserID, body.Password); err != nil {
+			switch {
+			case errors.Is(err, ErrInvalidPassword):
+				res.Json(w, map[string]string{"error": err.Error()}, http.StatusForbidden)
+			case errors.Is(err, ErrUserNotFound):
+				res.Json(w, map[string]string{"error": "unauthorized"}, http.StatusUnauthorized)
+			default:
+				res.Json(w, map[string]string{"error": "failed to erase account"}, http.StatusInternalServerError)
+			}
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// AdminExport godoc
+// @Summary Выгрузить данные пользователя (админ)
+// @Description Для обращений, полученных по почте. Формат тот же, что у /users/me/export
+// @Tags privacy,admin
+// @Produce json
+// @Produce application/zip
+// @Param id path int true "ID пользователя"
+// @Param format query string false "json или zip" default(json)
+// @Success 200 {object} privacy.ExportBundle
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /privacy/users/{id}/export [get]
+func (handler *PrivacyHandler) AdminExport() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 32)
+		if err != nil {
+			res.Json(w, map[string]string{"error": "invalid id"}, http.StatusBadRequest)
+			return
+		}
+		handler.export(w, r, uint(id))
+	}
+}
+
+// AdminErase godoc
+// @Summary Удалить данные пользователя (админ)
+// @Description Для обращений, полученных по почте. Причина (например, номер обращения) сохраняется в журнале удалений
+// @Tags privacy,admin
+// @Accept json
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Param request body privacy.AdminEraseRequest true "Причина удаления"
+// @Success 200 {object} privacy.ErasureResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /privacy/users/{id}/erase [post]
+func (handler *PrivacyHandler) AdminErase() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 32)
+		if err != nil {
+			res.Json(w, map[string]string{"error": "invalid id"}, http.StatusBadRequest)
+			return
+		}
+		body, err := req.HandleBody[AdminEraseRequest](&w, r)
+		if err != nil {
+			return
+		}
+		principal, ok := middleware.FromContext(r.Context())
+		if !ok {
+			res.Json(w, map[string]string{"error": "unauthorized"}, http.StatusUnauthorized)
+			return
+		}
+		erasure, err := handler.service.EraseByAdmin(r.Context(), principal.UserID, uint(id), body.Reason)
+		if err != nil {
+			if errors.Is(err, ErrUserNotFound) {
+				res.Json(w, map[string]string{"error": err.Error()}, http.StatusNotFound)
+				return
+			}
+			res.Json(w, map[string]string{"error": "failed to erase account"}, http.StatusInternalServerError)
+			return
+		}
+		res.Json(w, toErasureResponse(erasure), http.StatusOK)
+	}
+}
+
+// ListErasures godoc
+// @Summary Журнал удалений (админ)
+// @Tags privacy,admin
+// @Produce json
+// @Param user_id query int false "ID пользователя"
+// @Param page query int false "page" default(1)
+// @Param limit query int false "limit" default(20)
+// @Success 200 {object} privacy.ErasureListResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /privacy/erasures [get]
+func (handler *PrivacyHandler) ListErasures() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page < 1 {
+			page = 1
+		}
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		if limit < 1 {
+			limit = 20
+		}
+		if limit > 100 {
+			limit = 100
+		}
+		var userID uint64
+		if raw := r.URL.Query().Get("user_id"); raw != "" {
+			var err error
+			userID, err = strconv.ParseUint(raw, 10, 32)
+			if err != nil {
+				res.Json(w, map[string]string{"error": "invalid user_id"}, http.StatusBadRequest)
+				return
+			}
+		}
+
+		list, total, err := handler.service.ListErasures(uint(userID), limit, (page-1)*limit)
+		if err != nil {
+			res.Json(w, map[string]string{"error": "failed to list erasures"}, http.StatusInternalServerError)
+			return
+		}
+		out := make([]ErasureResponse, 0, len(list))
+		for i := range list {
+			out = append(out, toErasureResponse(&list[i]))
+		}
+		res.Json(w, ErasureListResponse{
+			Erasures:   out,
+			Total:      total,
+			Page:       page,
+			Limit:      limit,
+			TotalPages: (int(total) + limit - 1) / limit,
+		}, http.StatusOK)
+	}
+}
+
+// export отдаёт выгрузку в JSON или, при format=zip, архивом из отдельных файлов.
+func (handler *PrivacyHandler) export(w http.ResponseWriter, r *http.Request, userID uint) {
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "zip" {
+		res.Json(w, map[string]string{"error": "format must be json or zip"}, http.StatusBadRequest)
+		return
+	}
+	bundle, err := handler.service.Export(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			res.Json(w, map[string]string{"error": err.Error()}, http.StatusNotFound)
+			return
+		}
+		res.Json(w, map[string]string{"error": "failed to export data"}, http.StatusInternalServerError)
+		return
+	}
+
+	if format != "zip" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="bike-export-%d.json"`, userID))
+		res.Json(w, bundle, http.StatusOK)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="bike-export-%d.zip"`, userID))
+	w.WriteHeader(http.StatusOK)
+	// Заголовки уже отправлены: при ошибке клиент получит оборванный архив
+	_ = writeZip(w, bundle)
+}
+
+func writeZip(w http.ResponseWriter, bundle *ExportBundle) error {
+	archive := zip.NewWriter(w)
+	files := []struct {
+		name string
+		data any
+	}{
+		{"user.json", bundle.User},
+		{"addresses.json", bundle.Addresses},
+		{"sessions.json", bundle.Sessions},
+		{"linked_accounts.json", bundle.LinkedAccounts},
+		{"security.json", map[string]any{
+			"exported_at":        bundle.ExportedAt,
+			"two_factor_enabled": bundle.TwoFactorEnabled,
+		}},
+	}
+	for _, file := range files {
+		f, err := archive.Create(file.name)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(file.data); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
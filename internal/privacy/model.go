@@ -0,0 +1,24 @@
+package privacy
+
+import "time"
+
+// Erasure — запись об удалении персональных данных пользователя. Сам email не
+// хранится, только sha256 от него: по нему можно подтвердить, что удаление
+// выполнено, если пользователь обратится повторно.
+type Erasure struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"index;not null"`
+	EmailHash string `gorm:"size:64;index;not null"`
+	// Кто выполнил удаление: сам пользователь или администратор
+	RequestedByID uint   `gorm:"index"`
+	Channel       string `gorm:"size:16;not null"`
+	Reason        string `gorm:"size:500"`
+	// Сколько адресов удалено безвозвратно
+	AddressesDeleted int64
+	CreatedAt        time.Time
+}
+
+const (
+	ChannelSelf  = "self"
+	ChannelAdmin = "admin"
+)
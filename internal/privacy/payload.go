@@ -0,0 +1,71 @@
+package privacy
+
+import (
+	"bike/internal/addresses"
+	"bike/internal/users"
+)
+
+// ExportBundle — все данные, которые сервис хранит о пользователе.
+type ExportBundle struct {
+	ExportedAt       string                      `json:"exported_at"`
+	User             ExportUser                  `json:"user"`
+	Addresses        []addresses.AddressResponse `json:"addresses"`
+	Sessions         []ExportSession             `json:"sessions"`
+	LinkedAccounts   []ExportLinkedAccount       `json:"linked_accounts"`
+	TwoFactorEnabled bool                        `json:"two_factor_enabled"`
+}
+
+type ExportUser struct {
+	users.UserResponse
+	VerifiedAt         string `json:"verified_at,omitempty"`
+	PhoneVerifiedAt    string `json:"phone_verified_at,omitempty"`
+	MarketingConsentAt string `json:"marketing_consent_at,omitempty"`
+	UpdatedAt          string `json:"updated_at"`
+}
+
+type ExportSession struct {
+	ID         string `json:"id"`
+	DeviceName string `json:"device_name,omitempty"`
+	UserAgent  string `json:"user_agent,omitempty"`
+	IP         string `json:"ip,omitempty"`
+	CreatedAt  string `json:"created_at"`
+	LastSeenAt string `json:"last_seen_at"`
+	RevokedAt  string `json:"revoked_at,omitempty"`
+}
+
+type ExportLinkedAccount struct {
+	Provider  string `json:"provider"`
+	Subject   string `json:"subject"`
+	Email     string `json:"email,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+// EraseMeRequest — подтверждение удаления своего аккаунта. Пароль обязателен,
+// если он задан (у аккаунтов, созданных через OIDC, пароля нет).
+type EraseMeRequest struct {
+	Password string `json:"password,omitempty" example:"secret123"`
+}
+
+// AdminEraseRequest — удаление по обращению, полученному вне API (например, по почте).
+type AdminEraseRequest struct {
+	Reason string `json:"reason" validate:"required,max=500" example:"Письмо в поддержку от 12.03, обращение №1542"`
+}
+
+type ErasureResponse struct {
+	ID               uint   `json:"id"`
+	UserID           uint   `json:"user_id"`
+	EmailHash        string `json:"email_hash"`
+	RequestedByID    uint   `json:"requested_by_id"`
+	Channel          string `json:"channel"`
+	Reason           string `json:"reason,omitempty"`
+	AddressesDeleted int64  `json:"addresses_deleted"`
+	CreatedAt        string `json:"created_at"`
+}
+
+type ErasureListResponse struct {
+	Erasures   []ErasureResponse `json:"erasures"`
+	Total      int64             `json:"total"`
+	Page       int               `json:"page"`
+	Limit      int               `json:"limit"`
+	TotalPages int               `json:"total_pages"`
+}
@@ -0,0 +1,37 @@
+package privacy
+
+import "bike/pkg/db"
+
+type ErasureRepository struct {
+	database *db.Db
+}
+
+func NewErasureRepository(database *db.Db) *ErasureRepository {
+	return &ErasureRepository{database: database}
+}
+
+func (r *ErasureRepository) Create(erasure *Erasure) (*Erasure, error) {
+	result := r.database.DB.Create(erasure)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return erasure, nil
+}
+
+// List возвращает записи об удалении, новые первыми. userID = 0 — все записи.
+func (r *ErasureRepository) List(userID uint, limit, offset int) ([]Erasure, int64, error) {
+	query := r.database.DB.Model(&Erasure{})
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []Erasure
+	result := query.Order("created_at desc").Limit(limit).Offset(offset).Find(&list)
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+	return list, total, nil
+}
@@ -0,0 +1,223 @@
+package privacy
+
+import (
+	"bike/internal/addresses"
+	"bike/internal/auth"
+	"bike/internal/users"
+	"bike/pkg/db"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const timeLayout = "2006-01-02T15:04:05Z07:00"
+
+var (
+	ErrUserNotFound    = errors.New("user not found")
+	ErrInvalidPassword = errors.New("invalid password")
+)
+
+type PrivacyServiceDeps struct {
+	Database          *db.Db
+	UserRepository    *users.UserRepository
+	AddressRepository *addresses.AddressRepository
+	ErasureRepository *ErasureRepository
+	AuthService       *auth.AuthService
+}
+
+type PrivacyService struct {
+	database  *db.Db
+	users     *users.UserRepository
+	addresses *addresses.AddressRepository
+	erasures  *ErasureRepository
+	auth      *auth.AuthService
+}
+
+func NewPrivacyService(deps PrivacyServiceDeps) *PrivacyService {
+	return &PrivacyService{
+		database:  deps.Database,
+		users:     deps.UserRepository,
+		addresses: deps.AddressRepository,
+		erasures:  deps.ErasureRepository,
+		auth:      deps.AuthService,
+	}
+}
+
+// Export собирает все данные пользователя: профиль, адреса (включая удалённые,
+// пока они хранятся), сессии, привязанные аккаунты и статус 2FA.
+func (s *PrivacyService) Export(ctx context.Context, userID uint) (*ExportBundle, error) {
+	user, err := s.users.FindByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	list, err := s.addresses.ListAllByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.auth.PersonalData(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := &ExportBundle{
+		ExportedAt:       time.Now().UTC().Format(timeLayout),
+		User:             exportUser(user),
+		Addresses:        make([]addresses.AddressResponse, 0, len(list)),
+		Sessions:         make([]ExportSession, 0, len(data.Sessions)),
+		LinkedAccounts:   make([]ExportLinkedAccount, 0, len(data.Identities)),
+		TwoFactorEnabled: data.TwoFactorEnabled,
+	}
+	for i := range list {
+		bundle.Addresses = append(bundle.Addresses, addresses.ToResponse(&list[i]))
+	}
+	for _, sess := range data.Sessions {
+		bundle.Sessions = append(bundle.Sessions, ExportSession{
+			ID:         sess.ID,
+			DeviceName: sess.DeviceName,
+			UserAgent:  sess.UserAgent,
+			IP:         sess.IP,
+			CreatedAt:  sess.CreatedAt.Format(timeLayout),
+			LastSeenAt: sess.LastSeenAt.Format(timeLayout),
+			RevokedAt:  formatTime(sess.RevokedAt),
+		})
+	}
+	for _, identity := range data.Identities {
+		bundle.LinkedAccounts = append(bundle.LinkedAccounts, ExportLinkedAccount{
+			Provider:  identity.Provider,
+			Subject:   identity.Subject,
+			Email:     identity.Email,
+			CreatedAt: identity.CreatedAt.Format(timeLayout),
+		})
+	}
+	return bundle, nil
+}
+
+// EraseSelf удаляет аккаунт по запросу самого пользователя. Если у аккаунта
+// есть пароль, его нужно подтвердить.
+func (s *PrivacyService) EraseSelf(ctx context.Context, userID uint, password string) (*Erasure, error) {
+	user, err := s.users.FindByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if user.HasPassword() {
+		if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
+			return nil, ErrInvalidPassword
+		}
+	}
+	return s.erase(ctx, user, userID, ChannelSelf, "")
+}
+
+// EraseByAdmin удаляет аккаунт по обращению, полученному вне API.
+func (s *PrivacyService) EraseByAdmin(ctx context.Context, adminID, userID uint, reason string) (*Erasure, error) {
+	user, err := s.users.FindByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	return s.erase(ctx, user, adminID, ChannelAdmin, strings.TrimSpace(reason))
+}
+
+// ListErasures возвращает журнал удалений; userID = 0 — по всем пользователям.
+func (s *PrivacyService) ListErasures(userID uint, limit, offset int) ([]Erasure, int64, error) {
+	return s.erasures.List(userID, limit, offset)
+}
+
+// erase в одной транзакции отзывает токены и удаляет данные входа,
+// безвозвратно удаляет адреса, обезличивает запись пользователя, помечает её
+// удалённой и пишет журнал. Данные входа удаляются до обезличивания: коды из
+// SMS и счётчики входов привязаны к текущим телефону и email. При любой
+// ошибке не меняется ничего.
+func (s *PrivacyService) erase(ctx context.Context, user *users.User, requestedBy uint, channel, reason string) (*Erasure, error) {
+	emailHash := hashEmail(user.Email)
+
+	var erasure *Erasure
+	err := s.database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		database := &db.Db{DB: tx}
+		if err := s.auth.ForgetUser(ctx, tx, user); err != nil {
+			return err
+		}
+		deleted, err := addresses.NewAddressRepository(database).HardDeleteByUserID(user.ID)
+		if err != nil {
+			return err
+		}
+
+		userRepository := s.users.WithTx(tx)
+		anonymize(user)
+		if _, err := userRepository.Update(user); err != nil {
+			return err
+		}
+		if err := userRepository.Delete(user.ID); err != nil {
+			return err
+		}
+
+		erasure, err = NewErasureRepository(database).Create(&Erasure{
+			UserID:           user.ID,
+			EmailHash:        emailHash,
+			RequestedByID:    requestedBy,
+			Channel:          channel,
+			Reason:           reason,
+			AddressesDeleted: deleted,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return erasure, nil
+}
+
+// anonymize заменяет персональные данные заглушками. Строка остаётся, чтобы не
+// ломать ссылки на user_id (например, в журнале удалений).
+func anonymize(user *users.User) {
+	user.Email = fmt.Sprintf("deleted-%d@%s", user.ID, users.ErasedEmailDomain)
+	user.Name = "Deleted user"
+	user.Password = ""
+	user.VerifiedAt = nil
+	user.Phone = nil
+	user.PhoneVerifiedAt = nil
+	user.MarketingConsent = false
+	user.MarketingConsentAt = nil
+}
+
+// hashEmail хэширует email в нижнем регистре — так тот же адрес, введённый
+// иначе, даёт тот же хэш.
+func hashEmail(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email))))
+	return hex.EncodeToString(sum[:])
+}
+
+func exportUser(u *users.User) ExportUser {
+	return ExportUser{
+		UserResponse:       users.ToResponse(u),
+		VerifiedAt:         formatTime(u.VerifiedAt),
+		PhoneVerifiedAt:    formatTime(u.PhoneVerifiedAt),
+		MarketingConsentAt: formatTime(u.MarketingConsentAt),
+		UpdatedAt:          u.UpdatedAt.Format(timeLayout),
+	}
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(timeLayout)
+}
+
+func toErasureResponse(e *Erasure) ErasureResponse {
+	return ErasureResponse{
+		ID:               e.ID,
+		UserID:           e.UserID,
+		EmailHash:        e.EmailHash,
+		RequestedByID:    e.RequestedByID,
+		Channel:          e.Channel,
+		Reason:           e.Reason,
+		AddressesDeleted: e.AddressesDeleted,
+		CreatedAt:        e.CreatedAt.Format(timeLayout),
+	}
+}
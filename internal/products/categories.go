@@ -0,0 +1,66 @@
+package products
+
+import (
+	"bike/internal/categories"
+	"bike/pkg/slug"
+
+	"gorm.io/gorm"
+)
+
+// BackfillCategories переносит свободное поле Type в категории: различные
+// типы с одинаковым slug («pizza», «Pizza ») сливаются в одну корневую
+// категорию, имя берётся у самого частого написания. Продукты привязываются
+// к своей категории, поле Type остаётся как есть. Типы без букв и цифр
+// («—», «?») пропускаются: их продукты остаются без категории.
+//
+// Перенос выполняется, только пока категорий нет совсем (включая удалённые),
+// чтобы повторный запуск миграций не восстанавливал удалённые категории.
+func BackfillCategories(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Unscoped().Model(&categories.Category{}).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return nil
+		}
+
+		var types []struct {
+			Type  string
+			Count int64
+		}
+		err := tx.Model(&Product{}).
+			Select("TRIM(type) AS type, count(*) AS count").
+			Where("TRIM(type) <> ''").
+			Group("TRIM(type)").
+			Order("count DESC, type").
+			Scan(&types).Error
+		if err != nil {
+			return err
+		}
+
+		bySlug := make(map[string]uint)
+		for _, t := range types {
+			sl := slug.Clean(t.Type)
+			if sl == "" {
+				continue
+			}
+			id, ok := bySlug[sl]
+			if !ok {
+				c := categories.Category{Slug: sl, Name: t.Type, Visible: true}
+				if err := tx.Create(&c).Error; err != nil {
+					return err
+				}
+				id = c.ID
+				bySlug[sl] = id
+			}
+			err := tx.Exec(`INSERT INTO `+categories.ProductsJoinTable+` (product_id, category_id)
+				SELECT id, ? FROM products WHERE deleted_at IS NULL AND TRIM(type) = ?
+				ON CONFLICT DO NOTHING`, id, t.Type).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
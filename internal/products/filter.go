@@ -0,0 +1,166 @@
+package products
+
+import (
+	"bike/pkg/paginate"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Варианты сортировки каталога. По умолчанию — новые первыми.
+const (
+	SortNewest     = "newest"
+	SortPrice      = "price"
+	SortPriceDesc  = "price_desc"
+	SortRating     = "rating"
+	SortRatingDesc = "rating_desc"
+	SortName       = "name"
+	SortNameDesc   = "name_desc"
+)
+
+// ProductFilter — фильтры и сортировка GET /products.
+type ProductFilter struct {
+	Type string
+	Tags []string
+	// Все теги (true) или хотя бы один (false)
+	AllTags   bool
+	MinPrice  *int
+	MaxPrice  *int
+	MinRating *float64
+	// Блюдо должно содержать все Ingredients и ни одного из ExcludeIngredients
+	Ingredients        []string
+	ExcludeIngredients []string
+	Sort               string
+	// Продукт должен входить хотя бы в одну из категорий; задаётся маршрутом
+	// /categories/{slug}/products, а не параметром запроса
+	CategoryIDs []uint
+}
+
+// ParamError — неверное значение параметра запроса.
+type ParamError struct {
+	Param  string
+	Reason string
+}
+
+func (e *ParamError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Param, e.Reason)
+}
+
+// ParseFilter разбирает фильтры из query. Списки (tags, ingredients,
+// exclude_ingredients) передаются через запятую или повтором параметра.
+func ParseFilter(q url.Values) (ProductFilter, error) {
+	f := ProductFilter{
+		Type:               strings.TrimSpace(q.Get("type")),
+		Tags:               listParam(q, "tags"),
+		Ingredients:        listParam(q, "ingredients"),
+		ExcludeIngredients: listParam(q, "exclude_ingredients"),
+		Sort:               SortNewest,
+	}
+
+	switch q.Get("tags_match") {
+	case "", "any":
+	case "all":
+		f.AllTags = true
+	default:
+		return f, &ParamError{Param: "tags_match", Reason: "must be any or all"}
+	}
+
+	var err error
+	if f.MinPrice, err = intParam(q, "price_min"); err != nil {
+		return f, err
+	}
+	if f.MaxPrice, err = intParam(q, "price_max"); err != nil {
+		return f, err
+	}
+	if f.MinPrice != nil && f.MaxPrice != nil && *f.MinPrice > *f.MaxPrice {
+		return f, &ParamError{Param: "price_max", Reason: "must not be less than price_min"}
+	}
+
+	if v := q.Get("rating_min"); v != "" {
+		rating, err := strconv.ParseFloat(v, 64)
+		if err != nil || rating < 0 || rating > 5 {
+			return f, &ParamError{Param: "rating_min", Reason: "must be a number from 0 to 5"}
+		}
+		f.MinRating = &rating
+	}
+
+	if v := q.Get("sort"); v != "" {
+		switch v {
+		case SortNewest, SortPrice, SortPriceDesc, SortRating, SortRatingDesc, SortName, SortNameDesc:
+			f.Sort = v
+		default:
+			return f, &ParamError{Param: "sort", Reason: "must be one of newest, price, price_desc, rating, rating_desc, name, name_desc"}
+		}
+	}
+	return f, nil
+}
+
+func intParam(q url.Values, name string) (*int, error) {
+	v := q.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return nil, &ParamError{Param: name, Reason: "must be a non-negative integer"}
+	}
+	return &n, nil
+}
+
+func listParam(q url.Values, name string) []string {
+	var out []string
+	for _, raw := range q[name] {
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				out = append(out, item)
+			}
+		}
+	}
+	return out
+}
+
+// sortKey — колонка сортировки каталога и преобразования её значения для курсора.
+type sortKey struct {
+	column string
+	desc   bool
+	value  func(p *Product) string
+	parse  func(s string) (interface{}, error)
+}
+
+func sortKeyFor(sort string) sortKey {
+	switch sort {
+	case SortPrice, SortPriceDesc:
+		return sortKey{
+			column: "from_price",
+			desc:   sort == SortPriceDesc,
+			value:  func(p *Product) string { return strconv.Itoa(p.FromPrice) },
+			parse:  func(s string) (interface{}, error) { return strconv.Atoi(s) },
+		}
+	case SortRating, SortRatingDesc:
+		return sortKey{
+			column: "rating",
+			desc:   sort == SortRatingDesc,
+			value:  func(p *Product) string { return strconv.FormatFloat(p.Rating, 'g', -1, 64) },
+			parse:  func(s string) (interface{}, error) { return strconv.ParseFloat(s, 64) },
+		}
+	case SortName, SortNameDesc:
+		return sortKey{
+			column: "name",
+			desc:   sort == SortNameDesc,
+			value:  func(p *Product) string { return p.Name },
+			parse:  func(s string) (interface{}, error) { return s, nil },
+		}
+	default:
+		return sortKey{column: "id", desc: true}
+	}
+}
+
+// CursorFor возвращает позицию продукта p в каталоге, отсортированном по sort.
+func CursorFor(p *Product, sort string) *paginate.Cursor {
+	cur := &paginate.Cursor{Sort: sort, ID: p.ID}
+	if key := sortKeyFor(sort); key.value != nil {
+		cur.Key = key.value(p)
+	}
+	return cur
+}
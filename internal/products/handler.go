@@ -2,6 +2,10 @@ package products
 
 import (
 	"bike/configs"
+	"bike/internal/categories"
+	"bike/pkg/middleware"
+	"bike/pkg/paginate"
+	"bike/pkg/rbac"
 	"bike/pkg/req"
 	"bike/pkg/res"
 	"errors"
@@ -13,36 +17,49 @@ type ProductHandlerDeps struct {
 	ProductRepository *ProductRepository
 	ProductService    ProductService
 	Config            *configs.Config
+	Auth              *middleware.AuthDeps
+	CategoryService   categories.CategoryService
+	Cursors           *paginate.Codec
 }
 
 type ProductHandler struct {
 	ProductRepository *ProductRepository
 	service           ProductService
+	categories        categories.CategoryService
+	cursors           *paginate.Codec
 }
 
 func NewProductHandler(router *http.ServeMux, deps ProductHandlerDeps) {
 	handler := &ProductHandler{
 		ProductRepository: deps.ProductRepository,
 		service:           deps.ProductService,
+		categories:        deps.CategoryService,
+		cursors:           deps.Cursors,
 	}
-	router.HandleFunc("POST /products", handler.Create())
 	router.HandleFunc("GET /products", handler.GetAll())
+	router.HandleFunc("GET /products/search", handler.Search())
+	router.HandleFunc("GET /products/suggest", handler.Suggest())
 	router.HandleFunc("GET /products/{slug}", handler.GoTo())
-	router.Handle("PATCH /products/{slug}", handler.Update())
-	router.HandleFunc("DELETE /products/{slug}", handler.Delete())
+	router.HandleFunc("GET /categories/{slug}/products", handler.CategoryProducts())
 
-	router.HandleFunc("POST /products/{slug}/change", handler.Change())
+	// Изменение каталога — только для ролей или API-ключей с правом products:write
+	router.Handle("POST /products", middleware.IsAuthenticatedOrAPIKey(middleware.RequirePermission(handler.Create(), rbac.PermProductsWrite), deps.Auth))
+	router.Handle("PATCH /products/{slug}", middleware.IsAuthenticatedOrAPIKey(middleware.RequirePermission(handler.Update(), rbac.PermProductsWrite), deps.Auth))
+	router.Handle("DELETE /products/{slug}", middleware.IsAuthenticatedOrAPIKey(middleware.RequirePermission(handler.Delete(), rbac.PermProductsWrite), deps.Auth))
+	router.Handle("POST /products/{slug}/change", middleware.IsAuthenticatedOrAPIKey(middleware.RequirePermission(handler.Change(), rbac.PermProductsWrite), deps.Auth))
 }
 
 // Create godoc
 // @Summary Создать продукт (админ)
-// @Description Создаёт новый продукт
+// @Description Создаёт новый продукт, можно сразу с вариантами (размер, объём). С вариантами цена продукта берётся у варианта по умолчанию, а from_price — минимальная цена варианта
 // @Tags products,admin
 // @Accept json
 // @Produce json
 // @Param request body products.ProductCreateRequest true "Product data"
 // @Success 201 {object} products.Product
 // @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /products [post]
 func (handler *ProductHandler) Create() http.HandlerFunc {
@@ -69,24 +86,183 @@ func (handler *ProductHandler) Create() http.HandlerFunc {
 
 // GetAll godoc
 // @Summary Список продуктов
-// @Description Возвращает список продуктов (пагинация через limit/offset)
+// @Description Возвращает список продуктов с фильтрами и сортировкой.
+// @Description Пагинация через limit/offset или, если передан cursor (для первой страницы — пустой), по курсору: next_cursor/prev_cursor в ответе и заголовок Link.
+// @Description Списки передаются через запятую. При неверном значении ответ 400 с именем параметра в поле param
 // @Tags products,open
 // @Produce json
-// @Param limit query int false "limit"
+// @Param limit query int false "limit (в режиме курсора по умолчанию 20, до 100)"
 // @Param offset query int false "offset"
-// @Success 200 {array} products.Product
+// @Param cursor query string false "курсор страницы"
+// @Param type query string false "тип блюда"
+// @Param tags query string false "теги через запятую"
+// @Param tags_match query string false "any — хотя бы один тег, all — все теги" default(any)
+// @Param price_min query int false "минимальная цена (from_price — самый дешёвый вариант)"
+// @Param price_max query int false "максимальная цена (from_price)"
+// @Param rating_min query number false "минимальный рейтинг (0–5)"
+// @Param ingredients query string false "обязательные ингредиенты через запятую"
+// @Param exclude_ingredients query string false "исключаемые ингредиенты через запятую"
+// @Param sort query string false "newest, price, price_desc, rating, rating_desc, name, name_desc" default(newest)
+// @Success 200 {object} products.ProductListResponse
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /products [get]
 func (handler *ProductHandler) GetAll() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handler.list(w, r, nil)
+	}
+}
+
+// CategoryProducts godoc
+// @Summary Продукты категории
+// @Description Продукты видимой категории и всех её видимых подкатегорий. Фильтры, сортировка и пагинация — как у GET /products
+// @Tags products,categories,open
+// @Produce json
+// @Param slug path string true "slug категории"
+// @Param limit query int false "limit (в режиме курсора по умолчанию 20, до 100)"
+// @Param offset query int false "offset"
+// @Param cursor query string false "курсор страницы"
+// @Param type query string false "тип блюда"
+// @Param tags query string false "теги через запятую"
+// @Param tags_match query string false "any — хотя бы один тег, all — все теги" default(any)
+// @Param price_min query int false "минимальная цена (from_price — самый дешёвый вариант)"
+// @Param price_max query int false "максимальная цена (from_price)"
+// @Param rating_min query number false "минимальный рейтинг (0–5)"
+// @Param ingredients query string false "обязательные ингредиенты через запятую"
+// @Param exclude_ingredients query string false "исключаемые ингредиенты через запятую"
+// @Param sort query string false "newest, price, price_desc, rating, rating_desc, name, name_desc" default(newest)
+// @Success 200 {object} products.ProductListResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /categories/{slug}/products [get]
+func (handler *ProductHandler) CategoryProducts() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ids, err := handler.categories.SubtreeIDs(r.Context(), r.PathValue("slug"))
+		if err != nil {
+			if errors.Is(err, categories.ErrNotFound) {
+				res.Json(w, map[string]string{"error": "category not found"}, http.StatusNotFound)
+				return
+			}
+			res.Json(w, map[string]string{"error": "failed to get category"}, http.StatusInternalServerError)
+			return
+		}
+		handler.list(w, r, ids)
+	}
+}
+
+// list отвечает страницей каталога; categoryIDs ограничивает его категориями.
+func (handler *ProductHandler) list(w http.ResponseWriter, r *http.Request, categoryIDs []uint) {
+	q := r.URL.Query()
+	limit, offset := 0, 0
+	if v := q.Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			limit = n
+		} else {
+			res.Json(w, map[string]string{"error": "invalid limit", "param": "limit"}, http.StatusBadRequest)
+			return
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			offset = n
+		} else {
+			res.Json(w, map[string]string{"error": "invalid offset", "param": "offset"}, http.StatusBadRequest)
+			return
+		}
+	}
+
+	filter, err := ParseFilter(q)
+	if err != nil {
+		var paramErr *ParamError
+		if errors.As(err, &paramErr) {
+			res.Json(w, map[string]string{"error": paramErr.Error(), "param": paramErr.Param}, http.StatusBadRequest)
+			return
+		}
+		res.Json(w, map[string]string{"error": err.Error()}, http.StatusBadRequest)
+		return
+	}
+	filter.CategoryIDs = categoryIDs
+
+	if q.Has("cursor") {
+		handler.getPage(w, r, filter, limit)
+		return
+	}
+
+	list, total, err := handler.service.GetAll(r.Context(), filter, limit, offset)
+	if err != nil {
+		res.Json(w, map[string]string{"error": "failed to list products"}, http.StatusInternalServerError)
+		return
+	}
+	res.Json(w, ProductListResponse{
+		Products: list,
+		Total:    total,
+		Limit:    limit,
+		Offset:   offset,
+	}, http.StatusOK)
+}
+
+// getPage отвечает страницей каталога в режиме курсора.
+func (handler *ProductHandler) getPage(w http.ResponseWriter, r *http.Request, filter ProductFilter, limit int) {
+	if limit == 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	cursor, err := handler.cursors.Decode(r.URL.Query().Get("cursor"))
+	if err != nil {
+		res.Json(w, map[string]string{"error": err.Error(), "param": "cursor"}, http.StatusBadRequest)
+		return
+	}
+
+	list, total, more, err := handler.service.GetPage(r.Context(), filter, cursor, limit)
+	if err != nil {
+		if errors.Is(err, paginate.ErrInvalidCursor) {
+			res.Json(w, map[string]string{"error": err.Error(), "param": "cursor"}, http.StatusBadRequest)
+			return
+		}
+		res.Json(w, map[string]string{"error": "failed to list products"}, http.StatusInternalServerError)
+		return
+	}
+
+	var first, last *paginate.Cursor
+	if len(list) > 0 {
+		first, last = CursorFor(&list[0], filter.Sort), CursorFor(&list[len(list)-1], filter.Sort)
+	}
+	next, prev := handler.cursors.Neighbours(cursor, more, first, last)
+	paginate.SetLinkHeader(w, r, next, prev)
+	res.Json(w, ProductListResponse{
+		Products:   list,
+		Total:      total,
+		Limit:      limit,
+		NextCursor: next,
+		PrevCursor: prev,
+	}, http.StatusOK)
+}
+
+// Search godoc
+// @Summary Поиск продуктов
+// @Description Полнотекстовый поиск по названию, типу, тегам и ингредиентам с учётом русской морфологии.
+// @Description Каждое слово ищется по префиксу («марг» найдёт «Маргарита»). Результаты отсортированы по релевантности, совпадения подсвечены тегом <mark>
+// @Tags products,open
+// @Produce json
+// @Param q query string true "поисковый запрос"
+// @Param limit query int false "limit" default(20)
+// @Param offset query int false "offset"
+// @Success 200 {object} products.ProductSearchResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /products/search [get]
+func (handler *ProductHandler) Search() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		q := r.URL.Query()
-		limit, offset := 0, 0
+		limit, offset := 20, 0
 		if v := q.Get("limit"); v != "" {
-			if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= 100 {
 				limit = n
 			} else {
-				res.Json(w, map[string]string{"error": "invalid limit"}, http.StatusBadRequest)
+				res.Json(w, map[string]string{"error": "invalid limit", "param": "limit"}, http.StatusBadRequest)
 				return
 			}
 		}
@@ -94,17 +270,70 @@ func (handler *ProductHandler) GetAll() http.HandlerFunc {
 			if n, err := strconv.Atoi(v); err == nil && n >= 0 {
 				offset = n
 			} else {
-				res.Json(w, map[string]string{"error": "invalid offset"}, http.StatusBadRequest)
+				res.Json(w, map[string]string{"error": "invalid offset", "param": "offset"}, http.StatusBadRequest)
 				return
 			}
 		}
 
-		list, err := handler.service.GetAll(r.Context(), limit, offset)
+		list, total, err := handler.service.Search(r.Context(), q.Get("q"), limit, offset)
 		if err != nil {
-			res.Json(w, map[string]string{"error": "failed to list products"}, http.StatusInternalServerError)
+			if errors.Is(err, ErrValidation) {
+				res.Json(w, map[string]string{"error": err.Error(), "param": "q"}, http.StatusBadRequest)
+				return
+			}
+			res.Json(w, map[string]string{"error": "failed to search products"}, http.StatusInternalServerError)
 			return
 		}
-		res.Json(w, list, http.StatusOK)
+		if list == nil {
+			list = []SearchResult{}
+		}
+		res.Json(w, ProductSearchResponse{
+			Results: list,
+			Total:   total,
+			Limit:   limit,
+			Offset:  offset,
+		}, http.StatusOK)
+	}
+}
+
+// Suggest godoc
+// @Summary Подсказки по названию
+// @Description Подсказки для строки поиска с учётом опечаток. Латиница находит блюда с кириллическими названиями и наоборот («pepperony» → «Пепперони»).
+// @Description Для запросов короче двух символов возвращается пустой список
+// @Tags products,open
+// @Produce json
+// @Param q query string true "начало или часть названия"
+// @Param limit query int false "limit (до 20)" default(10)
+// @Success 200 {object} products.SuggestResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /products/suggest [get]
+func (handler *ProductHandler) Suggest() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		limit := 10
+		if v := q.Get("limit"); v != "" {
+			if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= 20 {
+				limit = n
+			} else {
+				res.Json(w, map[string]string{"error": "invalid limit", "param": "limit"}, http.StatusBadRequest)
+				return
+			}
+		}
+
+		list, err := handler.service.Suggest(r.Context(), q.Get("q"), limit)
+		if err != nil {
+			if errors.Is(err, ErrValidation) {
+				res.Json(w, map[string]string{"error": err.Error(), "param": "q"}, http.StatusBadRequest)
+				return
+			}
+			res.Json(w, map[string]string{"error": "failed to suggest products"}, http.StatusInternalServerError)
+			return
+		}
+		if list == nil {
+			list = []Suggestion{}
+		}
+		res.Json(w, SuggestResponse{Suggestions: list}, http.StatusOK)
 	}
 }
 
@@ -140,6 +369,7 @@ func (handler *ProductHandler) GoTo() http.HandlerFunc {
 
 // Update godoc
 // @Summary Обновить продукт (админ)
+// @Description Частичное обновление. variants заменяет набор вариантов целиком: вариант с id обновляется, без id создаётся, отсутствующие удаляются
 // @Tags products,admin
 // @Accept json
 // @Produce json
@@ -147,6 +377,8 @@ func (handler *ProductHandler) GoTo() http.HandlerFunc {
 // @Param request body products.ProductUpdateRequest true "Fields to update"
 // @Success 200 {object} products.Product
 // @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Router /products/{slug} [patch]
 func (handler *ProductHandler) Update() http.HandlerFunc {
@@ -188,6 +420,8 @@ func (handler *ProductHandler) Update() http.HandlerFunc {
 // @Tags products,admin
 // @Param slug path string true "slug"
 // @Success 204
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Router /products/{slug} [delete]
 func (handler *ProductHandler) Delete() http.HandlerFunc {
@@ -219,6 +453,8 @@ func (handler *ProductHandler) Delete() http.HandlerFunc {
 // @Param request body products.ProductSlugUpdateRequest true "new slug"
 // @Success 200 {object} products.Product
 // @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Router /products/{slug}/change [post]
 func (handler *ProductHandler) Change() http.HandlerFunc {
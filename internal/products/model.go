@@ -1,18 +1,93 @@
 package products
 
 import (
+	"bike/internal/categories"
+
 	"github.com/lib/pq"
 	"gorm.io/gorm"
 )
 
 type Product struct {
-	gorm.Model  `swaggerignore:"true"`
-	Slug        string         `json:"slug" gorm:"size:128;uniqueIndex;not null"`
-	Name        string         `json:"name" gorm:"not null;uniqueIndex"`
-	Type        string         `json:"type" gorm:"size:64;index"`
-	Price       int            `json:"price"`
-	Ingredients pq.StringArray `json:"ingredients" gorm:"type:text[]" swaggerignore:"true"`
-	Tags        pq.StringArray `json:"tags" gorm:"type:text[]" swaggerignore:"true"`
-	Image       string         `json:"image"`
-	Rating      float64        `json:"rating"`
+	gorm.Model `swaggerignore:"true"`
+	Slug       string `json:"slug" gorm:"size:128;uniqueIndex;not null"`
+	Name       string `json:"name" gorm:"not null;uniqueIndex;index:idx_products_name_trgm,type:gin,expression:name gin_trgm_ops"`
+	// Транслитерация имени (slug.Slugify) для подсказок: латиница находит кириллицу и наоборот
+	NameTranslit string `json:"-" gorm:"size:255;index:idx_products_name_translit_trgm,type:gin,expression:name_translit gin_trgm_ops" swaggerignore:"true"`
+	// Свободный тип блюда; для навигации используются Categories
+	Type string `json:"type" gorm:"size:64;index"`
+	// Цена продукта; при наличии вариантов — цена варианта по умолчанию
+	Price int `json:"price" gorm:"index"`
+	// Минимальная цена среди вариантов (или Price без вариантов) — «от 499 ₽».
+	// Вычисляется при сохранении, по ней фильтрует и сортирует каталог
+	FromPrice   int                   `json:"from_price" gorm:"not null;default:0;index"`
+	Ingredients pq.StringArray        `json:"ingredients" gorm:"type:text[];index:,type:gin" swaggerignore:"true"`
+	Tags        pq.StringArray        `json:"tags" gorm:"type:text[];index:,type:gin" swaggerignore:"true"`
+	Image       string                `json:"image"`
+	Rating      float64               `json:"rating"`
+	Categories  []categories.Category `json:"categories" gorm:"many2many:product_categories"`
+	Variants    []ProductVariant      `json:"variants"`
+	// Собственные группы модификаторов; вместе с группами категорий — см. GET /products/{slug}/modifiers
+	ModifierGroups []ModifierGroup `json:"-" gorm:"many2many:product_modifier_groups"`
+	// Полнотекстовый индекс; заполняется SQL-выражением searchVectorSQL, из Go не читается и не пишется
+	SearchVector string `json:"-" gorm:"type:tsvector;index:,type:gin;->:false;<-:false" swaggerignore:"true"`
+}
+
+// ProductVariant — вариант продукта со своей ценой: размер пиццы, объём напитка.
+type ProductVariant struct {
+	gorm.Model `swaggerignore:"true"`
+	ProductID  uint   `json:"-" gorm:"not null;index;uniqueIndex:idx_product_variants_default,where:is_default AND deleted_at IS NULL"`
+	Name       string `json:"name" gorm:"size:64;not null" example:"30 см"`
+	// Артикул; пустой допускается, непустой уникален
+	SKU         string `json:"sku" gorm:"size:64;uniqueIndex:idx_product_variants_sku,where:sku <> '' AND deleted_at IS NULL" example:"PZ-MARG-30"`
+	Price       int    `json:"price" gorm:"not null" example:"599"`
+	WeightGrams int    `json:"weight_g" example:"650"`
+	VolumeML    int    `json:"volume_ml" example:"0"`
+	// Вариант по умолчанию — ровно один у продукта с вариантами (см. индекс на ProductID)
+	IsDefault bool `json:"is_default" gorm:"not null"`
+	SortOrder int  `json:"sort_order" gorm:"not null;default:0"`
+}
+
+// ModifierGroup — группа модификаторов: «Борт», «Добавки», «Убрать ингредиенты».
+// Привязывается к продуктам и категориям; группа категории действует на все
+// продукты категории и её подкатегорий.
+type ModifierGroup struct {
+	gorm.Model `swaggerignore:"true"`
+	Name       string `json:"name" gorm:"size:64;not null" example:"Добавки"`
+	// Сколько модификаторов группы нужно выбрать: от MinSelect до MaxSelect
+	// (0 — без ограничения). Убираемые ингредиенты не считаются
+	MinSelect  int                   `json:"min_select" gorm:"not null;default:0" example:"0"`
+	MaxSelect  int                   `json:"max_select" gorm:"not null;default:0" example:"3"`
+	SortOrder  int                   `json:"sort_order" gorm:"not null;default:0"`
+	Modifiers  []Modifier            `json:"modifiers" gorm:"foreignKey:GroupID"`
+	Categories []categories.Category `json:"-" gorm:"many2many:category_modifier_groups"`
+}
+
+// Modifier — модификатор с изменением цены. Убираемый (Removable) — ингредиент,
+// который уже есть в блюде: выбрать его значит убрать, PriceDelta обычно 0 или меньше.
+type Modifier struct {
+	gorm.Model `swaggerignore:"true"`
+	GroupID    uint   `json:"group_id" gorm:"not null;index"`
+	Name       string `json:"name" gorm:"size:64;not null" example:"Двойной сыр"`
+	PriceDelta int    `json:"price_delta" gorm:"not null;default:0" example:"90"`
+	Removable  bool   `json:"removable" gorm:"not null"`
+	SortOrder  int    `json:"sort_order" gorm:"not null;default:0"`
+}
+
+// SearchResult — продукт, найденный полнотекстовым поиском.
+type SearchResult struct {
+	Product `gorm:"embedded"`
+	// Релевантность (ts_rank_cd), больше — выше
+	Rank float64 `json:"rank"`
+	// Имя с подсвеченными совпадениями (<mark>)
+	NameHighlight string `json:"name_highlight"`
+	// Фрагмент типа, тегов и ингредиентов с подсвеченными совпадениями
+	Snippet string `json:"snippet"`
+}
+
+// Suggestion — подсказка для строки поиска.
+type Suggestion struct {
+	Name string `json:"name" example:"Маргарита"`
+	Slug string `json:"slug" example:"margarita"`
+	// Похожесть на запрос (word_similarity) от 0 до 1
+	Score float64 `json:"score" example:"0.67"`
 }
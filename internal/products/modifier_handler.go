@@ -0,0 +1,305 @@
+package products
+
+import (
+	"bike/pkg/middleware"
+	"bike/pkg/rbac"
+	"bike/pkg/req"
+	"bike/pkg/res"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+type ModifierHandlerDeps struct {
+	ModifierService ModifierService
+	Auth            *middleware.AuthDeps
+}
+
+type ModifierHandler struct {
+	service ModifierService
+}
+
+func NewModifierHandler(router *http.ServeMux, deps ModifierHandlerDeps) {
+	handler := &ModifierHandler{
+		service: deps.ModifierService,
+	}
+	router.HandleFunc("GET /products/{slug}/modifiers", handler.ForProduct())
+	router.HandleFunc("POST /products/{slug}/price", handler.Quote())
+
+	// Управление модификаторами — право products:write
+	router.Handle("GET /modifier-groups", middleware.IsAuthenticatedOrAPIKey(middleware.RequirePermission(handler.List(), rbac.PermProductsWrite), deps.Auth))
+	router.Handle("POST /modifier-groups", middleware.IsAuthenticatedOrAPIKey(middleware.RequirePermission(handler.Create(), rbac.PermProductsWrite), deps.Auth))
+	router.Handle("GET /modifier-groups/{id}", middleware.IsAuthenticatedOrAPIKey(middleware.RequirePermission(handler.Get(), rbac.PermProductsWrite), deps.Auth))
+	router.Handle("PATCH /modifier-groups/{id}", middleware.IsAuthenticatedOrAPIKey(middleware.RequirePermission(handler.Update(), rbac.PermProductsWrite), deps.Auth))
+	router.Handle("DELETE /modifier-groups/{id}", middleware.IsAuthenticatedOrAPIKey(middleware.RequirePermission(handler.Delete(), rbac.PermProductsWrite), deps.Auth))
+	router.Handle("PUT /products/{slug}/modifier-groups", middleware.IsAuthenticatedOrAPIKey(middleware.RequirePermission(handler.AttachToProduct(), rbac.PermProductsWrite), deps.Auth))
+	router.Handle("PUT /categories/{slug}/modifier-groups", middleware.IsAuthenticatedOrAPIKey(middleware.RequirePermission(handler.AttachToCategory(), rbac.PermProductsWrite), deps.Auth))
+}
+
+// List godoc
+// @Summary Группы модификаторов (админ)
+// @Tags modifiers,admin
+// @Produce json
+// @Success 200 {object} products.ModifierGroupsResponse
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /modifier-groups [get]
+func (handler *ModifierHandler) List() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		list, err := handler.service.List(r.Context())
+		if err != nil {
+			res.Json(w, map[string]string{"error": "failed to list modifier groups"}, http.StatusInternalServerError)
+			return
+		}
+		res.Json(w, ModifierGroupsResponse{Groups: list}, http.StatusOK)
+	}
+}
+
+// Create godoc
+// @Summary Создать группу модификаторов (админ)
+// @Description Группа с правилами выбора (min_select..max_select, 0 — без ограничения) и модификаторами. Убираемые ингредиенты (removable) в правилах не учитываются
+// @Tags modifiers,admin
+// @Accept json
+// @Produce json
+// @Param request body products.ModifierGroupCreateRequest true "Группа"
+// @Success 201 {object} products.ModifierGroup
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /modifier-groups [post]
+func (handler *ModifierHandler) Create() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := req.HandleBody[ModifierGroupCreateRequest](&w, r)
+		if err != nil {
+			return
+		}
+		created, err := handler.service.Create(r.Context(), *body)
+		if err != nil {
+			if errors.Is(err, ErrValidation) {
+				res.Json(w, map[string]string{"error": err.Error()}, http.StatusBadRequest)
+				return
+			}
+			res.Json(w, map[string]string{"error": "failed to create modifier group"}, http.StatusInternalServerError)
+			return
+		}
+		res.Json(w, created, http.StatusCreated)
+	}
+}
+
+// Get godoc
+// @Summary Группа модификаторов (админ)
+// @Tags modifiers,admin
+// @Produce json
+// @Param id path int true "ID группы"
+// @Success 200 {object} products.ModifierGroup
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /modifier-groups/{id} [get]
+func (handler *ModifierHandler) Get() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := groupID(w, r)
+		if !ok {
+			return
+		}
+		g, err := handler.service.Get(r.Context(), id)
+		if err != nil {
+			writeModifierError(w, err, "failed to get modifier group")
+			return
+		}
+		res.Json(w, g, http.StatusOK)
+	}
+}
+
+// Update godoc
+// @Summary Обновить группу модификаторов (админ)
+// @Description Частичное обновление. modifiers заменяет набор целиком: модификатор с id обновляется, без id создаётся, отсутствующие удаляются
+// @Tags modifiers,admin
+// @Accept json
+// @Produce json
+// @Param id path int true "ID группы"
+// @Param request body products.ModifierGroupUpdateRequest true "Поля для обновления"
+// @Success 200 {object} products.ModifierGroup
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /modifier-groups/{id} [patch]
+func (handler *ModifierHandler) Update() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := groupID(w, r)
+		if !ok {
+			return
+		}
+		body, err := req.HandleBody[ModifierGroupUpdateRequest](&w, r)
+		if err != nil {
+			return
+		}
+		updated, err := handler.service.Update(r.Context(), id, *body)
+		if err != nil {
+			writeModifierError(w, err, "failed to update modifier group")
+			return
+		}
+		res.Json(w, updated, http.StatusOK)
+	}
+}
+
+// Delete godoc
+// @Summary Удалить группу модификаторов (админ)
+// @Description Удаляет группу с модификаторами и отвязывает её от продуктов и категорий
+// @Tags modifiers,admin
+// @Param id path int true "ID группы"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /modifier-groups/{id} [delete]
+func (handler *ModifierHandler) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := groupID(w, r)
+		if !ok {
+			return
+		}
+		if err := handler.service.Delete(r.Context(), id); err != nil {
+			writeModifierError(w, err, "failed to delete modifier group")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// AttachToProduct godoc
+// @Summary Привязать группы модификаторов к продукту (админ)
+// @Description Заменяет собственные группы продукта; группы категорий не затрагиваются
+// @Tags modifiers,admin
+// @Accept json
+// @Produce json
+// @Param slug path string true "slug продукта"
+// @Param request body products.ModifierGroupsRequest true "ID групп"
+// @Success 200 {object} products.ModifierGroupsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /products/{slug}/modifier-groups [put]
+func (handler *ModifierHandler) AttachToProduct() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := req.HandleBody[ModifierGroupsRequest](&w, r)
+		if err != nil {
+			return
+		}
+		list, err := handler.service.AttachToProduct(r.Context(), r.PathValue("slug"), body.GroupIDs)
+		if err != nil {
+			writeModifierError(w, err, "failed to attach modifier groups")
+			return
+		}
+		res.Json(w, ModifierGroupsResponse{Groups: list}, http.StatusOK)
+	}
+}
+
+// AttachToCategory godoc
+// @Summary Привязать группы модификаторов к категории (админ)
+// @Description Группы категории действуют на все продукты категории и её подкатегорий
+// @Tags modifiers,categories,admin
+// @Accept json
+// @Produce json
+// @Param slug path string true "slug категории"
+// @Param request body products.ModifierGroupsRequest true "ID групп"
+// @Success 200 {object} products.ModifierGroupsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /categories/{slug}/modifier-groups [put]
+func (handler *ModifierHandler) AttachToCategory() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := req.HandleBody[ModifierGroupsRequest](&w, r)
+		if err != nil {
+			return
+		}
+		list, err := handler.service.AttachToCategory(r.Context(), r.PathValue("slug"), body.GroupIDs)
+		if err != nil {
+			writeModifierError(w, err, "failed to attach modifier groups")
+			return
+		}
+		res.Json(w, ModifierGroupsResponse{Groups: list}, http.StatusOK)
+	}
+}
+
+// ForProduct godoc
+// @Summary Модификаторы продукта
+// @Description Группы модификаторов продукта: собственные и унаследованные от категорий
+// @Tags products,modifiers,open
+// @Produce json
+// @Param slug path string true "slug продукта"
+// @Success 200 {object} products.ModifierGroupsResponse
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /products/{slug}/modifiers [get]
+func (handler *ModifierHandler) ForProduct() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		list, err := handler.service.ForProduct(r.Context(), r.PathValue("slug"))
+		if err != nil {
+			writeModifierError(w, err, "failed to list modifiers")
+			return
+		}
+		res.Json(w, ModifierGroupsResponse{Groups: list}, http.StatusOK)
+	}
+}
+
+// Quote godoc
+// @Summary Рассчитать цену позиции
+// @Description Проверяет выбранные вариант и модификаторы по правилам групп и возвращает итоговую цену позиции
+// @Tags products,modifiers,open
+// @Accept json
+// @Produce json
+// @Param slug path string true "slug продукта"
+// @Param request body products.PriceRequest true "Выбор покупателя"
+// @Success 200 {object} products.PriceQuote
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /products/{slug}/price [post]
+func (handler *ModifierHandler) Quote() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := req.HandleBody[PriceRequest](&w, r)
+		if err != nil {
+			return
+		}
+		quote, err := handler.service.Quote(r.Context(), r.PathValue("slug"), *body)
+		if err != nil {
+			writeModifierError(w, err, "failed to calculate price")
+			return
+		}
+		res.Json(w, quote, http.StatusOK)
+	}
+}
+
+func groupID(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 32)
+	if err != nil {
+		res.Json(w, map[string]string{"error": "invalid id"}, http.StatusBadRequest)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func writeModifierError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrValidation):
+		res.Json(w, map[string]string{"error": err.Error()}, http.StatusBadRequest)
+	case errors.Is(err, ErrNotFound):
+		res.Json(w, map[string]string{"error": err.Error()}, http.StatusNotFound)
+	default:
+		res.Json(w, map[string]string{"error": fallback}, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,168 @@
+package products
+
+import (
+	"bike/internal/categories"
+	"bike/pkg/db"
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// Таблица связи групп модификаторов с продуктами (many2many в Product);
+// связь с категориями — categories.ModifierGroupsJoinTable
+const productModifierGroupsTable = "product_modifier_groups"
+
+type ModifierRepository struct {
+	Database *db.Db
+}
+
+func NewModifierRepository(database *db.Db) *ModifierRepository {
+	return &ModifierRepository{
+		Database: database,
+	}
+}
+
+// Transaction выполняет fn с репозиторием, все запросы которого идут в одной
+// транзакции. Ошибка из fn откатывает транзакцию.
+func (r *ModifierRepository) Transaction(ctx context.Context, fn func(tx *ModifierRepository) error) error {
+	return r.Database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(NewModifierRepository(&db.Db{DB: tx}))
+	})
+}
+
+func (r *ModifierRepository) Create(ctx context.Context, g *ModifierGroup) (*ModifierGroup, error) {
+	if err := r.Database.DB.WithContext(ctx).Create(g).Error; err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func (r *ModifierRepository) FindByID(ctx context.Context, id uint) (*ModifierGroup, error) {
+	var g ModifierGroup
+	res := r.Database.DB.WithContext(ctx).Preload("Modifiers", orderModifiers).First(&g, id)
+	if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &g, res.Error
+}
+
+// List возвращает все группы с модификаторами в порядке показа.
+func (r *ModifierRepository) List(ctx context.Context) ([]ModifierGroup, error) {
+	var list []ModifierGroup
+	err := r.Database.DB.WithContext(ctx).Preload("Modifiers", orderModifiers).
+		Order("sort_order, id").Find(&list).Error
+	return list, err
+}
+
+// CountByIDs возвращает, сколько групп из ids существует.
+func (r *ModifierRepository) CountByIDs(ctx context.Context, ids []uint) (int64, error) {
+	var cnt int64
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	err := r.Database.DB.WithContext(ctx).Model(&ModifierGroup{}).Where("id IN ?", ids).Count(&cnt).Error
+	return cnt, err
+}
+
+// ForProduct возвращает группы, привязанные к продукту или к одной из категорий categoryIDs.
+func (r *ModifierRepository) ForProduct(ctx context.Context, productID uint, categoryIDs []uint) ([]ModifierGroup, error) {
+	q := r.Database.DB.WithContext(ctx).Preload("Modifiers", orderModifiers)
+	own := "id IN (SELECT modifier_group_id FROM " + productModifierGroupsTable + " WHERE product_id = ?)"
+	if len(categoryIDs) > 0 {
+		q = q.Where(own+" OR id IN (SELECT modifier_group_id FROM "+categories.ModifierGroupsJoinTable+" WHERE category_id IN ?)", productID, categoryIDs)
+	} else {
+		q = q.Where(own, productID)
+	}
+	var list []ModifierGroup
+	err := q.Order("sort_order, id").Find(&list).Error
+	return list, err
+}
+
+// ListForCategory возвращает группы, привязанные непосредственно к категории.
+func (r *ModifierRepository) ListForCategory(ctx context.Context, categoryID uint) ([]ModifierGroup, error) {
+	var list []ModifierGroup
+	err := r.Database.DB.WithContext(ctx).Preload("Modifiers", orderModifiers).
+		Where("id IN (SELECT modifier_group_id FROM "+categories.ModifierGroupsJoinTable+" WHERE category_id = ?)", categoryID).
+		Order("sort_order, id").Find(&list).Error
+	return list, err
+}
+
+// Save сохраняет поля группы; модификаторы меняются через SyncModifiers.
+func (r *ModifierRepository) Save(ctx context.Context, g *ModifierGroup) (*ModifierGroup, error) {
+	if err := r.Database.DB.WithContext(ctx).Omit("Modifiers", "Categories").Save(g).Error; err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// SyncModifiers приводит модификаторы группы к list: с ID обновляются,
+// без ID создаются, остальные удаляются.
+func (r *ModifierRepository) SyncModifiers(ctx context.Context, g *ModifierGroup, list []Modifier) error {
+	keep := make([]uint, 0, len(list))
+	for _, m := range list {
+		if m.ID != 0 {
+			keep = append(keep, m.ID)
+		}
+	}
+	return r.Database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		del := tx.Where("group_id = ?", g.ID)
+		if len(keep) > 0 {
+			del = del.Where("id NOT IN ?", keep)
+		}
+		if err := del.Delete(&Modifier{}).Error; err != nil {
+			return err
+		}
+		for i := range list {
+			list[i].GroupID = g.ID
+			if err := tx.Save(&list[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Delete удаляет группу, её модификаторы и привязки.
+func (r *ModifierRepository) Delete(ctx context.Context, g *ModifierGroup) error {
+	return r.Database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, table := range []string{productModifierGroupsTable, categories.ModifierGroupsJoinTable} {
+			if err := tx.Exec("DELETE FROM "+table+" WHERE modifier_group_id = ?", g.ID).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("group_id = ?", g.ID).Delete(&Modifier{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(g).Error
+	})
+}
+
+// ReplaceForProduct заменяет группы, привязанные к продукту.
+func (r *ModifierRepository) ReplaceForProduct(ctx context.Context, productID uint, groupIDs []uint) error {
+	return r.replaceLinks(ctx, productModifierGroupsTable, "product_id", productID, groupIDs)
+}
+
+// ReplaceForCategory заменяет группы, привязанные к категории.
+func (r *ModifierRepository) ReplaceForCategory(ctx context.Context, categoryID uint, groupIDs []uint) error {
+	return r.replaceLinks(ctx, categories.ModifierGroupsJoinTable, "category_id", categoryID, groupIDs)
+}
+
+func (r *ModifierRepository) replaceLinks(ctx context.Context, table, column string, ownerID uint, groupIDs []uint) error {
+	return r.Database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM "+table+" WHERE "+column+" = ?", ownerID).Error; err != nil {
+			return err
+		}
+		for _, id := range groupIDs {
+			err := tx.Exec("INSERT INTO "+table+" ("+column+", modifier_group_id) VALUES (?, ?) ON CONFLICT DO NOTHING", ownerID, id).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func orderModifiers(db *gorm.DB) *gorm.DB {
+	return db.Order("sort_order, id")
+}
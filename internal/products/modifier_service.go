@@ -0,0 +1,233 @@
+package products
+
+import (
+	"bike/internal/categories"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type ModifierService interface {
+	List(ctx context.Context) ([]ModifierGroup, error)
+	Get(ctx context.Context, id uint) (*ModifierGroup, error)
+	Create(ctx context.Context, in ModifierGroupCreateRequest) (*ModifierGroup, error)
+	Update(ctx context.Context, id uint, in ModifierGroupUpdateRequest) (*ModifierGroup, error)
+	Delete(ctx context.Context, id uint) error
+	AttachToProduct(ctx context.Context, slug string, groupIDs []uint) ([]ModifierGroup, error)
+	AttachToCategory(ctx context.Context, slug string, groupIDs []uint) ([]ModifierGroup, error)
+	ForProduct(ctx context.Context, slug string) ([]ModifierGroup, error)
+	Quote(ctx context.Context, slug string, in PriceRequest) (*PriceQuote, error)
+}
+
+type ModifierServiceDeps struct {
+	ModifierRepository *ModifierRepository
+	ProductRepository  *ProductRepository
+	CategoryRepository *categories.CategoryRepository
+}
+
+type modifierService struct {
+	repo       *ModifierRepository
+	products   *ProductRepository
+	categories *categories.CategoryRepository
+}
+
+func NewModifierService(deps ModifierServiceDeps) ModifierService {
+	return &modifierService{
+		repo:       deps.ModifierRepository,
+		products:   deps.ProductRepository,
+		categories: deps.CategoryRepository,
+	}
+}
+
+func (s *modifierService) List(ctx context.Context) ([]ModifierGroup, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *modifierService) Get(ctx context.Context, id uint) (*ModifierGroup, error) {
+	g, err := s.repo.FindByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return g, err
+}
+
+func (s *modifierService) Create(ctx context.Context, in ModifierGroupCreateRequest) (*ModifierGroup, error) {
+	g := &ModifierGroup{
+		Name:      strings.TrimSpace(in.Name),
+		MinSelect: in.MinSelect,
+		MaxSelect: in.MaxSelect,
+		SortOrder: in.SortOrder,
+	}
+	if g.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrValidation)
+	}
+	modifiers, err := buildModifiers(in.Modifiers, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkGroupRules(g, modifiers); err != nil {
+		return nil, err
+	}
+	g.Modifiers = modifiers
+	return s.repo.Create(ctx, g)
+}
+
+func (s *modifierService) Update(ctx context.Context, id uint, in ModifierGroupUpdateRequest) (*ModifierGroup, error) {
+	if in.Name == nil && in.MinSelect == nil && in.MaxSelect == nil && in.SortOrder == nil && in.Modifiers == nil {
+		return nil, fmt.Errorf("%w: at least one field required", ErrValidation)
+	}
+	g, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Name != nil {
+		g.Name = strings.TrimSpace(*in.Name)
+		if g.Name == "" {
+			return nil, fmt.Errorf("%w: name is required", ErrValidation)
+		}
+	}
+	if in.MinSelect != nil {
+		g.MinSelect = *in.MinSelect
+	}
+	if in.MaxSelect != nil {
+		g.MaxSelect = *in.MaxSelect
+	}
+	if in.SortOrder != nil {
+		g.SortOrder = *in.SortOrder
+	}
+	modifiers := g.Modifiers
+	if in.Modifiers != nil {
+		if modifiers, err = buildModifiers(*in.Modifiers, g.Modifiers); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkGroupRules(g, modifiers); err != nil {
+		return nil, err
+	}
+
+	// Правила min/max проверены для нового набора модификаторов — сохраняем их вместе
+	err = s.repo.Transaction(ctx, func(tx *ModifierRepository) error {
+		if _, err := tx.Save(ctx, g); err != nil {
+			return err
+		}
+		if in.Modifiers != nil {
+			return tx.SyncModifiers(ctx, g, modifiers)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	g.Modifiers = modifiers
+	return g, nil
+}
+
+func (s *modifierService) Delete(ctx context.Context, id uint) error {
+	g, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, g)
+}
+
+// AttachToProduct заменяет собственные группы продукта и возвращает их.
+func (s *modifierService) AttachToProduct(ctx context.Context, sl string, groupIDs []uint) ([]ModifierGroup, error) {
+	p, err := s.findProduct(ctx, sl)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := s.checkGroups(ctx, groupIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceForProduct(ctx, p.ID, ids); err != nil {
+		return nil, err
+	}
+	return s.repo.ForProduct(ctx, p.ID, nil)
+}
+
+// AttachToCategory заменяет группы категории и возвращает их.
+func (s *modifierService) AttachToCategory(ctx context.Context, sl string, groupIDs []uint) ([]ModifierGroup, error) {
+	c, err := s.categories.FindBySlug(ctx, sl)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	ids, err := s.checkGroups(ctx, groupIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceForCategory(ctx, c.ID, ids); err != nil {
+		return nil, err
+	}
+	return s.repo.ListForCategory(ctx, c.ID)
+}
+
+// ForProduct возвращает группы, действующие для продукта: собственные и
+// привязанные к его категориям и их предкам.
+func (s *modifierService) ForProduct(ctx context.Context, sl string) ([]ModifierGroup, error) {
+	p, err := s.findProduct(ctx, sl)
+	if err != nil {
+		return nil, err
+	}
+	return s.groupsFor(ctx, p)
+}
+
+// Quote считает цену позиции по выбору покупателя.
+func (s *modifierService) Quote(ctx context.Context, sl string, in PriceRequest) (*PriceQuote, error) {
+	p, err := s.findProduct(ctx, sl)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := s.groupsFor(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	return CalculatePrice(p, groups, in)
+}
+
+func (s *modifierService) groupsFor(ctx context.Context, p *Product) ([]ModifierGroup, error) {
+	own := make([]uint, 0, len(p.Categories))
+	for _, c := range p.Categories {
+		own = append(own, c.ID)
+	}
+	categoryIDs, err := s.categories.WithAncestors(ctx, own)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ForProduct(ctx, p.ID, categoryIDs)
+}
+
+func (s *modifierService) findProduct(ctx context.Context, sl string) (*Product, error) {
+	p, err := s.products.FindBySlug(ctx, sl)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return p, err
+}
+
+// checkGroups убирает повторы из groupIDs и проверяет, что все группы существуют.
+func (s *modifierService) checkGroups(ctx context.Context, groupIDs []uint) ([]uint, error) {
+	seen := make(map[uint]bool, len(groupIDs))
+	unique := make([]uint, 0, len(groupIDs))
+	for _, id := range groupIDs {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	cnt, err := s.repo.CountByIDs(ctx, unique)
+	if err != nil {
+		return nil, err
+	}
+	if cnt != int64(len(unique)) {
+		return nil, fmt.Errorf("%w: unknown modifier group in group_ids", ErrValidation)
+	}
+	return unique, nil
+}
@@ -1,13 +1,30 @@
 package products
 
 type ProductCreateRequest struct {
-	Name        string   `json:"name" validate:"required,min=1" example:"Маргарита"`
-	Type        string   `json:"type" validate:"omitempty,max=64" example:"pizza"`
-	Tags        []string `json:"tags" validate:"omitempty,dive,required" example:"[\"italian\",\"popular\"]"`
-	Price       int      `json:"price" validate:"required,gt=0" example:"499"`
-	Ingredients []string `json:"ingredients" example:"[\"томатный соус\",\"моцарелла\",\"помидоры\",\"базилик\"]"`
-	Image       string   `json:"image" validate:"omitempty,url" example:"https://example.com/image.jpg"`
-	Rating      float64  `json:"rating" validate:"gte=0,lte=5" example:"4.5"`
+	Name string   `json:"name" validate:"required,min=1" example:"Маргарита"`
+	Type string   `json:"type" validate:"omitempty,max=64" example:"pizza"`
+	Tags []string `json:"tags" validate:"omitempty,dive,required" example:"[\"italian\",\"popular\"]"`
+	// Обязательна без вариантов; с вариантами берётся у варианта по умолчанию
+	Price       int                     `json:"price" validate:"omitempty,gt=0" example:"499"`
+	Ingredients []string                `json:"ingredients" example:"[\"томатный соус\",\"моцарелла\",\"помидоры\",\"базилик\"]"`
+	Image       string                  `json:"image" validate:"omitempty,url" example:"https://example.com/image.jpg"`
+	Rating      float64                 `json:"rating" validate:"gte=0,lte=5" example:"4.5"`
+	CategoryIDs []uint                  `json:"category_ids" example:"1,4"`
+	Variants    []ProductVariantRequest `json:"variants" validate:"omitempty,dive"`
+}
+
+// ProductVariantRequest — вариант в составе продукта. При обновлении вариант
+// с id изменяется, без id — создаётся.
+type ProductVariantRequest struct {
+	ID          *uint  `json:"id" example:"12"`
+	Name        string `json:"name" validate:"required,max=64" example:"30 см"`
+	SKU         string `json:"sku" validate:"omitempty,max=64" example:"PZ-MARG-30"`
+	Price       int    `json:"price" validate:"required,gt=0" example:"599"`
+	WeightGrams int    `json:"weight_g" validate:"gte=0" example:"650"`
+	VolumeML    int    `json:"volume_ml" validate:"gte=0" example:"0"`
+	// Если ни один вариант не отмечен, по умолчанию первый по sort_order
+	IsDefault bool `json:"is_default" example:"true"`
+	SortOrder int  `json:"sort_order" example:"20"`
 }
 
 type ProductUpdateRequest struct {
@@ -18,8 +35,100 @@ type ProductUpdateRequest struct {
 	Ingredients *[]string `json:"ingredients"`
 	Image       *string   `json:"image" validate:"omitempty,url"`
 	Rating      *float64  `json:"rating" validate:"omitempty,gte=0,lte=5"`
+	// Заменяет список категорий продукта; пустой список отвязывает от всех
+	CategoryIDs *[]uint `json:"category_ids"`
+	// Заменяет набор вариантов: отсутствующие в списке удаляются
+	Variants *[]ProductVariantRequest `json:"variants" validate:"omitempty,dive"`
 }
 
 type ProductSlugUpdateRequest struct {
 	Slug string `json:"slug" validate:"required,min=1" example:"margarita-2025"`
 }
+
+// ProductListResponse — страница каталога с общим числом подходящих продуктов.
+// Курсоры заполняются только в режиме ?cursor=.
+type ProductListResponse struct {
+	Products   []Product `json:"products"`
+	Total      int64     `json:"total"`
+	Limit      int       `json:"limit"`
+	Offset     int       `json:"offset"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+	PrevCursor string    `json:"prev_cursor,omitempty"`
+}
+
+type ProductSearchResponse struct {
+	Results []SearchResult `json:"results"`
+	Total   int64          `json:"total"`
+	Limit   int            `json:"limit"`
+	Offset  int            `json:"offset"`
+}
+
+type SuggestResponse struct {
+	Suggestions []Suggestion `json:"suggestions"`
+}
+
+type ModifierGroupCreateRequest struct {
+	Name      string            `json:"name" validate:"required,max=64" example:"Добавки"`
+	MinSelect int               `json:"min_select" validate:"gte=0" example:"0"`
+	MaxSelect int               `json:"max_select" validate:"gte=0" example:"3"`
+	SortOrder int               `json:"sort_order" example:"10"`
+	Modifiers []ModifierRequest `json:"modifiers" validate:"omitempty,dive"`
+}
+
+type ModifierGroupUpdateRequest struct {
+	Name      *string `json:"name" validate:"omitempty,min=1,max=64"`
+	MinSelect *int    `json:"min_select" validate:"omitempty,gte=0"`
+	MaxSelect *int    `json:"max_select" validate:"omitempty,gte=0"`
+	SortOrder *int    `json:"sort_order"`
+	// Заменяет набор модификаторов: отсутствующие в списке удаляются
+	Modifiers *[]ModifierRequest `json:"modifiers" validate:"omitempty,dive"`
+}
+
+// ModifierRequest — модификатор в составе группы. При обновлении модификатор
+// с id изменяется, без id — создаётся.
+type ModifierRequest struct {
+	ID         *uint  `json:"id" example:"7"`
+	Name       string `json:"name" validate:"required,max=64" example:"Двойной сыр"`
+	PriceDelta int    `json:"price_delta" example:"90"`
+	Removable  bool   `json:"removable" example:"false"`
+	SortOrder  int    `json:"sort_order" example:"10"`
+}
+
+// ModifierGroupsRequest — полный список групп, привязанных к продукту или категории.
+type ModifierGroupsRequest struct {
+	GroupIDs []uint `json:"group_ids" example:"1,2"`
+}
+
+type ModifierGroupsResponse struct {
+	Groups []ModifierGroup `json:"groups"`
+}
+
+// PriceRequest — выбор покупателя для расчёта цены позиции.
+type PriceRequest struct {
+	// Вариант продукта; по умолчанию — вариант по умолчанию
+	VariantID *uint `json:"variant_id" example:"12"`
+	// Выбранные модификаторы, в том числе убираемые ингредиенты
+	ModifierIDs []uint `json:"modifier_ids" example:"7,9"`
+	// По умолчанию 1
+	Quantity int `json:"quantity" validate:"gte=0,lte=99" example:"2"`
+}
+
+// PriceQuote — рассчитанная цена позиции.
+type PriceQuote struct {
+	Product   string `json:"product" example:"margarita"`
+	VariantID *uint  `json:"variant_id,omitempty" example:"12"`
+	// Цена продукта или варианта без модификаторов
+	BasePrice int             `json:"base_price" example:"599"`
+	Modifiers []QuoteModifier `json:"modifiers"`
+	UnitPrice int             `json:"unit_price" example:"689"`
+	Quantity  int             `json:"quantity" example:"2"`
+	Total     int             `json:"total" example:"1378"`
+}
+
+type QuoteModifier struct {
+	ID         uint   `json:"id" example:"7"`
+	GroupID    uint   `json:"group_id" example:"2"`
+	Name       string `json:"name" example:"Двойной сыр"`
+	PriceDelta int    `json:"price_delta" example:"90"`
+	Removed    bool   `json:"removed" example:"false"`
+}
@@ -0,0 +1,161 @@
+package products
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxQuantity — верхняя граница количества в одной позиции.
+const maxQuantity = 99
+
+// CalculatePrice проверяет выбор покупателя и считает цену позиции: цена
+// варианта (или продукта) плюс PriceDelta выбранных модификаторов, умноженная
+// на количество. groups — группы, действующие для продукта (свои и категорий).
+func CalculatePrice(p *Product, groups []ModifierGroup, in PriceRequest) (*PriceQuote, error) {
+	quote := &PriceQuote{
+		Product:   p.Slug,
+		BasePrice: p.Price,
+		Modifiers: []QuoteModifier{},
+		Quantity:  in.Quantity,
+	}
+	if quote.Quantity == 0 {
+		quote.Quantity = 1
+	}
+	if quote.Quantity < 0 || quote.Quantity > maxQuantity {
+		return nil, fmt.Errorf("%w: quantity must be between 1 and %d", ErrValidation, maxQuantity)
+	}
+
+	// Вариант
+	if in.VariantID != nil {
+		var found *ProductVariant
+		for i := range p.Variants {
+			if p.Variants[i].ID == *in.VariantID {
+				found = &p.Variants[i]
+			}
+		}
+		if found == nil {
+			return nil, fmt.Errorf("%w: unknown variant id %d", ErrValidation, *in.VariantID)
+		}
+		quote.VariantID = &found.ID
+		quote.BasePrice = found.Price
+	} else {
+		for i := range p.Variants {
+			if p.Variants[i].IsDefault {
+				quote.VariantID = &p.Variants[i].ID
+				quote.BasePrice = p.Variants[i].Price
+			}
+		}
+	}
+
+	// Модификаторы
+	type pick struct {
+		group    *ModifierGroup
+		modifier *Modifier
+	}
+	available := make(map[uint]pick)
+	for gi := range groups {
+		for mi := range groups[gi].Modifiers {
+			m := &groups[gi].Modifiers[mi]
+			available[m.ID] = pick{group: &groups[gi], modifier: m}
+		}
+	}
+	selected := make(map[uint]int, len(groups))
+	seen := make(map[uint]bool, len(in.ModifierIDs))
+	unit := quote.BasePrice
+	for _, id := range in.ModifierIDs {
+		it, ok := available[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: modifier %d is not available for this product", ErrValidation, id)
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("%w: duplicate modifier %d", ErrValidation, id)
+		}
+		seen[id] = true
+		if !it.modifier.Removable {
+			selected[it.group.ID]++
+		}
+		unit += it.modifier.PriceDelta
+		quote.Modifiers = append(quote.Modifiers, QuoteModifier{
+			ID:         it.modifier.ID,
+			GroupID:    it.group.ID,
+			Name:       it.modifier.Name,
+			PriceDelta: it.modifier.PriceDelta,
+			Removed:    it.modifier.Removable,
+		})
+	}
+
+	// Правила выбора групп
+	for i := range groups {
+		g := &groups[i]
+		n := selected[g.ID]
+		if n < g.MinSelect {
+			return nil, fmt.Errorf("%w: choose at least %d in %q", ErrValidation, g.MinSelect, g.Name)
+		}
+		if g.MaxSelect > 0 && n > g.MaxSelect {
+			return nil, fmt.Errorf("%w: choose at most %d in %q", ErrValidation, g.MaxSelect, g.Name)
+		}
+	}
+
+	// Скидки за убранные ингредиенты не опускают цену ниже нуля
+	if unit < 0 {
+		unit = 0
+	}
+	quote.UnitPrice = unit
+	quote.Total = unit * quote.Quantity
+	return quote, nil
+}
+
+// checkGroupRules проверяет правила выбора группы с модификаторами modifiers.
+func checkGroupRules(g *ModifierGroup, modifiers []Modifier) error {
+	if g.MinSelect < 0 || g.MaxSelect < 0 {
+		return fmt.Errorf("%w: min_select and max_select must be >= 0", ErrValidation)
+	}
+	if g.MaxSelect > 0 && g.MaxSelect < g.MinSelect {
+		return fmt.Errorf("%w: max_select must not be less than min_select", ErrValidation)
+	}
+	choosable := 0
+	for _, m := range modifiers {
+		if !m.Removable {
+			choosable++
+		}
+	}
+	if g.MinSelect > choosable {
+		return fmt.Errorf("%w: min_select exceeds the number of selectable modifiers", ErrValidation)
+	}
+	return nil
+}
+
+// buildModifiers проверяет модификаторы из запроса. existing — текущие
+// модификаторы группы: модификатор с id берётся из них, чужой id — ошибка.
+func buildModifiers(in []ModifierRequest, existing []Modifier) ([]Modifier, error) {
+	byID := make(map[uint]Modifier, len(existing))
+	for _, m := range existing {
+		byID[m.ID] = m
+	}
+
+	list := make([]Modifier, 0, len(in))
+	seenID := make(map[uint]bool, len(in))
+	for _, r := range in {
+		var m Modifier
+		if r.ID != nil {
+			cur, ok := byID[*r.ID]
+			if !ok {
+				return nil, fmt.Errorf("%w: unknown modifier id %d", ErrValidation, *r.ID)
+			}
+			if seenID[*r.ID] {
+				return nil, fmt.Errorf("%w: duplicate modifier id %d", ErrValidation, *r.ID)
+			}
+			seenID[*r.ID] = true
+			m = cur
+		}
+		m.Name = strings.TrimSpace(r.Name)
+		if m.Name == "" {
+			return nil, fmt.Errorf("%w: modifier name is required", ErrValidation)
+		}
+		m.PriceDelta = r.PriceDelta
+		m.Removable = r.Removable
+		m.SortOrder = r.SortOrder
+		list = append(list, m)
+	}
+	return list, nil
+}
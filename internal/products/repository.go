@@ -1,10 +1,16 @@
 package products
 
 import (
+	"bike/internal/categories"
 	"bike/pkg/db"
+	"bike/pkg/paginate"
 	"context"
 	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ProductRepository struct {
@@ -17,6 +23,14 @@ func NewProductRepository(database *db.Db) *ProductRepository {
 	}
 }
 
+// Transaction выполняет fn с репозиторием, все запросы которого идут в одной
+// транзакции. Ошибка из fn откатывает транзакцию.
+func (r *ProductRepository) Transaction(ctx context.Context, fn func(tx *ProductRepository) error) error {
+	return r.Database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(NewProductRepository(&db.Db{DB: tx}))
+	})
+}
+
 func (r *ProductRepository) Create(ctx context.Context, p *Product) (*Product, error) {
 	if err := r.Database.DB.WithContext(ctx).Create(p).Error; err != nil {
 		return nil, err
@@ -40,35 +54,117 @@ func (r *ProductRepository) ExistsSlug(ctx context.Context, slug string) (bool,
 
 func (r *ProductRepository) FindBySlug(ctx context.Context, slug string) (*Product, error) {
 	var p Product
-	res := r.Database.DB.WithContext(ctx).Where("slug = ?", slug).First(&p)
+	res := r.Database.DB.WithContext(ctx).Preload("Categories").Preload("Variants", orderVariants).Where("slug = ?", slug).First(&p)
 	if errors.Is(res.Error, gorm.ErrRecordNotFound) {
 		return nil, gorm.ErrRecordNotFound
 	}
 	return &p, res.Error
 }
 
-func (r *ProductRepository) List(ctx context.Context, limit, offset int) ([]Product, error) {
-	var list []Product
-	q := r.Database.DB.WithContext(ctx).Model(&Product{}).Order("id DESC")
+// filtered применяет фильтры каталога к запросу.
+func (r *ProductRepository) filtered(ctx context.Context, f ProductFilter) *gorm.DB {
+	q := r.Database.DB.WithContext(ctx).Model(&Product{})
+	if f.Type != "" {
+		q = q.Where("type = ?", f.Type)
+	}
+	if len(f.Tags) > 0 {
+		if f.AllTags {
+			q = q.Where("tags @> ?", pq.StringArray(f.Tags))
+		} else {
+			q = q.Where("tags && ?", pq.StringArray(f.Tags))
+		}
+	}
+	if f.MinPrice != nil {
+		q = q.Where("from_price >= ?", *f.MinPrice)
+	}
+	if f.MaxPrice != nil {
+		q = q.Where("from_price <= ?", *f.MaxPrice)
+	}
+	if f.MinRating != nil {
+		q = q.Where("rating >= ?", *f.MinRating)
+	}
+	if len(f.Ingredients) > 0 {
+		q = q.Where("ingredients @> ?", pq.StringArray(f.Ingredients))
+	}
+	if len(f.ExcludeIngredients) > 0 {
+		// У продукта без ингредиентов массив NULL — такие не исключаем
+		q = q.Where("NOT (COALESCE(ingredients, '{}') && ?)", pq.StringArray(f.ExcludeIngredients))
+	}
+	if len(f.CategoryIDs) > 0 {
+		q = q.Where("id IN (SELECT product_id FROM "+categories.ProductsJoinTable+" WHERE category_id IN ?)", f.CategoryIDs)
+	}
+	return q
+}
+
+// Count возвращает число продуктов, подходящих под фильтры.
+func (r *ProductRepository) Count(ctx context.Context, f ProductFilter) (int64, error) {
+	var total int64
+	err := r.filtered(ctx, f).Count(&total).Error
+	return total, err
+}
+
+// List возвращает страницу каталога с учётом фильтров и общее число подходящих продуктов.
+func (r *ProductRepository) List(ctx context.Context, f ProductFilter, limit, offset int) ([]Product, int64, error) {
+	total, err := r.Count(ctx, f)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	key := sortKeyFor(f.Sort)
+	q := r.filtered(ctx, f).Preload("Categories").Preload("Variants", orderVariants).Order(paginate.Order(key.column, key.desc, false))
 	if limit > 0 {
 		q = q.Limit(limit)
 	}
 	if offset > 0 {
 		q = q.Offset(offset)
 	}
+	var list []Product
 	if err := q.Find(&list).Error; err != nil {
-		return nil, err
+		return nil, 0, err
 	}
-	return list, nil
+	return list, total, nil
 }
 
+// ListAfter возвращает до limit продуктов после курсора (nil — с начала).
+// more — есть ли ещё продукты в направлении движения.
+func (r *ProductRepository) ListAfter(ctx context.Context, f ProductFilter, cur *paginate.Cursor, limit int) ([]Product, bool, error) {
+	key := sortKeyFor(f.Sort)
+	backward := cur != nil && cur.Backward
+	q := r.filtered(ctx, f).Preload("Categories").Preload("Variants", orderVariants)
+	if cur != nil {
+		if key.column == "id" {
+			q = q.Where(paginate.Seek(key.column, key.desc, backward), cur.ID)
+		} else {
+			value, err := key.parse(cur.Key)
+			if err != nil {
+				return nil, false, paginate.ErrInvalidCursor
+			}
+			q = q.Where(paginate.Seek(key.column, key.desc, backward), value, cur.ID)
+		}
+	}
+	var list []Product
+	err := q.Order(paginate.Order(key.column, key.desc, backward)).Limit(limit + 1).Find(&list).Error
+	if err != nil {
+		return nil, false, err
+	}
+	list, more := paginate.Trim(list, limit, backward)
+	return list, more, nil
+}
+
+// Save сохраняет поля продукта; категории и варианты меняются через
+// ReplaceCategories и SyncVariants.
 func (r *ProductRepository) Save(ctx context.Context, p *Product) (*Product, error) {
-	if err := r.Database.DB.WithContext(ctx).Save(p).Error; err != nil {
+	if err := r.Database.DB.WithContext(ctx).Omit(clause.Associations).Save(p).Error; err != nil {
 		return nil, err
 	}
 	return p, nil
 }
 
+// ReplaceCategories заменяет категории продукта на list.
+func (r *ProductRepository) ReplaceCategories(ctx context.Context, p *Product, list []categories.Category) error {
+	return r.Database.DB.WithContext(ctx).Model(p).Association("Categories").Replace(list)
+}
+
 func (r *ProductRepository) DeleteBySlug(ctx context.Context, slug string) error {
 	res := r.Database.DB.WithContext(ctx).Where("slug = ?", slug).Delete(&Product{})
 	if res.Error != nil {
@@ -79,3 +175,138 @@ func (r *ProductRepository) DeleteBySlug(ctx context.Context, slug string) error
 	}
 	return nil
 }
+
+// SyncVariants приводит варианты продукта к list: варианты с ID обновляются,
+// без ID создаются, остальные удаляются.
+func (r *ProductRepository) SyncVariants(ctx context.Context, p *Product, list []ProductVariant) error {
+	keep := make([]uint, 0, len(list))
+	for _, v := range list {
+		if v.ID != 0 {
+			keep = append(keep, v.ID)
+		}
+	}
+	return r.Database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		del := tx.Where("product_id = ?", p.ID)
+		if len(keep) > 0 {
+			del = del.Where("id NOT IN ?", keep)
+		}
+		if err := del.Delete(&ProductVariant{}).Error; err != nil {
+			return err
+		}
+		// Вариант по умолчанию сохраняем последним, чтобы не нарушить
+		// уникальный индекс, пока прежний ещё отмечен
+		for _, isDefault := range []bool{false, true} {
+			for i := range list {
+				if list[i].IsDefault != isDefault {
+					continue
+				}
+				list[i].ProductID = p.ID
+				if err := tx.Save(&list[i]).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// TakenSKU возвращает первый из skus, занятый вариантом другого продукта.
+func (r *ProductRepository) TakenSKU(ctx context.Context, skus []string, productID uint) (string, error) {
+	var taken []string
+	if len(skus) == 0 {
+		return "", nil
+	}
+	err := r.Database.DB.WithContext(ctx).Model(&ProductVariant{}).
+		Where("sku IN ? AND product_id <> ?", skus, productID).
+		Limit(1).Pluck("sku", &taken).Error
+	if err != nil || len(taken) == 0 {
+		return "", err
+	}
+	return taken[0], nil
+}
+
+func orderVariants(db *gorm.DB) *gorm.DB {
+	return db.Order("sort_order, id")
+}
+
+// RefreshSearchVector пересчитывает search_vector продукта после изменения.
+func (r *ProductRepository) RefreshSearchVector(ctx context.Context, id uint) error {
+	return r.Database.DB.WithContext(ctx).
+		Exec("UPDATE products SET search_vector = "+searchVectorSQL+" WHERE id = ?", id).Error
+}
+
+// Search ищет продукты по tsquery, новые при равной релевантности — первыми.
+func (r *ProductRepository) Search(ctx context.Context, tsquery string, limit, offset int) ([]SearchResult, int64, error) {
+	const match = `FROM products, (SELECT to_tsquery('russian', @q) || to_tsquery('simple', @q) AS query) AS q
+		WHERE products.deleted_at IS NULL AND products.search_vector @@ q.query`
+	args := map[string]interface{}{
+		"q":      tsquery,
+		"opts":   headlineOptions,
+		"limit":  limit,
+		"offset": offset,
+	}
+
+	var total int64
+	if err := r.Database.DB.WithContext(ctx).Raw("SELECT count(*) "+match, args).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var list []SearchResult
+	err := r.Database.DB.WithContext(ctx).Raw(`SELECT products.*,
+			ts_rank_cd(products.search_vector, q.query) AS rank,
+			ts_headline('russian', products.name, q.query, @opts) AS name_highlight,
+			ts_headline('russian', concat_ws(', ', products.type, array_to_string(products.tags, ', '), array_to_string(products.ingredients, ', ')), q.query, @opts) AS snippet
+		`+match+`
+		ORDER BY rank DESC, products.id DESC
+		LIMIT @limit OFFSET @offset`, args).Scan(&list).Error
+	if err != nil || len(list) == 0 {
+		return list, total, err
+	}
+
+	// Raw-запрос связи не грузит: подтягиваем их отдельно для найденных продуктов
+	ids := make([]uint, len(list))
+	for i := range list {
+		ids[i] = list[i].ID
+	}
+	var products []Product
+	err = r.Database.DB.WithContext(ctx).Preload("Categories").Preload("Variants", orderVariants).
+		Find(&products, ids).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	byID := make(map[uint]Product, len(products))
+	for _, p := range products {
+		byID[p.ID] = p
+	}
+	for i := range list {
+		p := byID[list[i].ID]
+		list[i].Categories = p.Categories
+		list[i].Variants = p.Variants
+	}
+	return list, total, nil
+}
+
+// Suggest ищет продукты, похожие на q по имени или на translit по транслитерации
+// имени. Фильтр через <% использует триграммные индексы.
+func (r *ProductRepository) Suggest(ctx context.Context, q, translit string, limit int) ([]Suggestion, error) {
+	var list []Suggestion
+	err := r.Database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(fmt.Sprintf("SET LOCAL pg_trgm.word_similarity_threshold = %g", suggestThreshold)).Error; err != nil {
+			return err
+		}
+		return tx.Raw(`SELECT name, slug,
+				GREATEST(word_similarity(@q, name), word_similarity(@t, name_translit)) AS score
+			FROM products
+			WHERE deleted_at IS NULL AND (@q <% name OR @t <% name_translit)
+			ORDER BY score DESC, name
+			LIMIT @limit`, map[string]interface{}{
+			"q":     q,
+			"t":     translit,
+			"limit": limit,
+		}).Scan(&list).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
@@ -0,0 +1,74 @@
+package products
+
+import (
+	"bike/pkg/slug"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// searchVectorSQL строит tsvector продукта. Каждое поле индексируется дважды:
+// конфигурацией russian (стемминг: «пиццу» найдёт «пицца») и simple (точные
+// слова, латиница, бренды). Веса: имя — A, тип и теги — B, ингредиенты — C.
+const searchVectorSQL = `
+	setweight(to_tsvector('russian', coalesce(name, '')), 'A') ||
+	setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+	setweight(to_tsvector('russian', coalesce(type, '') || ' ' || coalesce(array_to_string(tags, ' '), '')), 'B') ||
+	setweight(to_tsvector('simple', coalesce(type, '') || ' ' || coalesce(array_to_string(tags, ' '), '')), 'B') ||
+	setweight(to_tsvector('russian', coalesce(array_to_string(ingredients, ' '), '')), 'C') ||
+	setweight(to_tsvector('simple', coalesce(array_to_string(ingredients, ' '), '')), 'C')`
+
+// Параметры подсветки совпадений для ts_headline.
+const headlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxWords=20, MinWords=5, MaxFragments=2, FragmentDelimiter=\" … \""
+
+// Порог word_similarity для подсказок: ниже — слишком далёкие совпадения.
+// Значение по умолчанию в pg_trgm (0.6) не прощает опечаток в коротких словах.
+const suggestThreshold = 0.3
+
+// Не больше стольких слов из запроса — длинный запрос всё равно ничего не найдёт.
+const maxSearchTerms = 8
+
+// BackfillSearchVectors заполняет search_vector у продуктов, созданных до
+// появления поиска. Вызывается из миграций.
+func BackfillSearchVectors(db *gorm.DB) error {
+	return db.Exec("UPDATE products SET search_vector = " + searchVectorSQL + " WHERE search_vector IS NULL").Error
+}
+
+// BackfillNameTranslit заполняет name_translit у продуктов, созданных до
+// появления подсказок. Вызывается из миграций.
+func BackfillNameTranslit(db *gorm.DB) error {
+	var list []Product
+	if err := db.Select("id", "name").Where("name_translit IS NULL OR name_translit = ''").Find(&list).Error; err != nil {
+		return err
+	}
+	for _, p := range list {
+		if err := db.Model(&Product{}).Where("id = ?", p.ID).Update("name_translit", slug.Slugify(p.Name)).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasLetters сообщает, есть ли в строке буквы или цифры.
+func hasLetters(s string) bool {
+	return strings.IndexFunc(s, func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}) >= 0
+}
+
+// buildTSQuery превращает пользовательский ввод в tsquery с префиксным поиском
+// по каждому слову: «марг пиц» → «марг:* & пиц:*». Знаки препинания и
+// операторы tsquery отбрасываются. Пустая строка — в запросе нет ни одного слова.
+func buildTSQuery(q string) string {
+	words := strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) > maxSearchTerms {
+		words = words[:maxSearchTerms]
+	}
+	for i, w := range words {
+		words[i] = w + ":*"
+	}
+	return strings.Join(words, " & ")
+}
@@ -1,10 +1,14 @@
 package products
 
 import (
+	"bike/internal/categories"
+	"bike/pkg/paginate"
 	"bike/pkg/slug"
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"github.com/lib/pq"
@@ -19,22 +23,41 @@ var (
 type ProductService interface {
 	Create(ctx context.Context, in ProductCreateRequest) (*Product, error)
 	GoTo(ctx context.Context, slug string) (*Product, error)
-	GetAll(ctx context.Context, limit, offset int) ([]Product, error)
+	GetAll(ctx context.Context, filter ProductFilter, limit, offset int) ([]Product, int64, error)
+	GetPage(ctx context.Context, filter ProductFilter, cursor *paginate.Cursor, limit int) ([]Product, int64, bool, error)
 	Update(ctx context.Context, slug string, in ProductUpdateRequest) (*Product, error)
 	ChangeSlug(ctx context.Context, currentSlug, newSlug string) (*Product, error)
 	Delete(ctx context.Context, slug string) error
+	Search(ctx context.Context, q string, limit, offset int) ([]SearchResult, int64, error)
+	Suggest(ctx context.Context, q string, limit int) ([]Suggestion, error)
 }
 
-type productService struct{ repo *ProductRepository }
+type productService struct {
+	repo       *ProductRepository
+	categories *categories.CategoryRepository
+}
 
-func NewProductService(repo *ProductRepository) ProductService { return &productService{repo: repo} }
+func NewProductService(repo *ProductRepository, categories *categories.CategoryRepository) ProductService {
+	return &productService{repo: repo, categories: categories}
+}
 
 func (s *productService) Create(ctx context.Context, in ProductCreateRequest) (*Product, error) {
 	if in.Name == "" {
 		return nil, fmt.Errorf("%w: name is required", ErrValidation)
 	}
-	if in.Price <= 0 {
+	variants, err := buildVariants(in.Variants, nil)
+	if err != nil {
+		return nil, err
+	}
+	// С вариантами цена продукта — цена варианта по умолчанию
+	switch {
+	case len(variants) == 0 && in.Price <= 0:
 		return nil, fmt.Errorf("%w: price must be > 0", ErrValidation)
+	case len(variants) > 0 && in.Price != 0:
+		return nil, fmt.Errorf("%w: price is taken from the default variant", ErrValidation)
+	}
+	if err := s.checkSKUs(ctx, variants, 0); err != nil {
+		return nil, err
 	}
 
 	// Имя должно быть уникальным
@@ -44,6 +67,11 @@ func (s *productService) Create(ctx context.Context, in ProductCreateRequest) (*
 		return nil, fmt.Errorf("%w: name must be unique", ErrValidation)
 	}
 
+	cats, err := s.findCategories(ctx, in.CategoryIDs)
+	if err != nil {
+		return nil, err
+	}
+
 	// Базовый slug
 	base := slug.Slugify(in.Name)
 	if base == "" {
@@ -64,16 +92,30 @@ func (s *productService) Create(ctx context.Context, in ProductCreateRequest) (*
 	}
 
 	p := &Product{
-		Slug:        use,
-		Name:        in.Name,
-		Type:        in.Type,
-		Tags:        pq.StringArray(in.Tags),
-		Price:       in.Price,
-		Ingredients: pq.StringArray(in.Ingredients),
-		Image:       in.Image,
-		Rating:      in.Rating,
-	}
-	return s.repo.Create(ctx, p)
+		Slug:         use,
+		Name:         in.Name,
+		NameTranslit: base,
+		Type:         in.Type,
+		Tags:         pq.StringArray(in.Tags),
+		Price:        in.Price,
+		Ingredients:  pq.StringArray(in.Ingredients),
+		Image:        in.Image,
+		Rating:       in.Rating,
+		Categories:   cats,
+		Variants:     variants,
+	}
+	applyPrices(p)
+	// Продукт с вариантами и категориями появляется только вместе с поисковым вектором
+	err = s.repo.Transaction(ctx, func(tx *ProductRepository) error {
+		if _, err := tx.Create(ctx, p); err != nil {
+			return err
+		}
+		return tx.RefreshSearchVector(ctx, p.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
 }
 
 func (s *productService) GoTo(ctx context.Context, sl string) (*Product, error) {
@@ -84,13 +126,31 @@ func (s *productService) GoTo(ctx context.Context, sl string) (*Product, error)
 	return p, err
 }
 
-func (s *productService) GetAll(ctx context.Context, limit, offset int) ([]Product, error) {
-	return s.repo.List(ctx, limit, offset)
+func (s *productService) GetAll(ctx context.Context, filter ProductFilter, limit, offset int) ([]Product, int64, error) {
+	return s.repo.List(ctx, filter, limit, offset)
+}
+
+// GetPage — страница каталога по курсору (keyset). Курсор должен быть выдан
+// для той же сортировки.
+func (s *productService) GetPage(ctx context.Context, filter ProductFilter, cursor *paginate.Cursor, limit int) ([]Product, int64, bool, error) {
+	if cursor != nil && cursor.Sort != filter.Sort {
+		return nil, 0, false, paginate.ErrInvalidCursor
+	}
+	total, err := s.repo.Count(ctx, filter)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	list, more, err := s.repo.ListAfter(ctx, filter, cursor, limit)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	return list, total, more, nil
 }
 
 func (s *productService) Update(ctx context.Context, sl string, in ProductUpdateRequest) (*Product, error) {
 	if in.Name == nil && in.Type == nil && in.Tags == nil &&
-		in.Price == nil && in.Ingredients == nil && in.Image == nil && in.Rating == nil {
+		in.Price == nil && in.Ingredients == nil && in.Image == nil && in.Rating == nil &&
+		in.CategoryIDs == nil && in.Variants == nil {
 		return nil, fmt.Errorf("%w: at least one field required", ErrValidation)
 	}
 
@@ -110,6 +170,7 @@ func (s *productService) Update(ctx context.Context, sl string, in ProductUpdate
 			return nil, fmt.Errorf("%w: name must be unique", ErrValidation)
 		}
 		p.Name = *in.Name
+		p.NameTranslit = slug.Slugify(p.Name)
 	}
 	if in.Type != nil {
 		p.Type = *in.Type
@@ -117,9 +178,23 @@ func (s *productService) Update(ctx context.Context, sl string, in ProductUpdate
 	if in.Tags != nil {
 		p.Tags = pq.StringArray(*in.Tags)
 	}
+	if in.Variants != nil {
+		variants, err := buildVariants(*in.Variants, p.Variants)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.checkSKUs(ctx, variants, p.ID); err != nil {
+			return nil, err
+		}
+		p.Variants = variants
+	}
 	if in.Price != nil {
+		if len(p.Variants) > 0 {
+			return nil, fmt.Errorf("%w: price is taken from the default variant", ErrValidation)
+		}
 		p.Price = *in.Price
 	}
+	applyPrices(p)
 	if in.Ingredients != nil {
 		p.Ingredients = pq.StringArray(*in.Ingredients)
 	}
@@ -130,7 +205,69 @@ func (s *productService) Update(ctx context.Context, sl string, in ProductUpdate
 		p.Rating = *in.Rating
 	}
 
-	return s.repo.Save(ctx, p)
+	var cats []categories.Category
+	if in.CategoryIDs != nil {
+		if cats, err = s.findCategories(ctx, *in.CategoryIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	// Поля, варианты, категории и поисковый вектор меняются вместе или никак
+	err = s.repo.Transaction(ctx, func(tx *ProductRepository) error {
+		if _, err := tx.Save(ctx, p); err != nil {
+			return err
+		}
+		if in.Variants != nil {
+			if err := tx.SyncVariants(ctx, p, p.Variants); err != nil {
+				return err
+			}
+		}
+		if in.CategoryIDs != nil {
+			if err := tx.ReplaceCategories(ctx, p, cats); err != nil {
+				return err
+			}
+		}
+		return tx.RefreshSearchVector(ctx, p.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if in.CategoryIDs != nil {
+		p.Categories = cats
+	}
+	return p, nil
+}
+
+// checkSKUs проверяет, что артикулы вариантов не заняты другими продуктами.
+func (s *productService) checkSKUs(ctx context.Context, variants []ProductVariant, productID uint) error {
+	taken, err := s.repo.TakenSKU(ctx, variantSKUs(variants), productID)
+	if err != nil {
+		return err
+	}
+	if taken != "" {
+		return fmt.Errorf("%w: sku %s is already used", ErrValidation, taken)
+	}
+	return nil
+}
+
+// findCategories загружает категории по ID; неизвестный ID — ошибка валидации.
+func (s *productService) findCategories(ctx context.Context, ids []uint) ([]categories.Category, error) {
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	list, err := s.categories.FindByIDs(ctx, unique)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) != len(unique) {
+		return nil, fmt.Errorf("%w: unknown category in category_ids", ErrValidation)
+	}
+	return list, nil
 }
 
 // Явная смена slug пользователем
@@ -166,3 +303,26 @@ func (s *productService) Delete(ctx context.Context, sl string) error {
 	}
 	return err
 }
+
+// Search — полнотекстовый поиск с префиксным совпадением слов.
+func (s *productService) Search(ctx context.Context, q string, limit, offset int) ([]SearchResult, int64, error) {
+	tsquery := buildTSQuery(q)
+	if tsquery == "" {
+		return nil, 0, fmt.Errorf("%w: q must contain at least one word", ErrValidation)
+	}
+	return s.repo.Search(ctx, tsquery, limit, offset)
+}
+
+// Suggest — подсказки по мере ввода, устойчивые к опечаткам. Запрос
+// сравнивается с именем как есть и в транслитерации — с транслитерацией имени.
+func (s *productService) Suggest(ctx context.Context, q string, limit int) ([]Suggestion, error) {
+	q = strings.TrimSpace(q)
+	if q == "" {
+		return nil, fmt.Errorf("%w: q is required", ErrValidation)
+	}
+	// По одной букве триграммы ничего осмысленного не дают
+	if !hasLetters(q) || utf8.RuneCountInString(q) < 2 {
+		return []Suggestion{}, nil
+	}
+	return s.repo.Suggest(ctx, q, slug.Slugify(q), limit)
+}
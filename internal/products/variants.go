@@ -0,0 +1,105 @@
+package products
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// buildVariants проверяет варианты из запроса и возвращает их в порядке
+// sort_order. existing — текущие варианты продукта: вариант с id берётся из
+// них и обновляется, чужой id — ошибка. Если ни один вариант не отмечен
+// по умолчанию, им становится первый.
+func buildVariants(in []ProductVariantRequest, existing []ProductVariant) ([]ProductVariant, error) {
+	byID := make(map[uint]ProductVariant, len(existing))
+	for _, v := range existing {
+		byID[v.ID] = v
+	}
+
+	list := make([]ProductVariant, 0, len(in))
+	seenID := make(map[uint]bool, len(in))
+	seenSKU := make(map[string]bool, len(in))
+	defaults := 0
+	for _, r := range in {
+		var v ProductVariant
+		if r.ID != nil {
+			cur, ok := byID[*r.ID]
+			if !ok {
+				return nil, fmt.Errorf("%w: unknown variant id %d", ErrValidation, *r.ID)
+			}
+			if seenID[*r.ID] {
+				return nil, fmt.Errorf("%w: duplicate variant id %d", ErrValidation, *r.ID)
+			}
+			seenID[*r.ID] = true
+			v = cur
+		}
+
+		v.Name = strings.TrimSpace(r.Name)
+		if v.Name == "" {
+			return nil, fmt.Errorf("%w: variant name is required", ErrValidation)
+		}
+		if r.Price <= 0 {
+			return nil, fmt.Errorf("%w: variant price must be > 0", ErrValidation)
+		}
+		v.SKU = strings.TrimSpace(r.SKU)
+		if v.SKU != "" {
+			if seenSKU[v.SKU] {
+				return nil, fmt.Errorf("%w: duplicate sku %s", ErrValidation, v.SKU)
+			}
+			seenSKU[v.SKU] = true
+		}
+		v.Price = r.Price
+		v.WeightGrams = r.WeightGrams
+		v.VolumeML = r.VolumeML
+		v.IsDefault = r.IsDefault
+		v.SortOrder = r.SortOrder
+		if v.IsDefault {
+			defaults++
+		}
+		list = append(list, v)
+	}
+	if defaults > 1 {
+		return nil, fmt.Errorf("%w: only one variant can be default", ErrValidation)
+	}
+
+	sort.SliceStable(list, func(i, j int) bool { return list[i].SortOrder < list[j].SortOrder })
+	if defaults == 0 && len(list) > 0 {
+		list[0].IsDefault = true
+	}
+	return list, nil
+}
+
+// variantSKUs возвращает непустые артикулы вариантов.
+func variantSKUs(list []ProductVariant) []string {
+	skus := make([]string, 0, len(list))
+	for _, v := range list {
+		if v.SKU != "" {
+			skus = append(skus, v.SKU)
+		}
+	}
+	return skus
+}
+
+// applyPrices пересчитывает Price и FromPrice по вариантам продукта.
+func applyPrices(p *Product) {
+	if len(p.Variants) == 0 {
+		p.FromPrice = p.Price
+		return
+	}
+	p.FromPrice = p.Variants[0].Price
+	for _, v := range p.Variants {
+		if v.IsDefault {
+			p.Price = v.Price
+		}
+		if v.Price < p.FromPrice {
+			p.FromPrice = v.Price
+		}
+	}
+}
+
+// BackfillFromPrice заполняет from_price у продуктов, созданных до появления вариантов.
+func BackfillFromPrice(db *gorm.DB) error {
+	return db.Exec("UPDATE products SET from_price = price WHERE from_price = 0").Error
+}
@@ -0,0 +1,188 @@
+package users
+
+import (
+	"bike/pkg/middleware"
+	"bike/pkg/rbac"
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUserNotFound     = errors.New("user not found")
+	ErrEmailTaken       = errors.New("email already in use")
+	ErrInvalidRole      = errors.New("invalid role")
+	ErrSelfAction       = errors.New("cannot apply this action to your own account")
+	ErrAlreadySuspended = errors.New("user already suspended")
+	ErrNotSuspended     = errors.New("user is not suspended")
+	ErrNotDeleted       = errors.New("user is not deleted")
+	ErrErased           = errors.New("user data was erased and cannot be restored")
+)
+
+// AdminUpdate меняет имя, email и роль пользователя. При смене email или роли
+// все токены пользователя отзываются: в них записаны старые значения.
+// Новый email считается неподтверждённым.
+func (s *UserService) AdminUpdate(ctx context.Context, actor *middleware.Principal, id uint, in AdminUpdateUserRequest) (*User, error) {
+	user, err := s.findUser(id)
+	if err != nil {
+		return nil, err
+	}
+	before := *user
+
+	if in.Name != nil {
+		name := strings.TrimSpace(*in.Name)
+		if name == "" {
+			return nil, ErrInvalidName
+		}
+		user.Name = name
+	}
+	if in.Email != nil && *in.Email != user.Email {
+		if existing, _ := s.repo.FindByEmail(*in.Email); existing != nil {
+			return nil, ErrEmailTaken
+		}
+		user.Email = *in.Email
+		user.VerifiedAt = nil
+	}
+	if in.Role != nil && rbac.Role(*in.Role) != user.Role {
+		role := rbac.Role(*in.Role)
+		if !role.Valid() {
+			return nil, ErrInvalidRole
+		}
+		// Иначе последний администратор может случайно лишить себя прав
+		if user.ID == actor.UserID {
+			return nil, ErrSelfAction
+		}
+		user.Role = role
+	}
+
+	updated, err := s.repo.Update(user)
+	if err != nil {
+		return nil, err
+	}
+	if before.Email != updated.Email || before.Role != updated.Role {
+		if err := s.sessions.RevokeUserSessions(ctx, updated); err != nil {
+			return nil, err
+		}
+	}
+	s.record(actor, updated.ID, ActionUpdate, "")
+	return updated, nil
+}
+
+// Suspend блокирует аккаунт и завершает все его сессии.
+func (s *UserService) Suspend(ctx context.Context, actor *middleware.Principal, id uint, reason string) (*User, error) {
+	if id == actor.UserID {
+		return nil, ErrSelfAction
+	}
+	user, err := s.findUser(id)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsSuspended() {
+		return nil, ErrAlreadySuspended
+	}
+	now := time.Now()
+	user.SuspendedAt = &now
+	user.SuspendReason = strings.TrimSpace(reason)
+	updated, err := s.repo.Update(user)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sessions.RevokeUserSessions(ctx, updated); err != nil {
+		return nil, err
+	}
+	s.record(actor, updated.ID, ActionSuspend, reason)
+	return updated, nil
+}
+
+// Unsuspend снимает блокировку.
+func (s *UserService) Unsuspend(ctx context.Context, actor *middleware.Principal, id uint, reason string) (*User, error) {
+	user, err := s.findUser(id)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsSuspended() {
+		return nil, ErrNotSuspended
+	}
+	user.SuspendedAt = nil
+	user.SuspendReason = ""
+	updated, err := s.repo.Update(user)
+	if err != nil {
+		return nil, err
+	}
+	s.record(actor, updated.ID, ActionUnsuspend, reason)
+	return updated, nil
+}
+
+// Delete помечает пользователя удалённым (soft delete) и завершает его сессии.
+// Данные сохраняются, аккаунт можно восстановить через Restore.
+func (s *UserService) Delete(ctx context.Context, actor *middleware.Principal, id uint, reason string) error {
+	if id == actor.UserID {
+		return ErrSelfAction
+	}
+	user, err := s.findUser(id)
+	if err != nil {
+		return err
+	}
+	if err := s.sessions.RevokeUserSessions(ctx, user); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(user.ID); err != nil {
+		return err
+	}
+	s.record(actor, user.ID, ActionDelete, reason)
+	return nil
+}
+
+// Restore восстанавливает удалённого пользователя. Если email за это время
+// занял другой аккаунт, восстановление невозможно.
+func (s *UserService) Restore(ctx context.Context, actor *middleware.Principal, id uint, reason string) (*User, error) {
+	user, err := s.repo.FindByIDWithDeleted(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if !user.DeletedAt.Valid {
+		return nil, ErrNotDeleted
+	}
+	if user.IsErased() {
+		return nil, ErrErased
+	}
+	if existing, _ := s.repo.FindByEmail(user.Email); existing != nil {
+		return nil, ErrEmailTaken
+	}
+	if err := s.repo.Restore(user.ID); err != nil {
+		return nil, err
+	}
+	s.record(actor, user.ID, ActionRestore, reason)
+	return s.repo.FindByID(user.ID)
+}
+
+func (s *UserService) findUser(id uint) (*User, error) {
+	user, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// record пишет действие в журнал. Ошибка записи не отменяет уже выполненное действие.
+func (s *UserService) record(actor *middleware.Principal, userID uint, action, reason string) {
+	err := s.repo.CreateStatusChange(&StatusChange{
+		UserID:  userID,
+		ActorID: actor.UserID,
+		Action:  action,
+		Reason:  strings.TrimSpace(reason),
+	})
+	if err != nil {
+		log.Printf("failed to record %s of user %d: %v", action, userID, err)
+	}
+}
@@ -0,0 +1,220 @@
+package users
+
+import (
+	"bike/pkg/middleware"
+	"bike/pkg/req"
+	"bike/pkg/res"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// AdminUpdate godoc
+// @Summary Изменить пользователя (админ)
+// @Description Меняет имя, email и роль. При смене email или роли все токены пользователя отзываются; новый email нужно подтвердить
+// @Tags users,admin
+// @Accept json
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Param request body users.AdminUpdateUserRequest true "Изменяемые поля"
+// @Success 200 {object} users.UserResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/{id} [patch]
+func (handler *UserHandler) AdminUpdate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r)
+		if !ok {
+			return
+		}
+		body, err := req.HandleBody[AdminUpdateUserRequest](&w, r)
+		if err != nil {
+			return
+		}
+		actor, ok := middleware.FromContext(r.Context())
+		if !ok {
+			res.Json(w, map[string]string{"error": "unauthorized"}, http.StatusUnauthorized)
+			return
+		}
+		user, err := handler.service.AdminUpdate(r.Context(), actor, id, *body)
+		if err != nil {
+			writeAdminError(w, err)
+			return
+		}
+		res.Json(w, ToResponse(user), http.StatusOK)
+	}
+}
+
+// Suspend godoc
+// @Summary Заблокировать пользователя (админ)
+// @Description Заблокированный пользователь не может войти, его токены отзываются
+// @Tags users,admin
+// @Accept json
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Param request body users.StatusChangeRequest true "Причина"
+// @Success 200 {object} users.UserResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/{id}/suspend [post]
+func (handler *UserHandler) Suspend() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r)
+		if !ok {
+			return
+		}
+		body, err := req.HandleBody[StatusChangeRequest](&w, r)
+		if err != nil {
+			return
+		}
+		actor, ok := middleware.FromContext(r.Context())
+		if !ok {
+			res.Json(w, map[string]string{"error": "unauthorized"}, http.StatusUnauthorized)
+			return
+		}
+		user, err := handler.service.Suspend(r.Context(), actor, id, body.Reason)
+		if err != nil {
+			writeAdminError(w, err)
+			return
+		}
+		res.Json(w, ToResponse(user), http.StatusOK)
+	}
+}
+
+// Unsuspend godoc
+// @Summary Разблокировать пользователя (админ)
+// @Tags users,admin
+// @Accept json
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Param request body users.StatusChangeRequest true "Причина"
+// @Success 200 {object} users.UserResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/{id}/unsuspend [post]
+func (handler *UserHandler) Unsuspend() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r)
+		if !ok {
+			return
+		}
+		body, err := req.HandleBody[StatusChangeRequest](&w, r)
+		if err != nil {
+			return
+		}
+		actor, ok := middleware.FromContext(r.Context())
+		if !ok {
+			res.Json(w, map[string]string{"error": "unauthorized"}, http.StatusUnauthorized)
+			return
+		}
+		user, err := handler.service.Unsuspend(r.Context(), actor, id, body.Reason)
+		if err != nil {
+			writeAdminError(w, err)
+			return
+		}
+		res.Json(w, ToResponse(user), http.StatusOK)
+	}
+}
+
+// Delete godoc
+// @Summary Удалить пользователя (админ)
+// @Description Мягкое удаление: данные сохраняются, аккаунт можно восстановить. Токены пользователя отзываются
+// @Tags users,admin
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Param reason query string false "Причина"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/{id} [delete]
+func (handler *UserHandler) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r)
+		if !ok {
+			return
+		}
+		actor, ok := middleware.FromContext(r.Context())
+		if !ok {
+			res.Json(w, map[string]string{"error": "unauthorized"}, http.StatusUnauthorized)
+			return
+		}
+		if err := handler.service.Delete(r.Context(), actor, id, r.URL.Query().Get("reason")); err != nil {
+			writeAdminError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// Restore godoc
+// @Summary Восстановить удалённого пользователя (админ)
+// @Tags users,admin
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Param reason query string false "Причина"
+// @Success 200 {object} users.UserResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/{id}/restore [post]
+func (handler *UserHandler) Restore() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r)
+		if !ok {
+			return
+		}
+		actor, ok := middleware.FromContext(r.Context())
+		if !ok {
+			res.Json(w, map[string]string{"error": "unauthorized"}, http.StatusUnauthorized)
+			return
+		}
+		user, err := handler.service.Restore(r.Context(), actor, id, r.URL.Query().Get("reason"))
+		if err != nil {
+			writeAdminError(w, err)
+			return
+		}
+		res.Json(w, ToResponse(user), http.StatusOK)
+	}
+}
+
+// pathID разбирает {id} из пути; при ошибке сам отвечает 400.
+func pathID(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 32)
+	if err != nil {
+		res.Json(w, map[string]string{"error": "invalid id"}, http.StatusBadRequest)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func writeAdminError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrUserNotFound):
+		res.Json(w, map[string]string{"error": err.Error()}, http.StatusNotFound)
+	case errors.Is(err, ErrInvalidName), errors.Is(err, ErrInvalidRole), errors.Is(err, ErrSelfAction):
+		res.Json(w, map[string]string{"error": err.Error()}, http.StatusBadRequest)
+	case errors.Is(err, ErrEmailTaken), errors.Is(err, ErrAlreadySuspended), errors.Is(err, ErrNotSuspended),
+		errors.Is(err, ErrNotDeleted), errors.Is(err, ErrErased):
+		res.Json(w, map[string]string{"error": err.Error()}, http.StatusConflict)
+	default:
+		res.Json(w, map[string]string{"error": "failed to update user"}, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,90 @@
+package users
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type cacheEntry struct {
+	user      User
+	expiresAt time.Time
+}
+
+// UserCache — кэш пользователей по ID с коротким TTL для хэндлеров, которым
+// нужна полная запись, а не только Principal из токена. Изменения через
+// UserRepository.Update сразу сбрасывают запись; изменения на других
+// инстансах видны не позже чем через TTL.
+type UserCache struct {
+	repo *UserRepository
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	entries map[uint]cacheEntry
+}
+
+// NewUserCache создаёт кэш и подключает его к repo для сброса при обновлениях.
+func NewUserCache(repo *UserRepository, ttl time.Duration) *UserCache {
+	c := &UserCache{
+		repo:    repo,
+		ttl:     ttl,
+		entries: make(map[uint]cacheEntry),
+	}
+	repo.cache = c
+	return c
+}
+
+// Get возвращает копию пользователя из кэша или из базы.
+func (c *UserCache) Get(id uint) (*User, error) {
+	now := time.Now()
+	c.mu.Lock()
+	if e, ok := c.entries[id]; ok && now.Before(e.expiresAt) {
+		c.mu.Unlock()
+		user := e.user
+		return &user, nil
+	}
+	c.mu.Unlock()
+
+	user, err := c.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if c.ttl > 0 {
+		c.mu.Lock()
+		c.entries[id] = cacheEntry{user: *user, expiresAt: now.Add(c.ttl)}
+		c.prune(now)
+		c.mu.Unlock()
+	}
+	return user, nil
+}
+
+// AccountActive сообщает, что пользователь существует и не заблокирован.
+// При сбое базы запрос пропускается: токен уже проверен, а блокировка
+// дополнительно отзывает токены.
+func (c *UserCache) AccountActive(id uint) bool {
+	user, err := c.Get(id)
+	if err != nil {
+		return !errors.Is(err, gorm.ErrRecordNotFound)
+	}
+	return !user.IsSuspended()
+}
+
+func (c *UserCache) Invalidate(id uint) {
+	c.mu.Lock()
+	delete(c.entries, id)
+	c.mu.Unlock()
+}
+
+// prune удаляет истёкшие записи, когда кэш разрастается. Вызывается под c.mu.
+func (c *UserCache) prune(now time.Time) {
+	if len(c.entries) < 10000 {
+		return
+	}
+	for id, e := range c.entries {
+		if now.After(e.expiresAt) {
+			delete(c.entries, id)
+		}
+	}
+}
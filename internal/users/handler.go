@@ -2,66 +2,118 @@ package users
 
 import (
 	"bike/configs"
-	"bike/pkg/jwt"
+	"bike/pkg/middleware"
+	"bike/pkg/paginate"
+	"bike/pkg/rbac"
+	"bike/pkg/req"
 	"bike/pkg/res"
+	"errors"
 	"net/http"
 	"strconv"
 )
 
+// UserListResponse - страница пользователей. Курсоры заполняются только в режиме ?cursor=
 type UserListResponse struct {
 	Users      []UserResponse `json:"users"`
 	Total      int64          `json:"total"`
 	Page       int            `json:"page"`
 	Limit      int            `json:"limit"`
 	TotalPages int            `json:"total_pages"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+	PrevCursor string         `json:"prev_cursor,omitempty"`
 }
 
 type UserHandlerDeps struct {
 	UserRepository *UserRepository
+	UserService    *UserService
 	Config         *configs.Config
+	Auth           *middleware.AuthDeps
+	Cursors        *paginate.Codec
 }
 
 type UserHandler struct {
-	repo   *UserRepository
-	config *configs.Config
+	repo    *UserRepository
+	service *UserService
+	config  *configs.Config
+	cursors *paginate.Codec
 }
 
 func NewUsersHandler(router *http.ServeMux, deps UserHandlerDeps) {
 	handler := &UserHandler{
-		repo:   deps.UserRepository,
-		config: deps.Config,
+		repo:    deps.UserRepository,
+		service: deps.UserService,
+		config:  deps.Config,
+		cursors: deps.Cursors,
 	}
 
-	router.HandleFunc("GET /users", handler.GetAll())
-	router.HandleFunc("GET /users/{id}", handler.GetByID())
-	router.HandleFunc("GET /users/jwt/{id}", handler.GetJWTForUser())
-	router.HandleFunc("GET /users/search", handler.SearchUsers())
+	// Профиль текущего пользователя
+	router.Handle("GET /users/me", middleware.IsAuthenticated(handler.GetMe(), deps.Auth))
+	// Телефон из профиля даёт вход по SMS, поэтому под имперсонацией профиль не меняется
+	router.Handle("PATCH /users/me", middleware.IsAuthenticated(middleware.ForbidImpersonation(handler.UpdateMe()), deps.Auth))
+
+	// Админские маршруты — нужен токен или API-ключ с соответствующим правом
+	router.Handle("GET /users", middleware.IsAuthenticatedOrAPIKey(middleware.RequirePermission(handler.GetAll(), rbac.PermUsersRead), deps.Auth))
+	router.Handle("GET /users/{id}", middleware.IsAuthenticatedOrAPIKey(middleware.RequirePermission(handler.GetByID(), rbac.PermUsersRead), deps.Auth))
+	router.Handle("GET /users/search", middleware.IsAuthenticatedOrAPIKey(middleware.RequirePermission(handler.SearchUsers(), rbac.PermUsersRead), deps.Auth))
+
+	// Управление пользователями — только администратор по JWT: действие записывается на него
+	router.Handle("PATCH /users/{id}", middleware.IsAuthenticated(middleware.RequirePermission(handler.AdminUpdate(), rbac.PermUsersWrite), deps.Auth))
+	router.Handle("POST /users/{id}/suspend", middleware.IsAuthenticated(middleware.RequirePermission(handler.Suspend(), rbac.PermUsersWrite), deps.Auth))
+	router.Handle("POST /users/{id}/unsuspend", middleware.IsAuthenticated(middleware.RequirePermission(handler.Unsuspend(), rbac.PermUsersWrite), deps.Auth))
+	router.Handle("DELETE /users/{id}", middleware.IsAuthenticated(middleware.RequirePermission(handler.Delete(), rbac.PermUsersWrite), deps.Auth))
+	router.Handle("POST /users/{id}/restore", middleware.IsAuthenticated(middleware.RequirePermission(handler.Restore(), rbac.PermUsersWrite), deps.Auth))
 
 }
 
-func toUserResponse(u *User) UserResponse {
+func ToResponse(u *User) UserResponse {
 	created := ""
 	if !u.CreatedAt.IsZero() {
 		created = u.CreatedAt.Format("2006-01-02T15:04:05Z07:00")
 	}
+	suspended := ""
+	if u.SuspendedAt != nil {
+		suspended = u.SuspendedAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+	deleted := ""
+	if u.DeletedAt.Valid {
+		deleted = u.DeletedAt.Time.Format("2006-01-02T15:04:05Z07:00")
+	}
 	return UserResponse{
-		ID:        u.ID,
-		Email:     u.Email,
-		Name:      u.Name,
-		CreatedAt: created,
+		ID:                u.ID,
+		Email:             u.Email,
+		Name:              u.Name,
+		Role:              string(u.Role),
+		Verified:          u.IsVerified(),
+		Phone:             u.PhoneNumber(),
+		PhoneVerified:     u.PhoneVerifiedAt != nil,
+		PreferredLanguage: u.PreferredLanguage,
+		MarketingConsent:  u.MarketingConsent,
+		Status:            u.Status(),
+		SuspendedAt:       suspended,
+		SuspendReason:     u.SuspendReason,
+		DeletedAt:         deleted,
+		CreatedAt:         created,
 	}
 }
 
 // GetAll godoc
 // @Summary Получить всех пользователей (админ)
-// @Description Возвращает пользователей с пагинацией и фильтрацией
+// @Description Возвращает пользователей с пагинацией и фильтрацией.
+// @Description Если передан cursor (для первой страницы — пустой), пагинация по курсору: next_cursor/prev_cursor в ответе и заголовок Link, page игнорируется
 // @Tags users,admin
 // @Produce json
 // @Param page query int false "page" default(1)
 // @Param limit query int false "limit" default(10)
+// @Param cursor query string false "курсор страницы"
+// @Param sort query string false "name, name_desc, email, email_desc, created_at, created_at_desc"
 // @Param name query string false "filter by name"
 // @Param email query string false "filter by email"
+// @Param status query string false "active, suspended или deleted"
+// @Param include_deleted query bool false "включать удалённых"
 // @Success 200 {object} users.UserListResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /users [get]
 func (handler *UserHandler) GetAll() http.HandlerFunc {
@@ -83,21 +135,43 @@ func (handler *UserHandler) GetAll() http.HandlerFunc {
 		offset := (page - 1) * limit
 
 		// Параметры фильтрации
-		nameFilter := r.URL.Query().Get("name")
-		emailFilter := r.URL.Query().Get("email")
+		filter := ListFilter{
+			Name:   r.URL.Query().Get("name"),
+			Email:  r.URL.Query().Get("email"),
+			Status: r.URL.Query().Get("status"),
+		}
+		switch filter.Status {
+		case "", StatusActive, StatusSuspended, StatusDeleted:
+		default:
+			res.Json(w, map[string]string{"error": "status must be active, suspended or deleted"}, http.StatusBadRequest)
+			return
+		}
+		if raw := r.URL.Query().Get("include_deleted"); raw != "" {
+			includeDeleted, err := strconv.ParseBool(raw)
+			if err != nil {
+				res.Json(w, map[string]string{"error": "invalid include_deleted"}, http.StatusBadRequest)
+				return
+			}
+			filter.IncludeDeleted = includeDeleted
+		}
 
 		// Параметр сортировки
 		sortBy := r.URL.Query().Get("sort")
 
+		if r.URL.Query().Has("cursor") {
+			handler.getPage(w, r, sortBy, filter, limit)
+			return
+		}
+
 		// Получаем данные
-		list, err := handler.repo.ListAll(limit, offset, sortBy, nameFilter, emailFilter)
+		list, err := handler.repo.ListAll(limit, offset, sortBy, filter)
 		if err != nil {
 			res.Json(w, map[string]string{"error": "failed to list users"}, http.StatusInternalServerError)
 			return
 		}
 
 		// Получаем общее количество для пагинации (с учетом фильтров)
-		total, err := handler.repo.Count(nameFilter, emailFilter)
+		total, err := handler.repo.Count(filter)
 		if err != nil {
 			res.Json(w, map[string]string{"error": "failed to count users"}, http.StatusInternalServerError)
 			return
@@ -106,7 +180,7 @@ func (handler *UserHandler) GetAll() http.HandlerFunc {
 		// Формируем ответ
 		out := make([]UserResponse, 0, len(list))
 		for _, u := range list {
-			out = append(out, toUserResponse(&u))
+			out = append(out, ToResponse(&u))
 		}
 
 		totalPages := 0
@@ -126,49 +200,65 @@ func (handler *UserHandler) GetAll() http.HandlerFunc {
 	}
 }
 
-// GetByID godoc
-// @Summary Получить пользователя по ID (админ)
-// @Tags users,admin
-// @Produce json
-// @Param id path int true "ID пользователя"
-// @Success 200 {object} users.UserResponse
-// @Failure 400 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Router /users/{id} [get]
-func (handler *UserHandler) GetByID() http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		idStr := r.PathValue("id")
-		if idStr == "" {
-			res.Json(w, map[string]string{"error": "invalid id"}, http.StatusBadRequest)
-			return
-		}
-		id64, err := strconv.ParseUint(idStr, 10, 32)
-		if err != nil {
-			res.Json(w, map[string]string{"error": "invalid id"}, http.StatusBadRequest)
-			return
-		}
-		id := uint(id64)
+// getPage - отвечает страницей пользователей в режиме курсора
+func (handler *UserHandler) getPage(w http.ResponseWriter, r *http.Request, sortBy string, filter ListFilter, limit int) {
+	cursor, err := handler.cursors.Decode(r.URL.Query().Get("cursor"))
+	if err == nil && cursor != nil && cursor.Sort != sortBy {
+		err = paginate.ErrInvalidCursor
+	}
+	if err != nil {
+		res.Json(w, map[string]string{"error": err.Error()}, http.StatusBadRequest)
+		return
+	}
 
-		user, err := handler.repo.FindByID(id)
-		if err != nil {
-			res.Json(w, map[string]string{"error": "user not found"}, http.StatusNotFound)
+	list, more, err := handler.repo.ListAfter(sortBy, filter, cursor, limit)
+	if err != nil {
+		if errors.Is(err, paginate.ErrInvalidCursor) {
+			res.Json(w, map[string]string{"error": err.Error()}, http.StatusBadRequest)
 			return
 		}
-		res.Json(w, toUserResponse(user), http.StatusOK)
+		res.Json(w, map[string]string{"error": "failed to list users"}, http.StatusInternalServerError)
+		return
+	}
+	total, err := handler.repo.Count(filter)
+	if err != nil {
+		res.Json(w, map[string]string{"error": "failed to count users"}, http.StatusInternalServerError)
+		return
 	}
 
+	out := make([]UserResponse, 0, len(list))
+	for _, u := range list {
+		out = append(out, ToResponse(&u))
+	}
+	var first, last *paginate.Cursor
+	if len(list) > 0 {
+		first, last = CursorFor(&list[0], sortBy), CursorFor(&list[len(list)-1], sortBy)
+	}
+	next, prev := handler.cursors.Neighbours(cursor, more, first, last)
+	paginate.SetLinkHeader(w, r, next, prev)
+
+	res.Json(w, UserListResponse{
+		Users:      out,
+		Total:      total,
+		Limit:      limit,
+		TotalPages: (int(total) + limit - 1) / limit,
+		NextCursor: next,
+		PrevCursor: prev,
+	}, http.StatusOK)
 }
 
-// GetJWTForUser godoc
-// @Summary Возвращает jwt-token для пользователя по ID (админ)
+// GetByID godoc
+// @Summary Получить пользователя по ID (админ)
 // @Tags users,admin
 // @Produce json
 // @Param id path int true "ID пользователя"
-// @Success 200 {object} map[string]string
+// @Success 200 {object} users.UserResponse
 // @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Failure 404 {object} map[string]string
-// @Router /users/jwt/{id} [get]
-func (handler *UserHandler) GetJWTForUser() http.HandlerFunc {
+// @Router /users/{id} [get]
+func (handler *UserHandler) GetByID() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		idStr := r.PathValue("id")
 		if idStr == "" {
@@ -187,16 +277,9 @@ func (handler *UserHandler) GetJWTForUser() http.HandlerFunc {
 			res.Json(w, map[string]string{"error": "user not found"}, http.StatusNotFound)
 			return
 		}
-
-		token, err := jwt.NewJWT(handler.config.Auth.Secret).GenerateToken(jwt.JWTData{
-			Email: user.Email,
-		})
-		if err != nil {
-			res.Json(w, map[string]string{"error": "failed to generate token"}, http.StatusInternalServerError)
-			return
-		}
-		res.Json(w, map[string]string{"token": token}, http.StatusOK)
+		res.Json(w, ToResponse(user), http.StatusOK)
 	}
+
 }
 
 // SearchUsers godoc
@@ -206,6 +289,8 @@ func (handler *UserHandler) GetJWTForUser() http.HandlerFunc {
 // @Param email query string true "email"
 // @Success 200 {array} users.UserResponse
 // @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /users/search [get]
 func (handler *UserHandler) SearchUsers() http.HandlerFunc {
@@ -224,8 +309,74 @@ func (handler *UserHandler) SearchUsers() http.HandlerFunc {
 
 		out := make([]UserResponse, 0, len(users))
 		for _, u := range users {
-			out = append(out, toUserResponse(&u))
+			out = append(out, ToResponse(&u))
 		}
 		res.Json(w, out, http.StatusOK)
 	}
 }
+
+// GetMe godoc
+// @Summary Мой профиль
+// @Description Возвращает профиль текущего пользователя
+// @Tags users,jwt,user
+// @Produce json
+// @Success 200 {object} users.UserResponse
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/me [get]
+func (handler *UserHandler) GetMe() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := middleware.FromContext(r.Context())
+		if !ok {
+			res.Json(w, map[string]string{"error": "unauthorized"}, http.StatusUnauthorized)
+			return
+		}
+		user, err := handler.service.GetProfile(r.Context(), principal)
+		if err != nil {
+			res.Json(w, map[string]string{"error": "failed to get profile"}, http.StatusInternalServerError)
+			return
+		}
+		res.Json(w, ToResponse(user), http.StatusOK)
+	}
+}
+
+// UpdateMe godoc
+// @Summary Изменить мой профиль
+// @Description Меняет имя, телефон, язык (ru, en) и согласие на рекламные рассылки. Передаются только изменяемые поля; пустой phone удаляет телефон.
+// @Description Email и пароль меняются через /users/me/email и /users/me/password
+// @Tags users,jwt,user
+// @Accept json
+// @Produce json
+// @Param request body users.UpdateProfileRequest true "Изменяемые поля"
+// @Success 200 {object} users.UserResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/me [patch]
+func (handler *UserHandler) UpdateMe() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := req.HandleBody[UpdateProfileRequest](&w, r)
+		if err != nil {
+			return
+		}
+		principal, ok := middleware.FromContext(r.Context())
+		if !ok {
+			res.Json(w, map[string]string{"error": "unauthorized"}, http.StatusUnauthorized)
+			return
+		}
+		user, err := handler.service.UpdateProfile(r.Context(), principal, *body)
+		if err != nil {
+			switch {
+			case errors.Is(err, ErrInvalidPhone), errors.Is(err, ErrInvalidName):
+				res.Json(w, map[string]string{"error": err.Error()}, http.StatusBadRequest)
+			case errors.Is(err, ErrPhoneTaken):
+				res.Json(w, map[string]string{"error": err.Error()}, http.StatusConflict)
+			default:
+				res.Json(w, map[string]string{"error": "failed to update profile"}, http.StatusInternalServerError)
+			}
+			return
+		}
+		res.Json(w, ToResponse(user), http.StatusOK)
+	}
+}
@@ -1,10 +1,107 @@
 package users
 
-import "gorm.io/gorm"
+import (
+	"bike/pkg/rbac"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
 
 type User struct {
 	gorm.Model `swaggerignore:"true"`
 	Email      string `gorm:"index"`
 	Password   string
 	Name       string
+	Role       rbac.Role `gorm:"size:32;not null;default:customer"`
+	VerifiedAt *time.Time
+	// Телефон в формате E.164; NULL, если не указан
+	Phone           *string `gorm:"size:32;uniqueIndex"`
+	PhoneVerifiedAt *time.Time
+	// Язык писем и SMS: ru или en
+	PreferredLanguage string `gorm:"size:8;not null;default:ru"`
+	// Согласие на рекламные рассылки и момент, когда оно дано
+	MarketingConsent   bool `gorm:"not null;default:false"`
+	MarketingConsentAt *time.Time
+	// Блокировка администратором: пока задана, вход и запросы с токеном отклоняются
+	SuspendedAt   *time.Time
+	SuspendReason string `gorm:"size:500"`
+}
+
+// Статусы аккаунта для ответов API и фильтра status.
+const (
+	StatusActive    = "active"
+	StatusSuspended = "suspended"
+	StatusDeleted   = "deleted"
+)
+
+// ErasedEmailDomain — домен, на который заменяется email при удалении
+// персональных данных. Такие аккаунты не восстанавливаются.
+const ErasedEmailDomain = "erased.invalid"
+
+// Действия администратора, записываемые в журнал StatusChange.
+const (
+	ActionUpdate    = "update"
+	ActionSuspend   = "suspend"
+	ActionUnsuspend = "unsuspend"
+	ActionDelete    = "delete"
+	ActionRestore   = "restore"
+)
+
+// StatusChange — запись журнала действий администратора над аккаунтом.
+type StatusChange struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"index;not null"`
+	ActorID   uint   `gorm:"index;not null"`
+	Action    string `gorm:"size:16;not null"`
+	Reason    string `gorm:"size:500"`
+	CreatedAt time.Time
+}
+
+// IsVerified сообщает, подтверждён ли email пользователя.
+func (u *User) IsVerified() bool {
+	return u.VerifiedAt != nil
+}
+
+// HasPassword сообщает, задан ли пароль. У пользователей, созданных при входе
+// через OIDC, пароля нет, пока они не зададут его через сброс пароля.
+func (u *User) HasPassword() bool {
+	return u.Password != ""
+}
+
+// IsSuspended сообщает, заблокирован ли аккаунт.
+func (u *User) IsSuspended() bool {
+	return u.SuspendedAt != nil
+}
+
+// IsErased сообщает, что персональные данные пользователя удалены.
+func (u *User) IsErased() bool {
+	return strings.HasSuffix(u.Email, "@"+ErasedEmailDomain)
+}
+
+// Status возвращает статус аккаунта: active, suspended или deleted.
+func (u *User) Status() string {
+	switch {
+	case u.DeletedAt.Valid:
+		return StatusDeleted
+	case u.IsSuspended():
+		return StatusSuspended
+	default:
+		return StatusActive
+	}
+}
+
+// PhoneNumber возвращает телефон пользователя или пустую строку.
+func (u *User) PhoneNumber() string {
+	if u.Phone == nil {
+		return ""
+	}
+	return *u.Phone
+}
+
+// BackfillVerifiedAt считает подтверждёнными пользователей, появившихся до
+// подтверждения email: иначе при REQUIRE_VERIFIED_EMAIL они разом теряют
+// доступ к адресам. Вызывается из миграций один раз — в запуск, который
+// добавляет колонку verified_at; позже NULL означает «ещё не подтвердил».
+func BackfillVerifiedAt(db *gorm.DB) error {
+	return db.Exec("UPDATE users SET verified_at = created_at WHERE verified_at IS NULL").Error
 }
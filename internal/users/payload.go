@@ -1,8 +1,39 @@
 package users
 
 type UserResponse struct {
-	ID        uint   `json:"id" example:"1"`
-	Email     string `json:"email" example:"john.doe@example.com"`
-	Name      string `json:"name" example:"John Doe"`
-	CreatedAt string `json:"created_at" example:"2025-10-07T12:00:00Z"`
+	ID                uint   `json:"id" example:"1"`
+	Email             string `json:"email" example:"john.doe@example.com"`
+	Name              string `json:"name" example:"John Doe"`
+	Role              string `json:"role" example:"customer"`
+	Verified          bool   `json:"email_verified" example:"true"`
+	Phone             string `json:"phone,omitempty" example:"+79001234567"`
+	PhoneVerified     bool   `json:"phone_verified" example:"false"`
+	PreferredLanguage string `json:"preferred_language" example:"ru"`
+	MarketingConsent  bool   `json:"marketing_consent" example:"false"`
+	Status            string `json:"status" example:"active"`
+	SuspendedAt       string `json:"suspended_at,omitempty" example:"2025-11-01T09:30:00Z"`
+	SuspendReason     string `json:"suspend_reason,omitempty" example:"Спам в отзывах"`
+	DeletedAt         string `json:"deleted_at,omitempty" example:"2025-11-02T10:00:00Z"`
+	CreatedAt         string `json:"created_at" example:"2025-10-07T12:00:00Z"`
+}
+
+// UpdateProfileRequest — изменение своего профиля; nil — поле не меняется.
+// Пустой phone удаляет телефон.
+type UpdateProfileRequest struct {
+	Name              *string `json:"name,omitempty" validate:"omitempty,min=1,max=100" example:"Ivan"`
+	Phone             *string `json:"phone,omitempty" validate:"omitempty,max=32" example:"+7 900 123-45-67"`
+	PreferredLanguage *string `json:"preferred_language,omitempty" validate:"omitempty,oneof=ru en" example:"en"`
+	MarketingConsent  *bool   `json:"marketing_consent,omitempty" example:"true"`
+}
+
+// AdminUpdateUserRequest — изменение пользователя администратором; nil — поле не меняется.
+type AdminUpdateUserRequest struct {
+	Name  *string `json:"name,omitempty" validate:"omitempty,min=1,max=100" example:"Ivan"`
+	Email *string `json:"email,omitempty" validate:"omitempty,email" example:"ivan@example.com"`
+	Role  *string `json:"role,omitempty" validate:"omitempty,oneof=customer manager admin" example:"manager"`
+}
+
+// StatusChangeRequest — причина блокировки или разблокировки.
+type StatusChangeRequest struct {
+	Reason string `json:"reason" validate:"required,max=500" example:"Спам в отзывах"`
 }
@@ -2,10 +2,16 @@ package users
 
 import (
 	"bike/pkg/db"
+	"bike/pkg/paginate"
+	"time"
+
+	"gorm.io/gorm"
 )
 
 type UserRepository struct {
 	database *db.Db
+	// Необязательный кэш, сбрасывается при Update (см. NewUserCache)
+	cache *UserCache
 }
 
 func NewUserRepository(database *db.Db) *UserRepository {
@@ -14,6 +20,12 @@ func NewUserRepository(database *db.Db) *UserRepository {
 	}
 }
 
+// WithTx возвращает репозиторий, работающий в транзакции tx. Кэш общий,
+// чтобы изменения в транзакции тоже сбрасывали его.
+func (repo *UserRepository) WithTx(tx *gorm.DB) *UserRepository {
+	return &UserRepository{database: &db.Db{DB: tx}, cache: repo.cache}
+}
+
 func (repo *UserRepository) Create(user *User) (*User, error) {
 	result := repo.database.DB.Create(user)
 	if result.Error != nil {
@@ -31,6 +43,15 @@ func (repo *UserRepository) FindByEmail(email string) (*User, error) {
 	return &user, nil
 }
 
+func (repo *UserRepository) FindByPhone(phone string) (*User, error) {
+	var user User
+	result := repo.database.DB.First(&user, "phone = ?", phone)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &user, nil
+}
+
 func (repo *UserRepository) FindByID(id uint) (*User, error) {
 	var user User
 	result := repo.database.DB.First(&user, id)
@@ -40,39 +61,52 @@ func (repo *UserRepository) FindByID(id uint) (*User, error) {
 	return &user, nil
 }
 
-// ListAll - получает всех пользователей с пагинацией и сортировкой
-func (repo *UserRepository) ListAll(limit, offset int, sortBy, nameFilter, emailFilter string) ([]User, error) {
-	var list []User
+// ListFilter — фильтры списка пользователей для админки.
+type ListFilter struct {
+	Name  string
+	Email string
+	// active, suspended или deleted; пусто — без фильтра по статусу
+	Status string
+	// Включать удалённых (soft delete) пользователей
+	IncludeDeleted bool
+}
 
-	database := repo.database.DB
+// filtered применяет фильтры к запросу
+func (repo *UserRepository) filtered(filter ListFilter) *gorm.DB {
+	database := repo.database.DB.Model(&User{})
+	if filter.IncludeDeleted || filter.Status == StatusDeleted {
+		database = database.Unscoped()
+	}
 
 	// ФИЛЬТРАЦИЯ по имени
-	if nameFilter != "" {
-		database = database.Where("name LIKE ?", "%"+nameFilter+"%")
+	if filter.Name != "" {
+		database = database.Where("name LIKE ?", "%"+filter.Name+"%")
 	}
 
 	// ФИЛЬТРАЦИЯ по email
-	if emailFilter != "" {
-		database = database.Where("email LIKE ?", "%"+emailFilter+"%")
+	if filter.Email != "" {
+		database = database.Where("email LIKE ?", "%"+filter.Email+"%")
 	}
 
-	// СОРТИРОВКА
-	switch sortBy {
-	case "name":
-		database = database.Order("name asc")
-	case "name_desc":
-		database = database.Order("name desc")
-	case "email":
-		database = database.Order("email asc")
-	case "email_desc":
-		database = database.Order("email desc")
-	case "created_at":
-		database = database.Order("created_at asc")
-	case "created_at_desc":
-		database = database.Order("created_at desc")
-	default:
-		database = database.Order("created_at desc") // по умолчанию
+	// ФИЛЬТРАЦИЯ по статусу
+	switch filter.Status {
+	case StatusActive:
+		database = database.Where("suspended_at IS NULL AND deleted_at IS NULL")
+	case StatusSuspended:
+		database = database.Where("suspended_at IS NOT NULL AND deleted_at IS NULL")
+	case StatusDeleted:
+		database = database.Where("deleted_at IS NOT NULL")
 	}
+	return database
+}
+
+// ListAll - получает всех пользователей с пагинацией и сортировкой
+func (repo *UserRepository) ListAll(limit, offset int, sortBy string, filter ListFilter) ([]User, error) {
+	var list []User
+
+	// СОРТИРОВКА, при равных значениях — по ID
+	key := userSortKeyFor(sortBy)
+	database := repo.filtered(filter).Order(paginate.Order(key.column, key.desc, false))
 
 	// Пагинация
 	if limit > 0 {
@@ -86,19 +120,73 @@ func (repo *UserRepository) ListAll(limit, offset int, sortBy, nameFilter, email
 	return list, nil
 }
 
-// Count - возвращает общее количество пользователей
-func (repo *UserRepository) Count(nameFilter, emailFilter string) (int64, error) {
-	var count int64
-	database := repo.database.DB.Model(&User{})
+// ListAfter - получает до limit пользователей после курсора (nil — с начала).
+// more — есть ли ещё пользователи в направлении движения.
+func (repo *UserRepository) ListAfter(sortBy string, filter ListFilter, cur *paginate.Cursor, limit int) ([]User, bool, error) {
+	key := userSortKeyFor(sortBy)
+	backward := cur != nil && cur.Backward
+	database := repo.filtered(filter)
+	if cur != nil {
+		value, err := key.parse(cur.Key)
+		if err != nil {
+			return nil, false, paginate.ErrInvalidCursor
+		}
+		database = database.Where(paginate.Seek(key.column, key.desc, backward), value, cur.ID)
+	}
 
-	if nameFilter != "" {
-		database = database.Where("name LIKE ?", "%"+nameFilter+"%")
+	var list []User
+	result := database.Order(paginate.Order(key.column, key.desc, backward)).Limit(limit + 1).Find(&list)
+	if result.Error != nil {
+		return nil, false, result.Error
 	}
-	if emailFilter != "" {
-		database = database.Where("email LIKE ?", "%"+emailFilter+"%")
+	list, more := paginate.Trim(list, limit, backward)
+	return list, more, nil
+}
+
+// userSortKey - колонка сортировки списка и преобразования её значения для курсора
+type userSortKey struct {
+	column string
+	desc   bool
+	value  func(u *User) string
+	parse  func(s string) (interface{}, error)
+}
+
+func userSortKeyFor(sortBy string) userSortKey {
+	switch sortBy {
+	case "name", "name_desc":
+		return userSortKey{
+			column: "name",
+			desc:   sortBy == "name_desc",
+			value:  func(u *User) string { return u.Name },
+			parse:  func(s string) (interface{}, error) { return s, nil },
+		}
+	case "email", "email_desc":
+		return userSortKey{
+			column: "email",
+			desc:   sortBy == "email_desc",
+			value:  func(u *User) string { return u.Email },
+			parse:  func(s string) (interface{}, error) { return s, nil },
+		}
+	default:
+		// по умолчанию — сначала новые
+		return userSortKey{
+			column: "created_at",
+			desc:   sortBy != "created_at",
+			value:  func(u *User) string { return u.CreatedAt.Format(time.RFC3339Nano) },
+			parse:  func(s string) (interface{}, error) { return time.Parse(time.RFC3339Nano, s) },
+		}
 	}
+}
 
-	result := database.Count(&count)
+// CursorFor - возвращает позицию пользователя в списке, отсортированном по sortBy
+func CursorFor(u *User, sortBy string) *paginate.Cursor {
+	return &paginate.Cursor{Sort: sortBy, Key: userSortKeyFor(sortBy).value(u), ID: u.ID}
+}
+
+// Count - возвращает общее количество пользователей
+func (repo *UserRepository) Count(filter ListFilter) (int64, error) {
+	var count int64
+	result := repo.filtered(filter).Count(&count)
 	if result.Error != nil {
 		return 0, result.Error
 	}
@@ -123,3 +211,47 @@ func (repo *UserRepository) SearchByEmail(email string) ([]User, error) {
 	}
 	return users, nil
 }
+
+// Delete помечает пользователя удалённым (soft delete).
+func (repo *UserRepository) Delete(id uint) error {
+	result := repo.database.DB.Delete(&User{}, id)
+	if repo.cache != nil {
+		repo.cache.Invalidate(id)
+	}
+	return result.Error
+}
+
+// FindByIDWithDeleted ищет пользователя, включая удалённых.
+func (repo *UserRepository) FindByIDWithDeleted(id uint) (*User, error) {
+	var user User
+	result := repo.database.DB.Unscoped().First(&user, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &user, nil
+}
+
+// Restore снимает пометку об удалении.
+func (repo *UserRepository) Restore(id uint) error {
+	result := repo.database.DB.Unscoped().Model(&User{}).Where("id = ?", id).Update("deleted_at", nil)
+	if repo.cache != nil {
+		repo.cache.Invalidate(id)
+	}
+	return result.Error
+}
+
+// CreateStatusChange записывает действие администратора в журнал.
+func (repo *UserRepository) CreateStatusChange(change *StatusChange) error {
+	return repo.database.DB.Create(change).Error
+}
+
+func (repo *UserRepository) Update(user *User) (*User, error) {
+	result := repo.database.DB.Save(user)
+	if repo.cache != nil {
+		repo.cache.Invalidate(user.ID)
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return user, nil
+}
@@ -0,0 +1,99 @@
+package users
+
+import (
+	"bike/pkg/middleware"
+	"bike/pkg/phone"
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidPhone = errors.New("invalid phone number")
+	ErrPhoneTaken   = errors.New("phone number already in use")
+	ErrInvalidName  = errors.New("name must not be empty")
+)
+
+// SessionRevoker отзывает все токены и сессии пользователя.
+// Реализуется auth.AuthService.
+type SessionRevoker interface {
+	RevokeUserSessions(ctx context.Context, user *User) error
+}
+
+type UserService struct {
+	repo     *UserRepository
+	cache    *UserCache
+	sessions SessionRevoker
+}
+
+func NewUserService(repo *UserRepository, cache *UserCache, sessions SessionRevoker) *UserService {
+	return &UserService{
+		repo:     repo,
+		cache:    cache,
+		sessions: sessions,
+	}
+}
+
+// GetProfile возвращает профиль текущего пользователя.
+func (s *UserService) GetProfile(ctx context.Context, principal *middleware.Principal) (*User, error) {
+	return s.cache.Get(principal.UserID)
+}
+
+// UpdateProfile меняет имя, телефон, язык и согласие на рассылки.
+// Новый телефон нужно заново подтвердить через /users/me/phone/verify и
+// /users/me/phone/confirm.
+func (s *UserService) UpdateProfile(ctx context.Context, principal *middleware.Principal, in UpdateProfileRequest) (*User, error) {
+	user, err := s.repo.FindByID(principal.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Name != nil {
+		name := strings.TrimSpace(*in.Name)
+		if name == "" {
+			return nil, ErrInvalidName
+		}
+		user.Name = name
+	}
+	if in.Phone != nil {
+		if err := s.setPhone(user, *in.Phone); err != nil {
+			return nil, err
+		}
+	}
+	if in.PreferredLanguage != nil {
+		user.PreferredLanguage = *in.PreferredLanguage
+	}
+	if in.MarketingConsent != nil && *in.MarketingConsent != user.MarketingConsent {
+		user.MarketingConsent = *in.MarketingConsent
+		if user.MarketingConsent {
+			now := time.Now()
+			user.MarketingConsentAt = &now
+		} else {
+			user.MarketingConsentAt = nil
+		}
+	}
+
+	return s.repo.Update(user)
+}
+
+func (s *UserService) setPhone(user *User, raw string) error {
+	if strings.TrimSpace(raw) == "" {
+		user.Phone = nil
+		user.PhoneVerifiedAt = nil
+		return nil
+	}
+	number, err := phone.Normalize(raw)
+	if err != nil {
+		return ErrInvalidPhone
+	}
+	if user.PhoneNumber() == number {
+		return nil
+	}
+	if taken, _ := s.repo.FindByPhone(number); taken != nil {
+		return ErrPhoneTaken
+	}
+	user.Phone = &number
+	user.PhoneVerifiedAt = nil
+	return nil
+}
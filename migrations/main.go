@@ -2,8 +2,14 @@ package main
 
 import (
 	"bike/internal/addresses"
+	"bike/internal/apikeys"
+	"bike/internal/auth"
+	"bike/internal/categories"
+	"bike/internal/impersonation"
+	"bike/internal/privacy"
 	"bike/internal/products"
 	"bike/internal/users"
+	"bike/pkg/rbac"
 	"fmt"
 	"log"
 	"os"
@@ -38,15 +44,74 @@ func main() {
 		log.Fatal("Failed to connect to database after multiple attempts:", err)
 	}
 
+	// Триграммные индексы для подсказок по названию продукта
+	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+		log.Fatal("Failed to enable pg_trgm:", err)
+	}
+
+	// Пользователи, зарегистрированные до подтверждения email, считаются подтверждёнными
+	backfillVerified := db.Migrator().HasTable(&users.User{}) && !db.Migrator().HasColumn(&users.User{}, "VerifiedAt")
+
 	// Выполняем миграции
 	err = db.AutoMigrate(
+		&categories.Category{},
 		&products.Product{},
+		&products.ProductVariant{},
+		&products.ModifierGroup{},
+		&products.Modifier{},
 		&users.User{},
+		&users.StatusChange{},
 		&addresses.Address{},
+		&auth.RefreshToken{},
+		&auth.TokenRevocation{},
+		&auth.UserToken{},
+		&auth.LoginAttempt{},
+		&auth.TwoFactor{},
+		&auth.RecoveryCode{},
+		&auth.Identity{},
+		&auth.OIDCState{},
+		&auth.Session{},
+		&auth.PhoneCode{},
+		&apikeys.APIKey{},
+		&privacy.Erasure{},
+		&impersonation.Impersonation{},
+		&impersonation.Action{},
 	)
 	if err != nil {
 		log.Fatal("Migration failed:", err)
 	}
 
+	if backfillVerified {
+		if err := users.BackfillVerifiedAt(db); err != nil {
+			log.Fatal("Failed to mark existing users as verified:", err)
+		}
+	}
+
+	// Полнотекстовый индекс для продуктов, созданных до появления поиска
+	if err := products.BackfillSearchVectors(db); err != nil {
+		log.Fatal("Failed to build product search vectors:", err)
+	}
+	if err := products.BackfillNameTranslit(db); err != nil {
+		log.Fatal("Failed to fill product name transliterations:", err)
+	}
+	if err := products.BackfillFromPrice(db); err != nil {
+		log.Fatal("Failed to fill product from prices:", err)
+	}
+	// Категории из свободного поля Type
+	if err := products.BackfillCategories(db); err != nil {
+		log.Fatal("Failed to convert product types to categories:", err)
+	}
+
+	// Назначаем первого администратора, если указан ADMIN_EMAIL
+	if adminEmail := os.Getenv("ADMIN_EMAIL"); adminEmail != "" {
+		result := db.Model(&users.User{}).Where("email = ?", adminEmail).Update("role", rbac.RoleAdmin)
+		if result.Error != nil {
+			log.Fatal("Failed to grant admin role:", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			log.Printf("ADMIN_EMAIL=%s: user not found, role not granted", adminEmail)
+		}
+	}
+
 	fmt.Println("✅ Database migrated successfully!")
 }
@@ -0,0 +1,108 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+)
+
+// JWK — публичный ключ в формате RFC 7517.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use,omitempty"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519 (OKP)
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS — набор ключей, отдаваемый на /.well-known/jwks.json.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS возвращает публичные части асимметричных ключей, которые ещё
+// принимаются для проверки. HMAC-ключи не публикуются.
+func (k *Keyring) JWKS(now time.Time) JWKS {
+	out := JWKS{Keys: []JWK{}}
+	for id, key := range k.keys {
+		if _, err := k.VerificationKey(id, now); err != nil {
+			continue
+		}
+		switch pub := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			out.Keys = append(out.Keys, JWK{
+				Kty: "RSA",
+				Kid: key.ID,
+				Alg: key.Method.Alg(),
+				Use: "sig",
+				N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			out.Keys = append(out.Keys, JWK{
+				Kty: "OKP",
+				Kid: key.ID,
+				Alg: key.Method.Alg(),
+				Use: "sig",
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+	sort.Slice(out.Keys, func(i, j int) bool { return out.Keys[i].Kid < out.Keys[j].Kid })
+	return out
+}
+
+// PublicKey восстанавливает ключ для проверки подписи из JWK.
+func (j JWK) PublicKey() (*Key, error) {
+	switch j.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(j.N)
+		if err != nil {
+			return nil, fmt.Errorf("jwk %q: bad n: %w", j.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(j.E)
+		if err != nil {
+			return nil, fmt.Errorf("jwk %q: bad e: %w", j.Kid, err)
+		}
+		return NewPublicKey(j.Kid, &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		})
+	case "OKP":
+		if j.Crv != "Ed25519" {
+			return nil, fmt.Errorf("jwk %q: unsupported curve %q", j.Kid, j.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(j.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("jwk %q: bad x", j.Kid)
+		}
+		return NewPublicKey(j.Kid, ed25519.PublicKey(x))
+	default:
+		return nil, fmt.Errorf("jwk %q: unsupported key type %q", j.Kid, j.Kty)
+	}
+}
+
+// NewKeyringFromJWKS строит keyring только для проверки подписи — так другие
+// сервисы могут проверять наши токены по опубликованному JWKS.
+// Ключи неподдерживаемых типов пропускаются.
+func NewKeyringFromJWKS(set JWKS) *Keyring {
+	ring := NewKeyring(0)
+	for _, jwk := range set.Keys {
+		key, err := jwk.PublicKey()
+		if err != nil {
+			continue
+		}
+		ring.Add(key)
+	}
+	return ring
+}
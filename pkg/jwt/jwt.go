@@ -1,41 +1,157 @@
 package jwt
 
-import "github.com/golang-jwt/jwt/v5"
+import (
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func init() {
+	// iat, exp и nbf пишутся с точностью до микросекунды: отзыв всех токенов
+	// пользователя сравнивает iat с моментом отзыва, и секундной точности
+	// мало — токен, выданный в ту же секунду до отзыва, пережил бы его
+	jwt.TimePrecision = time.Microsecond
+}
 
 type JWTData struct {
-	Email string `json:"email"`
+	// ID пользователя, в токене — claim sub
+	UserID    uint      `json:"sub"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	ID        string    `json:"jti"`
+	IssuedAt  time.Time `json:"iat"`
+	ExpiresAt time.Time `json:"exp"`
+	// Сессия (устройство), в которой выдан токен
+	SessionID string `json:"sid"`
+	// Администратор, действующий от имени пользователя; nil — обычный вход
+	Actor *Actor `json:"act,omitempty"`
+}
+
+// Actor — тот, кто на самом деле выполняет запросы по токену (claim act, RFC 8693).
+type Actor struct {
+	UserID uint
+	Email  string
+}
+
+type actorClaim struct {
+	Subject string `json:"sub"`
+	Email   string `json:"email,omitempty"`
+}
+
+type claims struct {
+	Email     string      `json:"email"`
+	Role      string      `json:"role,omitempty"`
+	SessionID string      `json:"sid,omitempty"`
+	Act       *actorClaim `json:"act,omitempty"`
+	jwt.RegisteredClaims
 }
 
 type JWT struct {
-	Secret string
+	Keys *Keyring
 }
 
+// NewJWT — JWT с единственным HMAC-ключом (HS256).
 func NewJWT(secret string) *JWT {
+	ring := NewKeyring(0)
+	ring.Add(NewSecretKey(SecretKeyID, secret))
 	return &JWT{
-		Secret: secret,
+		Keys: ring,
 	}
 }
 
+// NewJWTWithKeyring — JWT поверх набора ключей (ротация, RS256/EdDSA).
+func NewJWTWithKeyring(keys *Keyring) *JWT {
+	return &JWT{
+		Keys: keys,
+	}
+}
+
+// GenerateToken подписывает токен. ExpiresAt обязателен, ID (jti) и IssuedAt
+// проставляются автоматически, если не заданы.
 func (j *JWT) GenerateToken(data JWTData) (string, error) {
-	t := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"email": data.Email,
-	})
-	s, err := t.SignedString([]byte(j.Secret))
+	if data.ID == "" {
+		data.ID = uuid.NewString()
+	}
+	if data.IssuedAt.IsZero() {
+		data.IssuedAt = time.Now()
+	}
+	key, err := j.Keys.SigningKey()
+	if err != nil {
+		return "", err
+	}
+	c := claims{
+		Email:     data.Email,
+		Role:      data.Role,
+		SessionID: data.SessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   Subject(data.UserID),
+			ID:        data.ID,
+			IssuedAt:  jwt.NewNumericDate(data.IssuedAt),
+			ExpiresAt: jwt.NewNumericDate(data.ExpiresAt),
+		},
+	}
+	if data.Actor != nil {
+		c.Act = &actorClaim{Subject: Subject(data.Actor.UserID), Email: data.Actor.Email}
+	}
+	t := jwt.NewWithClaims(key.Method, c)
+	t.Header["kid"] = key.ID
+	s, err := t.SignedString(key.signKey)
 	if err != nil {
 		return "", err
 	}
 	return s, nil
 }
 
+// ParseToken проверяет подпись ключом из заголовка kid и срок действия.
+// Токены без exp не принимаются.
 func (j *JWT) ParseToken(token string) (bool, *JWTData) {
-	t, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
-		return []byte(j.Secret), nil
-	})
-	if err != nil {
+	var c claims
+	t, err := jwt.ParseWithClaims(token, &c, j.keyFunc,
+		jwt.WithValidMethods(j.Keys.Methods()),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil || c.Email == "" {
 		return false, nil
 	}
-	email := t.Claims.(jwt.MapClaims)["email"].(string)
-	return t.Valid, &JWTData{
-		Email: email,
+	data := &JWTData{
+		Email:     c.Email,
+		Role:      c.Role,
+		ID:        c.ID,
+		SessionID: c.SessionID,
+		ExpiresAt: c.ExpiresAt.Time,
+	}
+	if c.IssuedAt != nil {
+		data.IssuedAt = c.IssuedAt.Time
+	}
+	if c.Subject != "" {
+		id, err := strconv.ParseUint(c.Subject, 10, 64)
+		if err != nil {
+			return false, nil
+		}
+		data.UserID = uint(id)
+	}
+	if c.Act != nil {
+		// Токен имперсонации без корректного act не принимаем: иначе он
+		// сошёл бы за обычный токен пользователя
+		id, err := strconv.ParseUint(c.Act.Subject, 10, 64)
+		if err != nil || id == 0 {
+			return false, nil
+		}
+		data.Actor = &Actor{UserID: uint(id), Email: c.Act.Email}
+	}
+	return t.Valid, data
+}
+
+func (j *JWT) keyFunc(t *jwt.Token) (interface{}, error) {
+	return j.Keys.Keyfunc(t)
+}
+
+// Subject — значение claim sub для пользователя.
+func Subject(userID uint) string {
+	if userID == 0 {
+		return ""
 	}
+	return strconv.FormatUint(uint64(userID), 10)
 }
@@ -0,0 +1,240 @@
+package jwt
+
+import (
+	"bike/configs"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SecretKeyID — kid HMAC-ключа из SECRET. Токены без kid (выданные до появления
+// keyring) проверяются этим ключом.
+const SecretKeyID = "hs256"
+
+var (
+	ErrUnknownKey = errors.New("unknown signing key")
+	ErrKeyRetired = errors.New("signing key retired")
+)
+
+// Key — один ключ keyring. Для асимметричных ключей без приватной части
+// (только PUBLIC KEY) ключ годится лишь для проверки подписи.
+type Key struct {
+	ID        string
+	Method    jwt.SigningMethod
+	RetiredAt time.Time
+
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// CanSign сообщает, есть ли у ключа приватная часть.
+func (k *Key) CanSign() bool {
+	return k.signKey != nil
+}
+
+// Keyring — набор ключей, выбираемых по заголовку kid. Подписываем одним
+// ключом, проверяем любым из активных. Выведенный из оборота ключ (RetiredAt)
+// ещё grace принимается для проверки, чтобы уже выданные токены дожили до exp.
+type Keyring struct {
+	keys    map[string]*Key
+	signing *Key
+	grace   time.Duration
+}
+
+func NewKeyring(grace time.Duration) *Keyring {
+	return &Keyring{
+		keys:  make(map[string]*Key),
+		grace: grace,
+	}
+}
+
+// Add добавляет ключ. Первый добавленный ключ, способный подписывать,
+// становится ключом подписи, пока не вызван SetSigningKey.
+func (k *Keyring) Add(key *Key) error {
+	if key.ID == "" {
+		return errors.New("key id is required")
+	}
+	if _, ok := k.keys[key.ID]; ok {
+		return fmt.Errorf("duplicate key id %q", key.ID)
+	}
+	k.keys[key.ID] = key
+	if k.signing == nil && key.CanSign() && key.RetiredAt.IsZero() {
+		k.signing = key
+	}
+	return nil
+}
+
+// SetSigningKey выбирает ключ подписи по kid.
+func (k *Keyring) SetSigningKey(id string) error {
+	key, ok := k.keys[id]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownKey, id)
+	}
+	if !key.CanSign() {
+		return fmt.Errorf("key %q has no private part", id)
+	}
+	if !key.RetiredAt.IsZero() {
+		return fmt.Errorf("%w: %q", ErrKeyRetired, id)
+	}
+	k.signing = key
+	return nil
+}
+
+// SigningKey возвращает текущий ключ подписи.
+func (k *Keyring) SigningKey() (*Key, error) {
+	if k.signing == nil {
+		return nil, errors.New("no signing key configured")
+	}
+	return k.signing, nil
+}
+
+// VerificationKey ищет ключ для проверки токена с данным kid.
+func (k *Keyring) VerificationKey(id string, now time.Time) (*Key, error) {
+	if id == "" {
+		id = SecretKeyID
+	}
+	key, ok := k.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, id)
+	}
+	if !key.RetiredAt.IsZero() && now.After(key.RetiredAt.Add(k.grace)) {
+		return nil, fmt.Errorf("%w: %q", ErrKeyRetired, id)
+	}
+	return key, nil
+}
+
+// Keyfunc выбирает ключ проверки по kid токена — для jwt.Parse.
+func (k *Keyring) Keyfunc(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+	key, err := k.VerificationKey(kid, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	// Алгоритм токена должен совпадать с алгоритмом ключа — иначе, например,
+	// публичный RSA-ключ можно было бы использовать как HMAC-секрет
+	if t.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %s for key %q", t.Method.Alg(), key.ID)
+	}
+	return key.verifyKey, nil
+}
+
+// Methods возвращает алгоритмы всех ключей — для jwt.WithValidMethods.
+func (k *Keyring) Methods() []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, key := range k.keys {
+		alg := key.Method.Alg()
+		if !seen[alg] {
+			seen[alg] = true
+			out = append(out, alg)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// NewSecretKey создаёт HMAC-ключ HS256.
+func NewSecretKey(id, secret string) *Key {
+	return &Key{
+		ID:        id,
+		Method:    jwt.SigningMethodHS256,
+		signKey:   []byte(secret),
+		verifyKey: []byte(secret),
+	}
+}
+
+// NewPublicKey создаёт ключ только для проверки подписи (RSA или Ed25519).
+func NewPublicKey(id string, pub crypto.PublicKey) (*Key, error) {
+	switch p := pub.(type) {
+	case *rsa.PublicKey:
+		return &Key{ID: id, Method: jwt.SigningMethodRS256, verifyKey: p}, nil
+	case ed25519.PublicKey:
+		return &Key{ID: id, Method: jwt.SigningMethodEdDSA, verifyKey: p}, nil
+	default:
+		return nil, fmt.Errorf("key %q: unsupported public key type %T", id, pub)
+	}
+}
+
+// LoadPEMKey читает ключ из PEM-файла. Приватный RSA-ключ даёт RS256,
+// Ed25519 — EdDSA. Файл с PUBLIC KEY даёт ключ только для проверки.
+func LoadPEMKey(id, path string) (*Key, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("key %q: %s is not a PEM file", id, path)
+	}
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		return NewPublicKey(id, pub)
+	case "RSA PRIVATE KEY":
+		priv, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		return &Key{ID: id, Method: jwt.SigningMethodRS256, signKey: priv, verifyKey: &priv.PublicKey}, nil
+	case "PRIVATE KEY":
+		priv, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		switch p := priv.(type) {
+		case *rsa.PrivateKey:
+			return &Key{ID: id, Method: jwt.SigningMethodRS256, signKey: p, verifyKey: &p.PublicKey}, nil
+		case ed25519.PrivateKey:
+			return &Key{ID: id, Method: jwt.SigningMethodEdDSA, signKey: p, verifyKey: p.Public()}, nil
+		default:
+			return nil, fmt.Errorf("key %q: unsupported private key type %T", id, priv)
+		}
+	default:
+		return nil, fmt.Errorf("key %q: unsupported PEM block %q", id, block.Type)
+	}
+}
+
+// LoadKeyring собирает keyring из конфигурации: HMAC-ключ из SECRET (если задан)
+// и ключи из PEM-файлов JWT_KEYS.
+func LoadKeyring(conf configs.AuthConfig) (*Keyring, error) {
+	ring := NewKeyring(conf.KeyGracePeriod)
+	for _, kc := range conf.Keys {
+		key, err := LoadPEMKey(kc.ID, kc.Path)
+		if err != nil {
+			return nil, err
+		}
+		key.RetiredAt = kc.RetiredAt
+		if err := ring.Add(key); err != nil {
+			return nil, err
+		}
+	}
+	if conf.Secret != "" {
+		key := NewSecretKey(SecretKeyID, conf.Secret)
+		key.RetiredAt = conf.SecretRetiredAt
+		if err := ring.Add(key); err != nil {
+			return nil, err
+		}
+	}
+	if conf.SigningKeyID != "" {
+		if err := ring.SetSigningKey(conf.SigningKeyID); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := ring.SigningKey(); err != nil {
+		return nil, err
+	}
+	return ring, nil
+}
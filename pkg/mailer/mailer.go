@@ -0,0 +1,29 @@
+package mailer
+
+import (
+	"bike/configs"
+	"context"
+)
+
+// Message — простое текстовое письмо.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer отправляет письма. Реализации: SMTP для продакшена и
+// LogMailer для локальной разработки.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New выбирает реализацию по conf.Driver: "smtp" или "log" (по умолчанию).
+func New(conf configs.MailConfig) Mailer {
+	switch conf.Driver {
+	case "smtp":
+		return NewSMTPMailer(conf)
+	default:
+		return NewLogMailer(conf.LogPath)
+	}
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"bike/pkg/rbac"
+	"context"
+	"net/http"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyInfo — данные проверенного API-ключа.
+type APIKeyInfo struct {
+	ID     uint
+	Name   string
+	Scopes []rbac.Permission
+}
+
+// APIKeyVerifier проверяет API-ключ и возвращает его данные.
+type APIKeyVerifier interface {
+	VerifyAPIKey(ctx context.Context, key string) (*APIKeyInfo, error)
+}
+
+// IsAPIKeyAuthenticated пропускает запрос только с действующим ключом в заголовке X-API-Key.
+// Права ключа кладутся в контекст и проверяются RequirePermission.
+func IsAPIKeyAuthenticated(next http.Handler, deps *AuthDeps) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw := r.Header.Get(APIKeyHeader)
+		if raw == "" || deps.APIKeys == nil {
+			writeUnauthed(w)
+			return
+		}
+		info, err := deps.APIKeys.VerifyAPIKey(r.Context(), raw)
+		if err != nil {
+			writeUnauthed(w)
+			return
+		}
+		ctx := context.WithValue(r.Context(), ContextScopesKey, info.Scopes)
+		if ww, ok := w.(*WrapperWriter); ok {
+			ww.SetEmail("apikey:" + info.Name)
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// IsAuthenticatedOrAPIKey принимает либо X-API-Key, либо Bearer JWT.
+func IsAuthenticatedOrAPIKey(next http.Handler, deps *AuthDeps) http.Handler {
+	byKey := IsAPIKeyAuthenticated(next, deps)
+	byToken := IsAuthenticated(next, deps)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(APIKeyHeader) != "" {
+			byKey.ServeHTTP(w, r)
+			return
+		}
+		byToken.ServeHTTP(w, r)
+	})
+}
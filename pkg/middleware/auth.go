@@ -3,6 +3,8 @@ package middleware
 import (
 	"bike/configs"
 	"bike/pkg/jwt"
+	"bike/pkg/rbac"
+	"bike/pkg/req"
 	"context"
 	"net/http"
 	"strings"
@@ -12,14 +14,46 @@ type key string
 
 const (
 	ContextEmailKey key = "ContextEmailKey"
+	ContextRoleKey  key = "ContextRoleKey"
+	ContextTokenKey key = "ContextTokenKey"
+	// Права API-ключа; для запросов с JWT не заполняется
+	ContextScopesKey key = "ContextScopesKey"
 )
 
+// RevocationChecker сообщает, был ли токен отозван (logout и т.п.).
+type RevocationChecker interface {
+	IsRevoked(data *jwt.JWTData) bool
+}
+
+// SessionTracker отмечает активность сессии (устройства), в которой выдан токен.
+type SessionTracker interface {
+	TouchSession(data *jwt.JWTData, ip string)
+}
+
+// AccountChecker сообщает, может ли пользователь работать с API:
+// аккаунт не удалён и не заблокирован.
+type AccountChecker interface {
+	AccountActive(userID uint) bool
+}
+
+// AuthDeps — зависимости IsAuthenticated, общие для всех хэндлеров.
+type AuthDeps struct {
+	Config      *configs.Config
+	JWT         *jwt.JWT
+	Revocations RevocationChecker
+	APIKeys     APIKeyVerifier
+	Sessions    SessionTracker
+	Accounts    AccountChecker
+	// Журнал запросов, выполненных от имени пользователя
+	Impersonations ImpersonationAuditor
+}
+
 func writeUnauthed(w http.ResponseWriter) {
 	w.WriteHeader(http.StatusUnauthorized)
 	w.Write([]byte("Unauthorized"))
 }
 
-func IsAuthenticated(next http.Handler, config *configs.Config) http.Handler {
+func IsAuthenticated(next http.Handler, deps *AuthDeps) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
 		if !strings.HasPrefix(authHeader, "Bearer ") {
@@ -27,17 +61,68 @@ func IsAuthenticated(next http.Handler, config *configs.Config) http.Handler {
 			return
 		}
 		token := strings.TrimPrefix(authHeader, "Bearer ")
-		isValid, data := jwt.NewJWT(config.Auth.Secret).ParseToken(token)
-		if !isValid {
+		isValid, data := deps.JWT.ParseToken(token)
+		// Токены без sub выданы до появления claim: клиент получит новый через /auth/refresh
+		if !isValid || data.UserID == 0 {
+			writeUnauthed(w)
+			return
+		}
+		// Сюда же попадают токены отозванных сессий
+		if deps.Revocations != nil && deps.Revocations.IsRevoked(data) {
 			writeUnauthed(w)
 			return
 		}
+		// Токены отзываются при блокировке, но проверяем и сам аккаунт — на случай
+		// блокировки на другом инстансе до синхронизации отзывов
+		if deps.Accounts != nil && !deps.Accounts.AccountActive(data.UserID) {
+			writeUnauthed(w)
+			return
+		}
+		// Администратор, заблокированный после выдачи токена имперсонации,
+		// теряет и доступ от имени пользователя
+		if deps.Accounts != nil && data.Actor != nil && !deps.Accounts.AccountActive(data.Actor.UserID) {
+			writeUnauthed(w)
+			return
+		}
+		if deps.Sessions != nil && data.SessionID != "" {
+			deps.Sessions.TouchSession(data, req.ClientIP(r))
+		}
+		role := rbac.Role(data.Role)
+		if !role.Valid() {
+			role = rbac.RoleCustomer
+		}
 		ctx := context.WithValue(r.Context(), ContextEmailKey, data.Email)
-		req := r.WithContext(ctx)
+		ctx = context.WithValue(ctx, ContextRoleKey, role)
+		ctx = context.WithValue(ctx, ContextTokenKey, data)
+		principal := &Principal{
+			UserID:    data.UserID,
+			Email:     data.Email,
+			Role:      role,
+			SessionID: data.SessionID,
+			TokenID:   data.ID,
+			Actor:     data.Actor,
+		}
+		ctx = WithPrincipal(ctx, principal)
 		if ww, ok := w.(*WrapperWriter); ok {
-			ww.SetEmail(data.Email)
+			ww.SetEmail(logEmail(principal))
+		}
+
+		if principal.Impersonated() && deps.Impersonations != nil {
+			ww, ok := w.(*WrapperWriter)
+			if !ok {
+				ww = &WrapperWriter{ResponseWriter: w, StatusCode: http.StatusOK}
+			}
+			next.ServeHTTP(ww, r.WithContext(ctx))
+			deps.Impersonations.RecordAction(principal, r, ww.StatusCode)
+			return
 		}
-		
-		next.ServeHTTP(w, req)
+		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+func logEmail(p *Principal) string {
+	if p.Impersonated() {
+		return p.Actor.Email + " as " + p.Email
+	}
+	return p.Email
+}
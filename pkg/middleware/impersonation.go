@@ -0,0 +1,23 @@
+package middleware
+
+import "net/http"
+
+// ImpersonationAuditor записывает запросы, выполненные администратором
+// от имени пользователя.
+type ImpersonationAuditor interface {
+	RecordAction(p *Principal, r *http.Request, status int)
+}
+
+// ForbidImpersonation запрещает действие при входе от имени пользователя:
+// смену пароля и email, управление 2FA и сессиями, удаление аккаунта.
+// Ставится после IsAuthenticated.
+func ForbidImpersonation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p, ok := FromContext(r.Context()); ok && p.Impersonated() {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte("Not allowed while impersonating"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
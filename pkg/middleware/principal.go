@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"bike/pkg/jwt"
+	"bike/pkg/rbac"
+	"context"
+)
+
+// Principal — аутентифицированный пользователь текущего запроса.
+type Principal struct {
+	UserID uint
+	Email  string
+	Role   rbac.Role
+	// Сессия (устройство), в которой выдан токен; пусто для старых токенов
+	SessionID string
+	// jti access-токена
+	TokenID string
+	// Администратор, действующий от имени пользователя; nil — обычный вход
+	Actor *jwt.Actor
+}
+
+// Impersonated сообщает, что запрос выполняет администратор от имени пользователя.
+func (p *Principal) Impersonated() bool {
+	return p.Actor != nil
+}
+
+const contextPrincipalKey key = "ContextPrincipalKey"
+
+// WithPrincipal кладёт principal в контекст.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, contextPrincipalKey, p)
+}
+
+// FromContext возвращает principal, положенный IsAuthenticated.
+// Для анонимных запросов и запросов по API-ключу ok == false.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(contextPrincipalKey).(*Principal)
+	return p, ok && p != nil
+}
+
+// UserIDFromContext — ID текущего пользователя или 0.
+func UserIDFromContext(ctx context.Context) uint {
+	if p, ok := FromContext(ctx); ok {
+		return p.UserID
+	}
+	return 0
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// RealIP подменяет r.RemoteAddr адресом клиента из X-Forwarded-For / X-Real-IP.
+// Включать только за доверенным прокси — иначе клиент сам подставит любой IP.
+func RealIP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := ""
+		if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+			// Первый адрес в списке — исходный клиент
+			first, _, _ := strings.Cut(xff, ",")
+			ip = strings.TrimSpace(first)
+		} else if xrip := r.Header.Get("X-Real-IP"); xrip != "" {
+			ip = strings.TrimSpace(xrip)
+		}
+		if net.ParseIP(ip) != nil {
+			r.RemoteAddr = net.JoinHostPort(ip, "0")
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"bike/pkg/rbac"
+	"net/http"
+)
+
+func writeForbidden(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusForbidden)
+	w.Write([]byte("Forbidden"))
+}
+
+// RequireRole пропускает запрос, только если роль пользователя входит в roles.
+// Должен стоять внутри IsAuthenticated — роль берётся из контекста.
+// У API-ключей роли нет, такие запросы получают 403.
+func RequireRole(next http.Handler, roles ...rbac.Role) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, isKey := r.Context().Value(ContextScopesKey).([]rbac.Permission); isKey {
+			writeForbidden(w)
+			return
+		}
+		role, ok := r.Context().Value(ContextRoleKey).(rbac.Role)
+		if !ok {
+			writeUnauthed(w)
+			return
+		}
+		for _, allowed := range roles {
+			if role == allowed {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+		writeForbidden(w)
+	})
+}
+
+// RequirePermission пропускает запрос, только если у роли пользователя
+// (или у API-ключа) есть все perms. Должен стоять внутри IsAuthenticated
+// или IsAuthenticatedOrAPIKey.
+func RequirePermission(next http.Handler, perms ...rbac.Permission) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		can, ok := permissionChecker(r)
+		if !ok {
+			writeUnauthed(w)
+			return
+		}
+		for _, p := range perms {
+			if !can(p) {
+				writeForbidden(w)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func permissionChecker(r *http.Request) (func(rbac.Permission) bool, bool) {
+	if scopes, ok := r.Context().Value(ContextScopesKey).([]rbac.Permission); ok {
+		return func(p rbac.Permission) bool {
+			for _, s := range scopes {
+				if s == p {
+					return true
+				}
+			}
+			return false
+		}, true
+	}
+	role, ok := r.Context().Value(ContextRoleKey).(rbac.Role)
+	if !ok {
+		return nil, false
+	}
+	return role.Can, true
+}
@@ -0,0 +1,175 @@
+// Package oidctest — тестовый провайдер OpenID Connect на httptest.Server:
+// discovery, JWKS со сменой ключей, token endpoint с проверкой PKCE.
+package oidctest
+
+import (
+	bikejwt "bike/pkg/jwt"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type signingKey struct {
+	id   string
+	priv ed25519.PrivateKey
+}
+
+type grant struct {
+	challenge string
+	claims    jwt.MapClaims
+}
+
+// Server — провайдер, выдающий ID-токены, подписанные Ed25519.
+type Server struct {
+	*httptest.Server
+	ClientID string
+
+	mu           sync.Mutex
+	key          signingKey
+	keySeq       int
+	grants       map[string]grant
+	jwksRequests int
+}
+
+func NewServer(clientID string) *Server {
+	s := &Server{ClientID: clientID, grants: make(map[string]grant)}
+	s.RotateKey()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("GET /jwks", s.jwks)
+	mux.HandleFunc("POST /token", s.token)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// Issuer — адрес провайдера, он же iss в токенах.
+func (s *Server) Issuer() string {
+	return s.URL
+}
+
+// RotateKey заменяет ключ подписи новым; старый из JWKS убирается.
+func (s *Server) RotateKey() {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keySeq++
+	s.key = signingKey{id: fmt.Sprintf("key-%d", s.keySeq), priv: priv}
+}
+
+// JWKSRequests — сколько раз запрашивали JWKS.
+func (s *Server) JWKSRequests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.jwksRequests
+}
+
+// Claims возвращает корректные claims ID-токена для subject;
+// тесты меняют в них нужные поля.
+func (s *Server) Claims(subject, nonce string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":   s.Issuer(),
+		"aud":   s.ClientID,
+		"sub":   subject,
+		"nonce": nonce,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}
+}
+
+// Sign подписывает claims текущим ключом.
+func (s *Server) Sign(claims jwt.MapClaims) string {
+	s.mu.Lock()
+	key := s.key
+	s.mu.Unlock()
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = key.id
+	signed, err := token.SignedString(key.priv)
+	if err != nil {
+		panic(err)
+	}
+	return signed
+}
+
+// Authorize имитирует вход пользователя на странице провайдера: запоминает
+// code_challenge и claims будущего ID-токена и возвращает authorization code.
+func (s *Server) Authorize(challenge string, claims jwt.MapClaims) string {
+	code := rand.Text()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.grants[code] = grant{challenge: challenge, claims: claims}
+	return code
+}
+
+func (s *Server) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 s.Issuer(),
+		"authorization_endpoint": s.URL + "/authorize",
+		"token_endpoint":         s.URL + "/token",
+		"jwks_uri":               s.URL + "/jwks",
+	})
+}
+
+func (s *Server) jwks(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.jwksRequests++
+	key := s.key
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, bikejwt.JWKS{Keys: []bikejwt.JWK{{
+		Kty: "OKP",
+		Kid: key.id,
+		Alg: jwt.SigningMethodEdDSA.Alg(),
+		Use: "sig",
+		Crv: "Ed25519",
+		X:   base64.RawURLEncoding.EncodeToString(key.priv.Public().(ed25519.PublicKey)),
+	}}})
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" || r.PostForm.Get("client_id") != s.ClientID {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_client"})
+		return
+	}
+	code := r.PostForm.Get("code")
+	s.mu.Lock()
+	g, ok := s.grants[code]
+	delete(s.grants, code)
+	s.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != g.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error":             "invalid_grant",
+			"error_description": "unknown code or code_verifier mismatch",
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{
+		"access_token": rand.Text(),
+		"token_type":   "Bearer",
+		"id_token":     s.Sign(g.claims),
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
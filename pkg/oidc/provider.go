@@ -0,0 +1,305 @@
+// Package oidc — клиент OpenID Connect: authorization code flow с PKCE
+// и проверка ID-токена по JWKS провайдера.
+package oidc
+
+import (
+	"bike/configs"
+	bikejwt "bike/pkg/jwt"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Не чаще, чем раз в этот интервал, перечитываем JWKS из-за неизвестного kid
+const jwksRefreshInterval = time.Minute
+
+var ErrUnknownProvider = errors.New("unknown oidc provider")
+
+// Claims — данные пользователя из ID-токена.
+type Claims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Tokens — ответ token endpoint.
+type Tokens struct {
+	AccessToken string
+	IDToken     string
+}
+
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Provider — один провайдер OIDC. Адреса endpoint'ов берутся из конфига
+// или из discovery-документа; JWKS кэшируется и перечитывается при смене ключей.
+type Provider struct {
+	Name   string
+	conf   configs.OIDCProviderConfig
+	client *http.Client
+
+	mu       sync.Mutex
+	meta     *metadata
+	keys     *bikejwt.Keyring
+	keysTime time.Time
+}
+
+func NewProvider(conf configs.OIDCProviderConfig, client *http.Client) *Provider {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Provider{Name: conf.Name, conf: conf, client: client}
+}
+
+// Registry — провайдеры по имени.
+type Registry map[string]*Provider
+
+func NewRegistry(conf configs.OIDCConfig) Registry {
+	registry := make(Registry, len(conf.Providers))
+	for _, p := range conf.Providers {
+		registry[p.Name] = NewProvider(p, nil)
+	}
+	return registry
+}
+
+func (r Registry) Get(name string) (*Provider, error) {
+	p, ok := r[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownProvider, name)
+	}
+	return p, nil
+}
+
+// Names возвращает имена провайдеров по алфавиту.
+func (r Registry) Names() []string {
+	names := make([]string, 0, len(r))
+	for name := range r {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AuthCodeURL собирает ссылку на страницу входа провайдера.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	meta, err := p.metadata(ctx)
+	if err != nil {
+		return "", err
+	}
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", p.conf.ClientID)
+	q.Set("redirect_uri", p.conf.RedirectURL)
+	q.Set("scope", strings.Join(p.conf.Scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", CodeChallenge(verifier))
+	q.Set("code_challenge_method", "S256")
+
+	sep := "?"
+	if strings.Contains(meta.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return meta.AuthorizationEndpoint + sep + q.Encode(), nil
+}
+
+// Exchange обменивает authorization code на токены.
+func (p *Provider) Exchange(ctx context.Context, code, verifier string) (*Tokens, error) {
+	meta, err := p.metadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.conf.RedirectURL)
+	form.Set("client_id", p.conf.ClientID)
+	form.Set("code_verifier", verifier)
+	if p.conf.ClientSecret != "" {
+		form.Set("client_secret", p.conf.ClientSecret)
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("Accept", "application/json")
+
+	var body struct {
+		AccessToken      string `json:"access_token"`
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	status, err := p.doJSON(r, &body)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK || body.Error != "" {
+		return nil, fmt.Errorf("oidc %s: token endpoint: %d %s %s", p.Name, status, body.Error, body.ErrorDescription)
+	}
+	if body.IDToken == "" {
+		return nil, fmt.Errorf("oidc %s: no id_token in response", p.Name)
+	}
+	return &Tokens{AccessToken: body.AccessToken, IDToken: body.IDToken}, nil
+}
+
+type idTokenClaims struct {
+	Email         string `json:"email"`
+	EmailVerified any    `json:"email_verified"`
+	Name          string `json:"name"`
+	Nonce         string `json:"nonce"`
+	jwt.RegisteredClaims
+}
+
+// VerifyIDToken проверяет подпись ID-токена по JWKS провайдера, issuer,
+// audience, срок действия и nonce.
+func (p *Provider) VerifyIDToken(ctx context.Context, raw, nonce string) (*Claims, error) {
+	meta, err := p.metadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := p.keyring(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	var c idTokenClaims
+	parse := func(keys *bikejwt.Keyring) error {
+		c = idTokenClaims{}
+		_, err := jwt.ParseWithClaims(raw, &c, keys.Keyfunc,
+			jwt.WithValidMethods(keys.Methods()),
+			jwt.WithIssuer(meta.Issuer),
+			jwt.WithAudience(p.conf.ClientID),
+			jwt.WithExpirationRequired(),
+			jwt.WithLeeway(time.Minute),
+		)
+		return err
+	}
+	err = parse(keys)
+	if errors.Is(err, bikejwt.ErrUnknownKey) {
+		// Провайдер мог сменить ключи — перечитываем JWKS
+		if keys, err = p.keyring(ctx, true); err != nil {
+			return nil, err
+		}
+		err = parse(keys)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("oidc %s: invalid id_token: %w", p.Name, err)
+	}
+	if c.Nonce != nonce {
+		return nil, fmt.Errorf("oidc %s: nonce mismatch", p.Name)
+	}
+	if c.Subject == "" {
+		return nil, fmt.Errorf("oidc %s: id_token without sub", p.Name)
+	}
+	return &Claims{
+		Subject:       c.Subject,
+		Email:         strings.ToLower(strings.TrimSpace(c.Email)),
+		EmailVerified: c.EmailVerified == true || c.EmailVerified == "true",
+		Name:          c.Name,
+	}, nil
+}
+
+// metadata возвращает адреса endpoint'ов: из конфига или из discovery.
+func (p *Provider) metadata(ctx context.Context) (*metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.meta != nil {
+		return p.meta, nil
+	}
+	meta := &metadata{
+		Issuer:                p.conf.Issuer,
+		AuthorizationEndpoint: p.conf.AuthURL,
+		TokenEndpoint:         p.conf.TokenURL,
+		JWKSURI:               p.conf.JWKSURL,
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JWKSURI == "" {
+		r, err := http.NewRequestWithContext(ctx, http.MethodGet, p.conf.Issuer+"/.well-known/openid-configuration", nil)
+		if err != nil {
+			return nil, err
+		}
+		var discovered metadata
+		status, err := p.doJSON(r, &discovered)
+		if err != nil {
+			return nil, err
+		}
+		if status != http.StatusOK {
+			return nil, fmt.Errorf("oidc %s: discovery: status %d", p.Name, status)
+		}
+		if strings.TrimRight(discovered.Issuer, "/") != p.conf.Issuer {
+			return nil, fmt.Errorf("oidc %s: discovery issuer %q does not match %q", p.Name, discovered.Issuer, p.conf.Issuer)
+		}
+		meta.Issuer = discovered.Issuer
+		if meta.AuthorizationEndpoint == "" {
+			meta.AuthorizationEndpoint = discovered.AuthorizationEndpoint
+		}
+		if meta.TokenEndpoint == "" {
+			meta.TokenEndpoint = discovered.TokenEndpoint
+		}
+		if meta.JWKSURI == "" {
+			meta.JWKSURI = discovered.JWKSURI
+		}
+	}
+	p.meta = meta
+	return meta, nil
+}
+
+// keyring возвращает ключи провайдера. force — перечитать JWKS,
+// но не чаще jwksRefreshInterval.
+func (p *Provider) keyring(ctx context.Context, force bool) (*bikejwt.Keyring, error) {
+	meta, err := p.metadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.keys != nil && (!force || time.Since(p.keysTime) < jwksRefreshInterval) {
+		return p.keys, nil
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, meta.JWKSURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	var set bikejwt.JWKS
+	status, err := p.doJSON(r, &set)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("oidc %s: jwks: status %d", p.Name, status)
+	}
+	p.keys = bikejwt.NewKeyringFromJWKS(set)
+	p.keysTime = time.Now()
+	return p.keys, nil
+}
+
+func (p *Provider) doJSON(r *http.Request, out any) (int, error) {
+	resp, err := p.client.Do(r)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	if err := json.Unmarshal(body, out); err != nil && resp.StatusCode == http.StatusOK {
+		return resp.StatusCode, fmt.Errorf("oidc %s: decode %s: %w", p.Name, r.URL, err)
+	}
+	return resp.StatusCode, nil
+}
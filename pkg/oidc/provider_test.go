@@ -0,0 +1,150 @@
+package oidc
+
+import (
+	"bike/configs"
+	"bike/pkg/oidc/oidctest"
+	"context"
+	"net/url"
+	"testing"
+	"time"
+)
+
+const testClientID = "bike-app"
+
+func newTestProvider(t *testing.T) (*oidctest.Server, *Provider) {
+	t.Helper()
+	srv := oidctest.NewServer(testClientID)
+	t.Cleanup(srv.Close)
+	p := NewProvider(configs.OIDCProviderConfig{
+		Name:        "test",
+		Issuer:      srv.Issuer(),
+		ClientID:    testClientID,
+		RedirectURL: "bike://oidc/callback",
+		Scopes:      []string{"openid", "email", "profile"},
+	}, srv.Client())
+	return srv, p
+}
+
+func TestAuthCodeURLUsesDiscovery(t *testing.T) {
+	srv, p := newTestProvider(t)
+	verifier, _ := RandomString()
+
+	raw, err := p.AuthCodeURL(context.Background(), "state-1", "nonce-1", verifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("parse %q: %v", raw, err)
+	}
+	if got := u.Scheme + "://" + u.Host + u.Path; got != srv.URL+"/authorize" {
+		t.Errorf("authorization endpoint = %q, want %q", got, srv.URL+"/authorize")
+	}
+	q := u.Query()
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             testClientID,
+		"redirect_uri":          "bike://oidc/callback",
+		"scope":                 "openid email profile",
+		"state":                 "state-1",
+		"nonce":                 "nonce-1",
+		"code_challenge":        CodeChallenge(verifier),
+		"code_challenge_method": "S256",
+	}
+	for key, value := range want {
+		if q.Get(key) != value {
+			t.Errorf("%s = %q, want %q", key, q.Get(key), value)
+		}
+	}
+}
+
+func TestExchangeSendsCodeVerifier(t *testing.T) {
+	srv, p := newTestProvider(t)
+	ctx := context.Background()
+	verifier, _ := RandomString()
+	claims := srv.Claims("user-1", "nonce-1")
+	claims["email"] = "Rider@Example.com"
+	claims["email_verified"] = true
+
+	code := srv.Authorize(CodeChallenge(verifier), claims)
+	if _, err := p.Exchange(ctx, code, "wrong-verifier"); err == nil {
+		t.Fatal("Exchange with wrong code_verifier: want error")
+	}
+
+	code = srv.Authorize(CodeChallenge(verifier), claims)
+	tokens, err := p.Exchange(ctx, code, verifier)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	got, err := p.VerifyIDToken(ctx, tokens.IDToken, "nonce-1")
+	if err != nil {
+		t.Fatalf("VerifyIDToken: %v", err)
+	}
+	if got.Subject != "user-1" || got.Email != "rider@example.com" || !got.EmailVerified {
+		t.Errorf("claims = %+v", got)
+	}
+}
+
+func TestVerifyIDTokenRejects(t *testing.T) {
+	srv, p := newTestProvider(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		modify func(claims map[string]any)
+	}{
+		{"nonce", func(c map[string]any) { c["nonce"] = "other-nonce" }},
+		{"issuer", func(c map[string]any) { c["iss"] = "https://evil.example.com" }},
+		{"audience", func(c map[string]any) { c["aud"] = "other-client" }},
+		{"expired", func(c map[string]any) { c["exp"] = time.Now().Add(-time.Hour).Unix() }},
+		{"no expiry", func(c map[string]any) { delete(c, "exp") }},
+		{"no subject", func(c map[string]any) { delete(c, "sub") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := srv.Claims("user-1", "nonce-1")
+			tt.modify(claims)
+			if _, err := p.VerifyIDToken(ctx, srv.Sign(claims), "nonce-1"); err == nil {
+				t.Error("want error")
+			}
+		})
+	}
+}
+
+func TestVerifyIDTokenRefetchesRotatedKeys(t *testing.T) {
+	srv, p := newTestProvider(t)
+	ctx := context.Background()
+	verify := func() error {
+		_, err := p.VerifyIDToken(ctx, srv.Sign(srv.Claims("user-1", "nonce-1")), "nonce-1")
+		return err
+	}
+
+	if err := verify(); err != nil {
+		t.Fatalf("VerifyIDToken: %v", err)
+	}
+	if err := verify(); err != nil {
+		t.Fatalf("VerifyIDToken: %v", err)
+	}
+	if n := srv.JWKSRequests(); n != 1 {
+		t.Fatalf("JWKS fetched %d times, want 1 (cached)", n)
+	}
+
+	srv.RotateKey()
+	// Сразу после загрузки JWKS не перечитывается, даже при неизвестном kid
+	if err := verify(); err == nil {
+		t.Fatal("token with new key before refresh interval: want error")
+	}
+	if n := srv.JWKSRequests(); n != 1 {
+		t.Fatalf("JWKS fetched %d times within refresh interval, want 1", n)
+	}
+
+	p.mu.Lock()
+	p.keysTime = time.Now().Add(-jwksRefreshInterval)
+	p.mu.Unlock()
+	if err := verify(); err != nil {
+		t.Fatalf("VerifyIDToken after rotation: %v", err)
+	}
+	if n := srv.JWKSRequests(); n != 2 {
+		t.Fatalf("JWKS fetched %d times, want 2", n)
+	}
+}
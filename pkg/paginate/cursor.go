@@ -0,0 +1,98 @@
+package paginate
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor — позиция в списке: значение ключа сортировки и ID записи на границе
+// страницы. Клиенту отдаётся в подписанном виде и не может быть подделан.
+type Cursor struct {
+	// Режим сортировки, для которого выдан курсор
+	Sort string `json:"s,omitempty"`
+	// Значение ключа сортировки в строковом виде; пусто при сортировке по ID
+	Key string `json:"k,omitempty"`
+	ID  uint   `json:"i"`
+	// Курсор предыдущей страницы: выбираются записи перед позицией
+	Backward bool `json:"b,omitempty"`
+}
+
+// Codec подписывает курсоры HMAC-SHA256.
+type Codec struct {
+	key []byte
+}
+
+// NewCodec создаёт кодек. Ключ подписи выводится из secret, чтобы один и тот
+// же секрет не использовался напрямую в разных целях.
+func NewCodec(secret string) *Codec {
+	sum := sha256.Sum256([]byte("paginate:" + secret))
+	return &Codec{key: sum[:]}
+}
+
+// Encode возвращает курсор в виде base64url(json).base64url(подпись).
+func (c *Codec) Encode(cur Cursor) string {
+	payload, _ := json.Marshal(cur)
+	body := base64.RawURLEncoding.EncodeToString(payload)
+	return body + "." + base64.RawURLEncoding.EncodeToString(c.sign(body))
+}
+
+// Decode проверяет подпись и разбирает курсор. Пустая строка — первая
+// страница: возвращается nil без ошибки.
+func (c *Codec) Decode(raw string) (*Cursor, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	body, sig, ok := strings.Cut(raw, ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	got, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, c.sign(body)) {
+		return nil, ErrInvalidCursor
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cur Cursor
+	if err := json.Unmarshal(payload, &cur); err != nil || cur.ID == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &cur, nil
+}
+
+// Neighbours строит курсоры соседних страниц по первой и последней записи
+// текущей (nil — страница пуста). more — есть ли ещё записи в направлении
+// движения (см. Trim).
+func (c *Codec) Neighbours(cur *Cursor, more bool, first, last *Cursor) (next, prev string) {
+	if first == nil || last == nil {
+		return "", ""
+	}
+	hasNext, hasPrev := more, cur != nil
+	if cur != nil && cur.Backward {
+		hasNext, hasPrev = true, more
+	}
+	if hasNext {
+		n := *last
+		n.Backward = false
+		next = c.Encode(n)
+	}
+	if hasPrev {
+		p := *first
+		p.Backward = true
+		prev = c.Encode(p)
+	}
+	return next, prev
+}
+
+func (c *Codec) sign(body string) []byte {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write([]byte(body))
+	return mac.Sum(nil)[:16]
+}
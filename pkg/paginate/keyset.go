@@ -0,0 +1,74 @@
+package paginate
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Seek возвращает условие WHERE для записей после курсора при сортировке по
+// (column, id). Для column == "id" — одно значение, иначе ключ и ID.
+// backward — записи перед курсором.
+func Seek(column string, desc, backward bool) string {
+	op := ">"
+	if desc != backward {
+		op = "<"
+	}
+	if column == "id" {
+		return "id " + op + " ?"
+	}
+	return fmt.Sprintf("(%s, id) %s (?, ?)", column, op)
+}
+
+// Order возвращает ORDER BY для сортировки по (column, id). При движении назад
+// порядок обратный, Trim потом разворачивает страницу.
+func Order(column string, desc, backward bool) string {
+	dir := "ASC"
+	if desc != backward {
+		dir = "DESC"
+	}
+	if column == "id" {
+		return "id " + dir
+	}
+	return fmt.Sprintf("%s %s, id %s", column, dir, dir)
+}
+
+// Trim обрабатывает выборку из limit+1 записей: отрезает лишнюю и, если шли
+// назад, возвращает прямой порядок. more — была ли лишняя запись.
+func Trim[T any](items []T, limit int, backward bool) ([]T, bool) {
+	more := len(items) > limit
+	if more {
+		items = items[:limit]
+	}
+	if backward {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+	return items, more
+}
+
+// SetLinkHeader добавляет заголовок Link (RFC 8288) со ссылками на соседние
+// страницы — текущий запрос с заменённым cursor.
+func SetLinkHeader(w http.ResponseWriter, r *http.Request, next, prev string) {
+	var links []string
+	if next != "" {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, pageURL(r, next)))
+	}
+	if prev != "" {
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, pageURL(r, prev)))
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
+
+func pageURL(r *http.Request, cursor string) string {
+	q := r.URL.Query()
+	q.Set("cursor", cursor)
+	q.Del("page")
+	q.Del("offset")
+	u := url.URL{Path: r.URL.Path, RawQuery: q.Encode()}
+	return u.String()
+}
@@ -0,0 +1,36 @@
+// Package phone приводит телефонные номера к формату E.164.
+package phone
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrInvalid = errors.New("invalid phone number")
+
+// Normalize приводит номер к виду +79001234567: убирает пробелы, скобки и
+// дефисы, российский номер с 8 в начале переводит на +7.
+func Normalize(raw string) (string, error) {
+	var digits strings.Builder
+	plus := false
+	for i, r := range strings.TrimSpace(raw) {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' && i == 0:
+			plus = true
+		case r == ' ' || r == '-' || r == '(' || r == ')':
+		default:
+			return "", ErrInvalid
+		}
+	}
+	d := digits.String()
+	if !plus && len(d) == 11 && d[0] == '8' {
+		d = "7" + d[1:]
+		plus = true
+	}
+	if !plus || len(d) < 10 || len(d) > 15 || d[0] == '0' {
+		return "", ErrInvalid
+	}
+	return "+" + d, nil
+}
@@ -0,0 +1,77 @@
+package rbac
+
+// Role — роль пользователя, хранится в users.User и передаётся в JWT.
+type Role string
+
+const (
+	RoleCustomer Role = "customer"
+	RoleManager  Role = "manager"
+	RoleAdmin    Role = "admin"
+)
+
+// Permission — отдельное право на группу действий.
+type Permission string
+
+const (
+	PermProductsWrite    Permission = "products:write"
+	PermUsersRead        Permission = "users:read"
+	PermUsersWrite       Permission = "users:write"
+	PermUsersImpersonate Permission = "users:impersonate"
+	PermAddressesRead    Permission = "addresses:read"
+	PermAPIKeysManage    Permission = "apikeys:manage"
+)
+
+// APIKeyScopes — права, которые можно выдать API-ключу.
+var APIKeyScopes = []Permission{
+	PermProductsWrite,
+	PermUsersRead,
+	PermAddressesRead,
+}
+
+var rolePermissions = map[Role][]Permission{
+	RoleCustomer: {},
+	RoleManager: {
+		PermProductsWrite,
+		PermUsersRead,
+		PermAddressesRead,
+	},
+	RoleAdmin: {
+		PermProductsWrite,
+		PermUsersRead,
+		PermUsersWrite,
+		PermUsersImpersonate,
+		PermAddressesRead,
+		PermAPIKeysManage,
+	},
+}
+
+// Valid сообщает, известна ли роль.
+func (r Role) Valid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// Can проверяет, есть ли у роли право p.
+func (r Role) Can(p Permission) bool {
+	for _, perm := range rolePermissions[r] {
+		if perm == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Permissions возвращает список прав роли.
+func (r Role) Permissions() []Permission {
+	return rolePermissions[r]
+}
+
+// IsAPIKeyScope сообщает, можно ли выдать право p API-ключу.
+func (p Permission) IsAPIKeyScope() bool {
+	for _, scope := range APIKeyScopes {
+		if scope == p {
+			return true
+		}
+	}
+	return false
+}
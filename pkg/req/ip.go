@@ -0,0 +1,16 @@
+package req
+
+import (
+	"net"
+	"net/http"
+)
+
+// ClientIP возвращает IP клиента из r.RemoteAddr. За прокси адрес
+// подставляет middleware.RealIP.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -26,6 +26,16 @@ func init() {
 // Slugify: "Пицца Маргарита" -> "pizza-margarita"
 
 func Slugify(s string) string {
+	out := Clean(s)
+	if out == "" {
+		out = "item"
+	}
+	return out
+}
+
+// Clean — как Slugify, но без заглушки "item": если в s нет ни букв,
+// ни цифр ("!!!", "—"), возвращает пустую строку.
+func Clean(s string) string {
 	s = strings.TrimSpace(strings.ToLower(s))
 	var b strings.Builder
 	for _, r := range s {
@@ -45,11 +55,7 @@ func Slugify(s string) string {
 	out := strings.ReplaceAll(b.String(), " ", "-")
 	out = nonAlNum.ReplaceAllString(out, "")
 	out = multiDash.ReplaceAllString(out, "-")
-	out = strings.Trim(out, "-")
-	if out == "" {
-		out = "item"
-	}
-	return out
+	return strings.Trim(out, "-")
 }
 
 // WithSuffix("pizza-margarita", 2) -> "pizza-margarita-2"
@@ -0,0 +1,63 @@
+package sms
+
+import (
+	"bike/configs"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HTTPSender отправляет SMS через HTTP-шлюз: POST {BaseURL}/messages
+// с JSON {"from", "to", "text"} и ключом в заголовке Authorization.
+// BaseURL можно направить на локальную заглушку.
+type HTTPSender struct {
+	baseURL string
+	apiKey  string
+	from    string
+	client  *http.Client
+}
+
+func NewHTTPSender(conf configs.SMSConfig) *HTTPSender {
+	return &HTTPSender{
+		baseURL: strings.TrimRight(conf.BaseURL, "/"),
+		apiKey:  conf.APIKey,
+		from:    conf.From,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type httpMessage struct {
+	From string `json:"from,omitempty"`
+	To   string `json:"to"`
+	Text string `json:"text"`
+}
+
+func (s *HTTPSender) Send(ctx context.Context, to, text string) error {
+	body, err := json.Marshal(httpMessage{From: s.from, To: to, Text: text})
+	if err != nil {
+		return err
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+"/messages", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Content-Type", "application/json")
+	if s.apiKey != "" {
+		r.Header.Set("Authorization", "Bearer "+s.apiKey)
+	}
+	resp, err := s.client.Do(r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("sms gateway: status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// LogSender ничего не отправляет: пишет сообщения в лог или, если задан path,
+// дописывает их в файл. Для локальной разработки.
+type LogSender struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewLogSender(path string) *LogSender {
+	return &LogSender{path: path}
+}
+
+func (s *LogSender) Send(ctx context.Context, to, text string) error {
+	if s.path == "" {
+		log.Printf("sms to=%s: %s", to, text)
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = fmt.Fprintf(f, "=== %s\nTo: %s\n\n%s\n\n", time.Now().Format(time.RFC3339), to, text)
+	return err
+}
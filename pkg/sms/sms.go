@@ -0,0 +1,22 @@
+package sms
+
+import (
+	"bike/configs"
+	"context"
+)
+
+// SMSSender отправляет SMS. Реализации: HTTP-шлюз для продакшена и
+// LogSender для локальной разработки.
+type SMSSender interface {
+	Send(ctx context.Context, to, text string) error
+}
+
+// New выбирает реализацию по conf.Driver: "http" или "log" (по умолчанию).
+func New(conf configs.SMSConfig) SMSSender {
+	switch conf.Driver {
+	case "http":
+		return NewHTTPSender(conf)
+	default:
+		return NewLogSender(conf.LogPath)
+	}
+}